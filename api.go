@@ -54,8 +54,7 @@ func NewAPI(osVarName string, required ...bool) (_ *API, err error) {
 	s, ok := os.LookupEnv(osVarName)
 	defer func() {
 		if len(required) > 0 && required[0] && err != nil {
-			log_.Errorf("%s", err)
-			os.Exit(1)
+			fatal(err)
 		}
 	}()
 	if !ok && InUnitTests() {
@@ -103,7 +102,7 @@ func APIFromURL(url string, args ...any) (*API, error) {
 			continue
 		}
 		if n := strings.Index(name, "Header."); n != -1 {
-			d.Headers[name[n+7:]] = value[0]
+			d.Header(name[n+7:], "%s", value[0])
 		}
 	}
 	if n := strings.ToLower(u.Username()); n != "" {
@@ -111,7 +110,7 @@ func APIFromURL(url string, args ...any) (*API, error) {
 		case n == "bearer":
 			d.Header("Authorization", "Bearer %s", u.Password())
 		case strings.HasPrefix(n, "x-"):
-			d.Headers[u.Username()] = u.Password()
+			d.Header(u.Username(), "%s", u.Password())
 		}
 	}
 	if d.Name == "" {
@@ -127,6 +126,38 @@ func APIFromURL(url string, args ...any) (*API, error) {
 	return &d, nil
 }
 
+// UseHeaders sets default headers sent with every Endpoint request, like
+// organization identifiers or Accept. Headers set on API or Endpoint take
+// precedence over defaults. User-Agent defaults to BuildInfo().UserAgent()
+// unless given here, an empty value removes the header from defaults.
+func UseHeaders(h map[string]string) {
+	headersMu.Lock()
+	defer headersMu.Unlock()
+	for n, v := range h {
+		headers[http.CanonicalHeaderKey(n)] = v
+	}
+}
+
+// DefaultHeaders returns headers applied to every Endpoint request.
+func DefaultHeaders() map[string]string {
+	headersMu.RLock()
+	defer headersMu.RUnlock()
+	h := map[string]string{"User-Agent": BuildInfo().UserAgent()}
+	for n, v := range headers {
+		if v == "" {
+			delete(h, n)
+			continue
+		}
+		h[n] = v
+	}
+	return h
+}
+
+var (
+	headersMu sync.RWMutex
+	headers   = make(map[string]string)
+)
+
 func MustAPI(osVarName string) *API {
 	d, err := NewAPI(osVarName)
 	if err != nil {
@@ -148,7 +179,7 @@ func (a *API) Header(name, value string, args ...any) *API {
 	if a.Headers == nil {
 		a.Headers = make(map[string]string)
 	}
-	a.Headers[name] = value
+	a.Headers[http.CanonicalHeaderKey(name)] = value
 	return a
 }
 
@@ -233,7 +264,8 @@ func (a *API) auth(r *http.Request) (string, error) {
 	if a.Authorization == nil {
 		return "", nil
 	}
-	if b := a.get(r.Context(), key, 1); len(b) != 0 {
+	ctx := WithCache(r.Context()) // tokens are cached regardless of request
+	if b := a.get(ctx, key, 1); len(b) != 0 {
 		return string(b), nil
 	}
 	if tkn, exp, err = a.Authorization(r); err != nil {
@@ -242,7 +274,7 @@ func (a *API) auth(r *http.Request) (string, error) {
 	if tkn = strings.TrimSpace(tkn); tkn == "" {
 		return "", Errorf("token not found")
 	}
-	a.set(key, []byte(tkn), exp.Sub(time.Now()))
+	a.set(ctx, key, []byte(tkn), exp.Sub(time.Now()))
 	return tkn, nil
 }
 
@@ -250,7 +282,7 @@ func (a *API) get(ctx context.Context, hash string, t time.Duration) []byte {
 	if t <= 0 {
 		t = a.Cache
 	}
-	if t <= 0 {
+	if t <= 0 || cacheMode(ctx) != cacheDefault {
 		return nil
 	}
 	var s string
@@ -261,12 +293,19 @@ func (a *API) get(ctx context.Context, hash string, t time.Duration) []byte {
 	return []byte(s)
 }
 
-func (a *API) set(hash string, b []byte, t time.Duration) int {
+func (a *API) ttl(t time.Duration) time.Duration {
 	if t <= 0 {
-		t = a.Cache
+		return a.Cache
 	}
+	return t
+}
+
+func (a *API) set(ctx context.Context, hash string, b []byte, t time.Duration) int {
 	if t <= 0 {
+		t = a.Cache
+	}
+	if t <= 0 || cacheMode(ctx) == cacheBypass {
 		return -1
 	}
-	return Set(Context(), hash, string(b), t)
+	return Set(ctx, hash, string(b), t)
 }
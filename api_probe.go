@@ -0,0 +1,154 @@
+package ion
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Health is the last known state of an API checked with Probe.
+type Health struct {
+	Name    string        `json:"name"`
+	Up      bool          `json:"up"`
+	Latency time.Duration `json:"latency"`
+	// Checked is the time of the last probe.
+	Checked time.Time `json:"checked"`
+	// Since is the time Up changed last time.
+	Since time.Time `json:"since"`
+	Error string    `json:"error,omitempty"`
+}
+
+// Probe periodically calls lightweight path of the API (e.g. /status) and
+// records its availability and latency, so a vendor outage is noticed before
+// users hit it. Responses other than 5xx mean the API is up. The state is
+// available from Health, Healths and HealthHandler, and exported as
+// api_up{name} gauge and api_probe_in_seconds{name} summary.
+//
+// Example:
+//
+//	stripe := ion.MustAPI("STRIPE_URL").Probe("/v1/balance", 30*time.Second)
+//	http.Handle("/health/vendors", ion.HealthHandler())
+func (a *API) Probe(path string, interval time.Duration) *API {
+	healthsMu.Lock()
+	if _, ok := healths[a.Name]; !ok {
+		healths[a.Name] = &Health{Name: a.Name, Up: true}
+	}
+	healthsMu.Unlock()
+	Tasks.Run("probe:"+a.Name, JobFunc(func(c context.Context) error {
+		return a.probe(c, path, interval)
+	}), interval)
+	return a
+}
+
+// Health returns the last known state of the API, it is always up when the
+// API is not probed.
+func (a *API) Health() Health {
+	healthsMu.RLock()
+	defer healthsMu.RUnlock()
+	if h, ok := healths[a.Name]; ok {
+		return *h
+	}
+	return Health{Name: a.Name, Up: true}
+}
+
+// Healths returns states of all probed APIs sorted by name.
+func Healths() []Health {
+	healthsMu.RLock()
+	defer healthsMu.RUnlock()
+	var hh []Health
+	for _, h := range healths {
+		hh = append(hh, *h)
+	}
+	slices.SortFunc(hh, func(a, b Health) int { return strings.Compare(a.Name, b.Name) })
+	return hh
+}
+
+// HealthHandler serves states of probed APIs as JSON, or a single one when
+// `name` query parameter is given. It responds with 503 status when any of
+// listed APIs is down.
+func HealthHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hh := Healths()
+		if n := r.URL.Query().Get("name"); n != "" {
+			hh = slices.DeleteFunc(hh, func(h Health) bool { return !strings.EqualFold(h.Name, n) })
+			if len(hh) == 0 {
+				http.Error(w, fmt.Sprintf("%s not probed", n), http.StatusNotFound)
+				return
+			}
+		}
+		code := http.StatusOK
+		for _, h := range hh {
+			if !h.Up {
+				code = http.StatusServiceUnavailable
+			}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(code)
+		_ = json.NewEncoder(w).Encode(hh)
+	})
+}
+
+func (a *API) probe(c context.Context, path string, timeout time.Duration) error {
+	c, done := context.WithTimeout(c, timeout)
+	defer done()
+	req, err := http.NewRequestWithContext(c, http.MethodGet, a.URL.Format("scheme://host:port")+path, nil)
+	if err != nil {
+		return err
+	}
+	for _, h := range []map[string]string{DefaultHeaders(), a.Headers} {
+		for n, v := range h {
+			req.Header.Set(n, v)
+		}
+	}
+	now := time.Now()
+	res, err := a.run(req)
+	if err == nil {
+		_, _ = io.Copy(io.Discard, res.Body)
+		_ = res.Body.Close()
+		if res.StatusCode >= 500 {
+			err = Errorf("%s", res.Status)
+		}
+	}
+	if c.Err() != nil && err == nil {
+		return nil
+	}
+	a.healthy(err, time.Since(now))
+	return nil
+}
+
+// healthy records result of a probe.
+func (a *API) healthy(err error, latency time.Duration) {
+	healthsMu.Lock()
+	defer healthsMu.Unlock()
+	h := healths[a.Name]
+	up := err == nil
+	if h.Up != up {
+		h.Since = time.Now()
+		if up {
+			log_.Infof("API: %s is up again", a.Name)
+		} else {
+			log_.Errorf("API: %s is down due %s", a.Name, err)
+		}
+	}
+	h.Up, h.Latency, h.Checked, h.Error = up, latency, time.Now(), ""
+	if err != nil {
+		h.Error = err.Error()
+	}
+	var n float64
+	if up {
+		n = 1
+	}
+	Metrics.Gauge("api_up{name=%q}", n, a.Name)
+	Metrics.Percentile("api_probe_in_seconds{name=%q}", latency.Seconds(), a.Name)
+}
+
+var (
+	healthsMu sync.RWMutex
+	healths   = make(map[string]*Health)
+)
@@ -0,0 +1,53 @@
+package ion_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sokool/ion"
+)
+
+func TestAPI_Probe(t *testing.T) {
+	var down atomic.Bool
+	ion.Endpoints.Handler(func(r *http.Request, w *httptest.ResponseRecorder) {
+		if r.URL.Path != "/status" {
+			t.Errorf("unexpected probe path %s", r.URL.Path)
+		}
+		if down.Load() {
+			w.WriteHeader(http.StatusBadGateway)
+		}
+	}, "probe.test")
+
+	a, err := ion.APIFromURL("https://probe.test?Name=Probed")
+	if err != nil {
+		t.Fatal(err)
+	}
+	a.Probe("/status", 5*time.Millisecond)
+	wait := func(up bool) {
+		t.Helper()
+		for i := 0; i < 100; i++ {
+			if h := a.Health(); h.Up == up && !h.Checked.IsZero() {
+				return
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+		t.Fatalf("expected up=%v, got %+v", up, a.Health())
+	}
+	wait(true)
+	down.Store(true)
+	wait(false)
+
+	w := httptest.NewRecorder()
+	ion.HealthHandler().ServeHTTP(w, httptest.NewRequest("GET", "/?name=probed", nil))
+	var hh []ion.Health
+	if err = json.NewDecoder(w.Body).Decode(&hh); err != nil || w.Code != http.StatusServiceUnavailable || len(hh) != 1 {
+		t.Fatalf("unexpected status page %d %v %v", w.Code, hh, err)
+	}
+	down.Store(false)
+	wait(true)
+	ion.Tasks.Terminate("probe:Probed")
+}
@@ -0,0 +1,94 @@
+package ion
+
+import (
+	"fmt"
+	"os"
+	"sync"
+)
+
+// Build describes release of the running application.
+type Build struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+	Commit  string `json:"commit"`
+	Date    string `json:"date"`
+}
+
+// SetBuildInfo registers release metadata of the running application, usually
+// injected with -ldflags at compile time. It is logged once, exposed as
+// build_info gauge in Metrics, sent as User-Agent by every Endpoint and
+// attached to LLM requests, so vendor-side issues can be correlated with
+// specific releases.
+//
+// Example:
+//
+//	var version, commit, date string // set by -ldflags "-X main.version=..."
+//
+//	func main() {
+//		ion.SetBuildInfo(version, commit, date)
+//	}
+func SetBuildInfo(version, commit, date string) {
+	buildMu.Lock()
+	build = Build{Name: build.Name, Version: version, Commit: commit, Date: date}
+	b := build
+	// label values are kept as they are, not snake cased as by Metrics.Gauge
+	if buildSeries != "" {
+		Metrics.set.UnregisterMetric(buildSeries)
+	}
+	buildSeries = fmt.Sprintf("build_info{name=%q,version=%q,commit=%q,date=%q}", b.Name, b.Version, b.Commit, b.Date)
+	Metrics.set.GetOrCreateGauge(buildSeries, nil).Set(1)
+	buildMu.Unlock()
+
+	log_.Infof("build %s", b)
+}
+
+// BuildInfo returns metadata registered with SetBuildInfo.
+func BuildInfo() Build {
+	buildMu.RLock()
+	defer buildMu.RUnlock()
+	return build
+}
+
+// UserAgent returns value for User-Agent header in the form of
+// "name/version (commit)".
+func (b Build) UserAgent() string {
+	s := b.Name + "/" + b.Version
+	if b.Version == "" {
+		s = b.Name
+	}
+	if b.Commit != "" {
+		s += fmt.Sprintf(" (%s)", b.Commit)
+	}
+	return s
+}
+
+// Meta returns non-empty build attributes.
+func (b Build) Meta() Meta {
+	m := Meta{}
+	for k, v := range map[string]string{"app": b.Name, "version": b.Version, "commit": b.Commit, "date": b.Date} {
+		if v != "" {
+			m[k] = v
+		}
+	}
+	return m
+}
+
+func (b Build) String() string {
+	if b.Date == "" {
+		return b.UserAgent()
+	}
+	return fmt.Sprintf("%s built %s", b.UserAgent(), b.Date)
+}
+
+var (
+	buildMu     sync.RWMutex
+	build       = Build{Name: appName()}
+	buildSeries string // build_info metric registered by SetBuildInfo
+)
+
+func appName() string {
+	if n := os.Getenv("APP_NAME"); n != "" {
+		return n
+	}
+	return "ion"
+}
@@ -0,0 +1,24 @@
+package ion_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/sokool/ion"
+)
+
+func TestSetBuildInfo(t *testing.T) {
+	defer func(b ion.Build) { ion.SetBuildInfo(b.Version, b.Commit, b.Date) }(ion.BuildInfo())
+	ion.SetBuildInfo("1.2.3", "abc", "2026-10-17")
+	ion.SetBuildInfo("1.2.4", "def", "2026-10-18")
+	s := ion.Metrics.String()
+	if !strings.Contains(s, `version="1.2.4",commit="def",date="2026-10-18"} 1`) {
+		t.Fatalf("expected build_info with label values intact in %s", s)
+	}
+	if strings.Contains(s, `version="1.2.3"`) {
+		t.Fatal("expected previous build_info series removed")
+	}
+	if ua := ion.BuildInfo().UserAgent(); !strings.HasSuffix(ua, "/1.2.4 (def)") {
+		t.Fatalf("unexpected user agent %s", ua)
+	}
+}
@@ -0,0 +1,74 @@
+package ion
+
+import (
+	"errors"
+	"reflect"
+)
+
+// Defaults fills zero-valued fields of the struct pointed by v with values of
+// their `default` tags, converted with Cast rules (see RegisterCast), so
+// configs loaded from partial env or JSON documents get sane values. Nested
+// structs are filled as well, nil pointers are allocated only for fields
+// carrying the tag. Fields already set are left intact.
+//
+// Example:
+//
+//	type Config struct {
+//		Port    int           `json:"port" default:"8080"`
+//		Timeout time.Duration `json:"timeout" default:"30s"`
+//		Hosts   []string      `json:"hosts" default:"a.local,b.local"`
+//	}
+//	var c Config
+//	err := ion.Defaults(&c)
+func Defaults(v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return ErrDefault.New("non-nil pointer expected, %T given", v)
+	}
+	var errs []error
+	defaults(rv.Elem(), "", &errs)
+	return errors.Join(errs...)
+}
+
+func defaults(v reflect.Value, path string, errs *[]error) {
+	v = diffElem(v)
+	if v.Kind() != reflect.Struct || mapConverts(v.Type()) {
+		return
+	}
+	for _, f := range reflect.VisibleFields(v.Type()) {
+		if !f.IsExported() || f.Anonymous {
+			continue
+		}
+		fv, err := v.FieldByIndexErr(f.Index)
+		if err != nil {
+			continue // promoted through nil embedded pointer
+		}
+		p := mapPath(path, f.Name)
+		d, ok := f.Tag.Lookup("default")
+		if !ok || !fv.IsZero() {
+			defaults(fv, p, errs)
+			continue
+		}
+		t, n := fv.Type(), 0
+		for ; t.Kind() == reflect.Pointer; n++ {
+			t = t.Elem()
+		}
+		c, err := castValue(d, t)
+		if err != nil {
+			*errs = append(*errs, ErrDefault.New("%s %w", p, err))
+			continue
+		}
+		for ; n > 0; n-- {
+			x := reflect.New(t)
+			x.Elem().Set(c)
+			c, t = x, x.Type()
+		}
+		if !c.Type().AssignableTo(fv.Type()) {
+			*errs = append(*errs, ErrDefault.New("%s can not be set from %s", p, c.Type()))
+			continue
+		}
+		fv.Set(c)
+	}
+}
+
+var ErrDefault = Errorf("default")
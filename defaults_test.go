@@ -0,0 +1,60 @@
+package ion_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/sokool/ion"
+)
+
+func TestDefaults(t *testing.T) {
+	type db struct {
+		URL  string `default:"postgres://localhost"`
+		Pool *int   `default:"4"`
+	}
+	type config struct {
+		Port    int           `default:"8080"`
+		Timeout time.Duration `default:"30s"`
+		Hosts   []string      `default:"a.local, b.local"`
+		Debug   bool          `default:"true"`
+		Name    string        `default:"app"`
+		DB      db
+		Cache   *db
+	}
+	c := config{Name: "set"}
+	if err := ion.Defaults(&c); err != nil {
+		t.Fatal(err)
+	}
+	if c.Port != 8080 || c.Timeout != 30*time.Second || fmt.Sprint(c.Hosts) != "[a.local b.local]" || !c.Debug ||
+		c.Name != "set" || c.DB.URL != "postgres://localhost" || *c.DB.Pool != 4 || c.Cache != nil {
+		t.Fatalf("unexpected config %+v", c)
+	}
+	var bad struct {
+		Port int `default:"http"`
+	}
+	if err := ion.Defaults(&bad); !ion.ErrDefault.In(err) {
+		t.Fatalf("expected conversion error, got %v", err)
+	}
+	if err := ion.Defaults(c); !ion.ErrDefault.In(err) {
+		t.Fatalf("expected pointer error, got %v", err)
+	}
+}
+
+type defaultsBase struct {
+	Region string `default:"eu"`
+}
+
+func TestDefaults_Edges(t *testing.T) {
+	var c struct {
+		*defaultsBase
+		Any   any   `default:"x"`
+		Count **int `default:"3"`
+	}
+	if err := ion.Defaults(&c); err != nil {
+		t.Fatal(err)
+	}
+	if c.Any != "x" || **c.Count != 3 || c.defaultsBase != nil {
+		t.Fatalf("unexpected defaults %v %d %v", c.Any, **c.Count, c.defaultsBase)
+	}
+}
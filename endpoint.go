@@ -11,6 +11,7 @@ import (
 	"net/http"
 	"net/url"
 	"reflect"
+	"slices"
 	"sort"
 	"strings"
 	"time"
@@ -28,16 +29,29 @@ type Endpoint[REQ, RES any] struct {
 	domain  *API
 	key     string
 	cache   time.Duration
+	tags    []string
 	context context.Context
 	limiter Limiter
 	lock    bool
 	log     *Logger
 }
 
+// NewEndpoint creates Endpoint from url. When url is invalid the error is
+// passed to the OnFatal handler and an Endpoint failing on every call is
+// returned, use ParseEndpoint to handle the error yourself.
 func NewEndpoint[REQ, RES any](url string, args ...any) Endpoint[REQ, RES] {
+	e, err := ParseEndpoint[REQ, RES](url, args...)
+	if err != nil {
+		fatal(ErrEndpoint.Wrap(err))
+	}
+	return e
+}
+
+// ParseEndpoint creates Endpoint from url or returns an error when url is invalid.
+func ParseEndpoint[REQ, RES any](url string, args ...any) (Endpoint[REQ, RES], error) {
 	a, err := APIFromURL(url, args...)
 	if err != nil {
-		Exit("Rest: %s", err)
+		return Endpoint[REQ, RES]{domain: &API{}, log: NewLogger("")}, err
 	}
 	u := a.URL
 	return Endpoint[REQ, RES]{
@@ -47,7 +61,7 @@ func NewEndpoint[REQ, RES any](url string, args ...any) Endpoint[REQ, RES] {
 		params: u.URL.Query(),
 		log:    NewLogger(""),
 		name:   u.Hostname(),
-	}
+	}, nil
 }
 
 func JSONEndpoint(url string, args ...any) Endpoint[JSON, JSON] {
@@ -90,7 +104,7 @@ func (e Endpoint[REQ, RES]) Header(n, v string, args ...any) Endpoint[REQ, RES]
 	if e.headers == nil {
 		e.headers = make(map[string]string)
 	}
-	e.headers[n] = fmt.Sprintf(fmt.Sprintf("%s", v), args...)
+	e.headers[http.CanonicalHeaderKey(n)] = fmt.Sprintf(fmt.Sprintf("%s", v), args...)
 	return e
 }
 
@@ -125,7 +139,7 @@ func (e Endpoint[REQ, RES]) Execute() (RES, error) {
 func (e Endpoint[REQ, RES]) String() string {
 	var h string
 	for n, v := range e.headers {
-		h += fmt.Sprintf("%s: %s\n", n, v)
+		h += fmt.Sprintf("%s: %s\n", n, Redaction().Header(n, v))
 	}
 
 	return fmt.Sprintf(`%s %s HTTP/1.1
@@ -172,6 +186,13 @@ func (e Endpoint[REQ, RES]) Cache(d time.Duration, name ...string) Endpoint[REQ,
 	return e
 }
 
+// CacheTags attaches tags to cached responses of the endpoint, so they can be
+// dropped with Invalidate when underlying data changes.
+func (e Endpoint[REQ, RES]) CacheTags(tags ...string) Endpoint[REQ, RES] {
+	e.tags = append(slices.Clone(e.tags), tags...)
+	return e
+}
+
 // Lock enables distributed locking for the endpoint.
 //
 // When enabled, Lock prevents concurrent invocations of the same endpoint
@@ -188,8 +209,8 @@ func (e Endpoint[REQ, RES]) Lock(enable bool) Endpoint[REQ, RES] {
 }
 
 func (e Endpoint[REQ, RES]) wait(ctx context.Context) error {
-	if e.limiter != nil {
-		return e.limiter.Check(ctx, UUID(e.String()))
+	if e.limiter != nil && e.domain != nil {
+		return e.limiter.Check(ctx, e.domain.Name+":"+e.method+" "+e.path)
 	}
 	if e.domain != nil && e.domain.limiter != nil {
 		return e.domain.limiter.Check(ctx, e.domain.Name)
@@ -199,6 +220,9 @@ func (e Endpoint[REQ, RES]) wait(ctx context.Context) error {
 
 func (e Endpoint[REQ, RES]) reader(contentType string, r REQ) (*strings.Reader, error) {
 	v := any(r)
+	if strings.HasSuffix(contentType, "+json") && !isEmpty(r) {
+		contentType = "application/json" // vendor media types, ie. application/vnd.kafka.binary.v2+json
+	}
 	switch contentType {
 	case "application/x-www-form-urlencoded":
 		switch v := v.(type) {
@@ -229,13 +253,28 @@ func (e Endpoint[REQ, RES]) execute(in REQ) (RES, error) {
 		return out, Errorf("domain url not found")
 	}
 	tag := e.tag()
-	if e.headers == nil {
-		e.headers = make(map[string]string)
+	hs := make(map[string]string)
+	for n, v := range e.headers {
+		hs[http.CanonicalHeaderKey(n)] = v
 	}
+	e.headers = hs
 	if _, found := e.headers["Content-Type"]; !found && !isEmpty(in) {
 		e.headers["Content-Type"] = "application/json"
 	}
+	for _, h := range []map[string]string{e.domain.Headers, DefaultHeaders()} {
+		for n, v := range h {
+			n = http.CanonicalHeaderKey(n)
+			if _, found := e.headers[n]; !found {
+				e.headers[n] = v
+			}
+		}
+	}
 
+	if v := diffElem(reflect.ValueOf(in)); v.Kind() == reflect.Struct {
+		if err := Validate(in); err != nil {
+			return out, err
+		}
+	}
 	rdr, err := e.reader(e.headers["Content-Type"], in)
 	if err != nil {
 		return out, err
@@ -269,7 +308,11 @@ func (e Endpoint[REQ, RES]) execute(in REQ) (RES, error) {
 	}
 	msg := fmt.Sprintf(tag+" %s:%s", e.method, e.path)
 	code := ""
-	if b = e.domain.get(cx, key, e.cache); b == nil {
+	if b = e.domain.get(cx, key, e.cache); b != nil {
+		if p, ok := cx.Value(endpointCachedKey{}).(*bool); ok {
+			*p = true
+		}
+	} else {
 		if err = e.wait(cx); err != nil {
 			return out, err
 		}
@@ -294,8 +337,9 @@ func (e Endpoint[REQ, RES]) execute(in REQ) (RES, error) {
 			return out, err
 		}
 		b, _ = io.ReadAll(res.Body)
-		if n := e.domain.set(key, b, e.cache); n > 0 {
+		if n := e.domain.set(cx, key, b, e.cache); n > 0 {
 			code = "200 Cached"
+			CacheTag(cx, key, e.domain.ttl(e.cache), e.tags...)
 		}
 		ins := float64(rdr.Size()) / 1024
 		ous := float64(len(b)) / 1024
@@ -308,20 +352,27 @@ func (e Endpoint[REQ, RES]) execute(in REQ) (RES, error) {
 
 	if len(b) != 0 {
 		if err = json.Unmarshal(b, &out); err != nil {
-			e.log.Errorf(msg)
+			e.log.Errorf(msg+" %s: %.512s", err, JSON(b).Redact())
 			return out, err
 		}
 	}
 	return out, nil
 }
 
+// endpointCachedKey carries flag set when Post reads response from cache.
+type endpointCachedKey struct{}
+
+// hash returns cache key of the request, User-Agent carrying release is left
+// out, so new release does not invalidate cached responses.
 func (e Endpoint[REQ, RES]) hash(r *http.Request) (string, error) {
 	hash := md5.New()
 	key := fmt.Sprintf("%s\n%s\n%s\n", r.Method, r.URL, e.key)
 	if e.key == "" {
 		hk := make([]string, 0, len(r.Header))
 		for k := range r.Header {
-			hk = append(hk, k)
+			if k != "User-Agent" {
+				hk = append(hk, k)
+			}
 		}
 		sort.Strings(hk)
 		for _, k := range hk {
@@ -344,8 +395,8 @@ func (e Endpoint[REQ, RES]) hash(r *http.Request) (string, error) {
 	if _, err := hash.Write([]byte(key)); err != nil {
 		return "", err
 	}
-	// Return the MD5 hash as a hex string
-	return hex.EncodeToString(hash.Sum(nil)), nil
+	// Return the MD5 hash as a hex string, prefixed for cache metrics
+	return "rest:" + hex.EncodeToString(hash.Sum(nil)), nil
 }
 
 func (e Endpoint[REQ, RES]) tag() string {
@@ -371,8 +422,16 @@ func isEmpty[T any](value T) bool {
 
 type values = url.Values
 
+var ErrEndpoint = Errorf("rest")
+
 // ConvertStructToURLValues converts a struct into url.Values
 func newValues(input any) (url.Values, error) {
+	switch input := input.(type) {
+	case JSON:
+		return input.Values(), nil
+	case Meta:
+		return input.JSON().Values(), nil
+	}
 	values := url.Values{}
 
 	// Reflect the input to inspect its type
@@ -1,9 +1,14 @@
 package ion_test
 
 import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/sokool/ion"
 )
@@ -42,3 +47,138 @@ func TestDomain(t *testing.T) {
 		t.Fatal("error does not contain 'domain url not found'")
 	}
 }
+
+func TestNewEndpoint_Fatal(t *testing.T) {
+	var err error
+	ion.OnFatal(func(e error) { err = e })
+	defer ion.OnFatal(nil)
+	if _, er := ion.JSONEndpoint("not an url").Get(); er == nil {
+		t.Fatal("expected endpoint error")
+	}
+	if !ion.ErrEndpoint.In(err) {
+		t.Fatalf("expected fatal endpoint error, got %v", err)
+	}
+	if _, err = ion.ParseEndpoint[ion.JSON, ion.JSON]("not an url"); err == nil {
+		t.Fatal("expected parse error")
+	}
+}
+
+func TestEndpoint_Headers(t *testing.T) {
+	ion.Endpoints.Handler(func(r *http.Request, w *httptest.ResponseRecorder) {
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"org":   r.Header.Get("X-Org"),
+			"agent": r.Header.Get("User-Agent"),
+			"team":  r.Header.Get("X-Team"),
+		})
+	}, "headers.test")
+	ion.UseHeaders(map[string]string{"x-org": "acme", "X-Team": "core"})
+	defer ion.UseHeaders(map[string]string{"X-Org": "", "X-Team": ""})
+
+	j, err := ion.JSONEndpoint("https://headers.test/echo").Header("X-Team", "billing").Get()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := j.Text("org"); s != "acme" {
+		t.Fatalf("expected acme org, got %s", s)
+	}
+	if s := j.Text("team"); s != "billing" {
+		t.Fatalf("expected billing team, got %s", s)
+	}
+	if s := j.Text("agent"); s != ion.BuildInfo().UserAgent() {
+		t.Fatalf("expected %s agent, got %s", ion.BuildInfo().UserAgent(), s)
+	}
+}
+
+func TestEndpoint_CacheTags(t *testing.T) {
+	var calls int
+	ion.Endpoints.Handler(func(r *http.Request, w *httptest.ResponseRecorder) {
+		calls++
+		_ = json.NewEncoder(w).Encode(map[string]int{"calls": calls})
+	}, "tags.test")
+	e := ion.JSONEndpoint("https://tags.test/orders").Cache(time.Hour).CacheTags("orders")
+	for range 2 {
+		if j, err := e.Get(); err != nil || j.Int("calls") != 1 {
+			t.Fatalf("expected cached response, got %s %v", j, err)
+		}
+	}
+	if err := ion.Invalidate(context.Background(), "orders"); err != nil {
+		t.Fatal(err)
+	}
+	if j, err := e.Get(); err != nil || j.Int("calls") != 2 {
+		t.Fatalf("expected fresh response, got %s %v", j, err)
+	}
+}
+
+func TestEndpoint_WithoutCache(t *testing.T) {
+	var calls int
+	ion.Endpoints.Handler(func(r *http.Request, w *httptest.ResponseRecorder) {
+		calls++
+		_ = json.NewEncoder(w).Encode(map[string]int{"calls": calls})
+	}, "bypass.test")
+	ctx := context.Background()
+	e := ion.JSONEndpoint("https://bypass.test/orders/%s", ion.UUID()).Cache(time.Hour)
+	for i, c := range []struct {
+		ctx   context.Context
+		calls int
+	}{
+		{ion.WithoutCache(ctx), 1}, // nothing cached
+		{ctx, 2},
+		{ctx, 2},
+		{ion.WithoutCache(ctx), 3},
+		{ion.RefreshCache(ctx), 4},
+		{ion.WithCache(ion.WithoutCache(ctx)), 4},
+	} {
+		if j, err := e.Context(c.ctx).Get(); err != nil || j.Int("calls") != c.calls {
+			t.Fatalf("%d: expected %d calls, got %s %v", i, c.calls, j, err)
+		}
+	}
+}
+
+func TestEndpoint_CanonicalHeaders(t *testing.T) {
+	var calls int
+	var team []string
+	ion.Endpoints.Handler(func(r *http.Request, w *httptest.ResponseRecorder) {
+		calls++
+		team = r.Header.Values("X-Team")
+		_ = json.NewEncoder(w).Encode(map[string]int{"calls": calls})
+	}, "headers.test")
+	defer func(b ion.Build) { ion.SetBuildInfo(b.Version, b.Commit, b.Date) }(ion.BuildInfo())
+	e := ion.JSONEndpoint("https://headers.test/%s", ion.UUID()).Cache(time.Hour).Header("x-team", "a").Header("X-Team", "b")
+	ion.SetBuildInfo("1.0.0", "abc", "")
+	if _, err := e.Get(); err != nil {
+		t.Fatal(err)
+	}
+	if len(team) != 1 || team[0] != "b" {
+		t.Fatalf("expected canonical header set once, got %v", team)
+	}
+	ion.SetBuildInfo("1.0.1", "def", "") // new release, same User-Agent header name
+	if j, err := e.Get(); err != nil || j.Int("calls") != 1 {
+		t.Fatalf("expected response cached across releases, got %s %v", j, err)
+	}
+	// other default headers, like tenant, are part of the cache key
+	ion.UseHeaders(map[string]string{"X-Tenant": "a"})
+	defer ion.UseHeaders(map[string]string{"X-Tenant": ""})
+	if j, err := e.Get(); err != nil || j.Int("calls") != 2 {
+		t.Fatalf("expected response of tenant a, got %s %v", j, err)
+	}
+	ion.UseHeaders(map[string]string{"X-Tenant": "b"})
+	if j, err := e.Get(); err != nil || j.Int("calls") != 3 {
+		t.Fatalf("expected response of tenant b not served from cache of a, got %s %v", j, err)
+	}
+}
+
+func TestEndpoint_LimitByPath(t *testing.T) {
+	ion.Endpoints.Handler(func(r *http.Request, w *httptest.ResponseRecorder) {
+		_, _ = w.Write([]byte(`{}`))
+	}, "limit.test")
+	id := ion.UUID()
+	if _, err := ion.JSONEndpoint("https://limit.test/%s/a", id).Limit(1).Get(); err != nil {
+		t.Fatal(err)
+	}
+	// other endpoint of the domain has its own budget
+	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+	defer cancel()
+	if _, err := ion.JSONEndpoint("https://limit.test/%s/b", id).Limit(1).Context(ctx).Get(); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,161 @@
+package ion
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"sync"
+)
+
+// Enum holds a value of string based type T restricted to the set of values
+// declared with NewEnum. It validates itself when decoded from JSON, scanned
+// from SQL or converted with Cast, so invalid states never reach domain code.
+//
+// Example:
+//
+//	type Status string
+//
+//	var Statuses = NewEnum[Status]("active", "suspended", "closed")
+//
+//	type Account struct {
+//		Status Enum[Status] `json:"status"`
+//	}
+type Enum[T ~string] struct{ value T }
+
+// NewEnum declares allowed values for T and returns an empty Enum which can be
+// used to parse or list them. Calling it again for the same T extends the set.
+func NewEnum[T ~string](values ...T) Enum[T] {
+	enumsMu.Lock()
+	defer enumsMu.Unlock()
+	t := reflect.TypeFor[T]()
+	for _, v := range values {
+		if !slices.Contains(enums[t], string(v)) {
+			enums[t] = append(enums[t], string(v))
+		}
+	}
+	return Enum[T]{}
+}
+
+// ParseEnum returns Enum of s when s is one of the declared values of T.
+func ParseEnum[T ~string](s string) (Enum[T], error) {
+	var e Enum[T]
+	if !slices.Contains(e.list(), s) {
+		return e, ErrEnum.New("%q is not one of %v", s, e.list())
+	}
+	e.value = T(s)
+	return e, nil
+}
+
+// MustEnum returns Enum of v or panics when v is not declared.
+func MustEnum[T ~string](v T) Enum[T] {
+	e, err := ParseEnum[T](string(v))
+	if err != nil {
+		panic(err)
+	}
+	return e
+}
+
+// Get returns the underlying value.
+func (e Enum[T]) Get() T {
+	return e.value
+}
+
+// Is reports whether the enum holds any of the given values.
+func (e Enum[T]) Is(values ...T) bool {
+	return slices.Contains(values, e.value)
+}
+
+// IsEmpty reports whether the enum holds no value.
+func (e Enum[T]) IsEmpty() bool {
+	return e.value == ""
+}
+
+// Values returns all declared values of T in declaration order.
+func (e Enum[T]) Values() []T {
+	var o []T
+	for _, s := range e.list() {
+		o = append(o, T(s))
+	}
+	return o
+}
+
+func (e Enum[T]) String() string {
+	return string(e.value)
+}
+
+// MarshalJSON ...
+func (e Enum[T]) MarshalJSON() ([]byte, error) {
+	if e.IsEmpty() {
+		return []byte("null"), nil
+	}
+	return json.Marshal(string(e.value))
+}
+
+// UnmarshalJSON ...
+func (e *Enum[T]) UnmarshalJSON(b []byte) error {
+	if string(b) == "null" {
+		*e = Enum[T]{}
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return ErrEnum.Wrap(err)
+	}
+	return e.set(s)
+}
+
+// Value implements driver.Valuer.
+func (e Enum[T]) Value() (driver.Value, error) {
+	if e.IsEmpty() {
+		return nil, nil
+	}
+	return string(e.value), nil
+}
+
+// Scan implements sql.Scanner.
+func (e *Enum[T]) Scan(src any) error {
+	switch v := src.(type) {
+	case nil:
+		*e = Enum[T]{}
+		return nil
+	case string:
+		return e.set(v)
+	case []byte:
+		return e.set(string(v))
+	default:
+		return ErrEnum.New("unsupported scan type %T", v)
+	}
+}
+
+func (e *Enum[T]) set(s string) error {
+	n, err := ParseEnum[T](s)
+	if err != nil {
+		return err
+	}
+	*e = n
+	return nil
+}
+
+func (e Enum[T]) list() []string {
+	enumsMu.RLock()
+	defer enumsMu.RUnlock()
+	return enums[reflect.TypeFor[T]()]
+}
+
+// parse lets Cast convert strings into any Enum[T] without knowing T.
+func (e Enum[T]) parse(s string) (any, error) {
+	return ParseEnum[T](s)
+}
+
+type enum interface {
+	fmt.Stringer
+	parse(string) (any, error)
+}
+
+var (
+	ErrEnum = Errorf("enum")
+	enumsMu sync.RWMutex
+	enums   = make(map[reflect.Type][]string)
+)
@@ -0,0 +1,45 @@
+package ion_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	. "github.com/sokool/ion"
+)
+
+type status string
+
+var statuses = NewEnum[status]("active", "suspended")
+
+func TestEnum(t *testing.T) {
+	var a struct {
+		Status Enum[status] `json:"status"`
+	}
+	if err := json.Unmarshal([]byte(`{"status":"active"}`), &a); err != nil || !a.Status.Is("active") {
+		t.Fatalf("expected active, got %s %v", a.Status, err)
+	}
+	if err := json.Unmarshal([]byte(`{"status":"deleted"}`), &a); !ErrEnum.In(err) {
+		t.Fatalf("expected enum error, got %v", err)
+	}
+	if b, err := json.Marshal(a); err != nil || string(b) != `{"status":"active"}` {
+		t.Fatalf("expected active json, got %s %v", b, err)
+	}
+	if err := a.Status.Scan([]byte("suspended")); err != nil || a.Status.Get() != "suspended" {
+		t.Fatalf("expected suspended, got %s %v", a.Status, err)
+	}
+	if v, err := a.Status.Value(); err != nil || v != "suspended" {
+		t.Fatalf("expected suspended value, got %v %v", v, err)
+	}
+	if e, err := Cast[string, Enum[status]]("active"); err != nil || e.Get() != "active" {
+		t.Fatalf("expected active cast, got %s %v", e, err)
+	}
+	if _, err := Cast[string, Enum[status]]("foo"); err == nil {
+		t.Fatal("expected cast error")
+	}
+	if s, err := Cast[Enum[status], string](MustEnum[status]("active")); err != nil || s != "active" {
+		t.Fatalf("expected active string, got %s %v", s, err)
+	}
+	if n := len(statuses.Values()); n != 2 {
+		t.Fatalf("expected 2 values, got %d", n)
+	}
+}
@@ -0,0 +1,93 @@
+package ion
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"time"
+)
+
+// Idempotent returns middleware honoring client Idempotency-Key headers.
+//
+// The first response to a request carrying the header is stored in the Store
+// for ttl, keyed by the idempotency key, together with hash of the request
+// route (method and path) and body. Retries of the same request get the
+// stored response replayed, marked with Idempotent-Replayed header, without
+// calling next. Requests reusing the key with other route or body are
+// rejected with 422 Unprocessable Entity. Concurrent retries wait for the
+// first one to finish (see UseLocker). Server errors (5xx) are not stored, so
+// a retry can succeed later.
+//
+// Example:
+//
+//	http.Handle("/payments/callback", ion.Idempotent(24*time.Hour)(handler))
+func Idempotent(ttl time.Duration) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			k := r.Header.Get("Idempotency-Key")
+			if k == "" || ttl <= 0 {
+				next.ServeHTTP(w, r)
+				return
+			}
+			b, err := io.ReadAll(r.Body)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(b))
+			key, h := Text(k).Hash("http:idempotency"), Text(r.Method+" "+r.URL.Path+"\n"+string(b)).Hash()
+
+			mu := NewLocker(r.Context(), key)
+			mu.Lock()
+			defer mu.Unlock()
+
+			var s snapshot
+			// kept regardless of cache bypass of the request
+			cx := WithCache(r.Context())
+			if Get(cx, key, &s) > 0 {
+				if s.Request != h {
+					http.Error(w, "Idempotency-Key used for other request", http.StatusUnprocessableEntity)
+					return
+				}
+				for n, v := range s.Header {
+					w.Header()[n] = v
+				}
+				w.Header().Set("Idempotent-Replayed", "true")
+				w.WriteHeader(s.Status)
+				_, _ = w.Write(s.Body)
+				return
+			}
+			rec := &recorder{ResponseWriter: w, status: http.StatusOK}
+			next.ServeHTTP(rec, r)
+			if rec.status >= 500 {
+				return
+			}
+			s = snapshot{Request: h, Status: rec.status, Header: w.Header().Clone(), Body: rec.body.Bytes()}
+			Set(cx, key, s, ttl)
+		})
+	}
+}
+
+type snapshot struct {
+	Request string      `json:"request"` // hash of route and body
+	Status  int         `json:"status"`
+	Header  http.Header `json:"header"`
+	Body    []byte      `json:"body"`
+}
+
+// recorder passes response to the underlying writer while keeping its copy.
+type recorder struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (r *recorder) WriteHeader(code int) {
+	r.status = code
+	r.ResponseWriter.WriteHeader(code)
+}
+
+func (r *recorder) Write(b []byte) (int, error) {
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
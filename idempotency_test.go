@@ -0,0 +1,44 @@
+package ion_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sokool/ion"
+)
+
+func TestIdempotent(t *testing.T) {
+	var calls int
+	h := ion.Idempotent(time.Minute)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprintf(w, "payment %d", calls)
+	}))
+	do := func(key, body string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodPost, "/payments", strings.NewReader(body))
+		r.Header.Set("Idempotency-Key", key)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w
+	}
+	if w := do("k1", `{"amount":10}`); w.Code != http.StatusCreated || w.Body.String() != "payment 1" {
+		t.Fatalf("unexpected first response %d %s", w.Code, w.Body)
+	}
+	w := do("k1", `{"amount":10}`)
+	if w.Code != http.StatusCreated || w.Body.String() != "payment 1" || w.Header().Get("Idempotent-Replayed") != "true" {
+		t.Fatalf("expected replayed response, got %d %s", w.Code, w.Body)
+	}
+	if w := do("k1", `{"amount":20}`); w.Code != http.StatusUnprocessableEntity || calls != 1 {
+		t.Fatalf("expected key reused for different body rejected, got %d %s", w.Code, w.Body)
+	}
+	if w := do("k2", `{"amount":20}`); w.Body.String() != "payment 2" {
+		t.Fatalf("expected new payment for new key, got %s", w.Body)
+	}
+	if w := do("", `{"amount":10}`); w.Body.String() != "payment 3" {
+		t.Fatalf("expected pass through without key, got %s", w.Body)
+	}
+}
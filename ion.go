@@ -8,6 +8,7 @@ import (
 	"os"
 	"os/signal"
 	"strings"
+	"sync/atomic"
 
 	"github.com/google/uuid"
 )
@@ -22,6 +23,7 @@ var (
 	log_    = NewLogger(os.Getenv("APP_NAME"))
 	cancel  func()
 	website string
+	onFatal atomic.Pointer[func(error)]
 )
 
 func init() {
@@ -41,9 +43,24 @@ func Context() context.Context {
 	return ctx
 }
 
+// OnFatal registers a handler called when library code runs into an error it
+// cannot report to the caller, like an invalid URL given to NewEndpoint or a
+// missing variable required by NewAPI. Without a handler such errors terminate
+// the process through Exit, or panic when running in unit tests. Applications
+// embedding ion can use it to shut down on their own terms.
+func OnFatal(fn func(error)) {
+	if fn == nil {
+		onFatal.Store(nil)
+		return
+	}
+	onFatal.Store(&fn)
+}
+
 // Exit terminates the program with an exit code depending on the presence of errors in args.
 func Exit(msg string, args ...any) {
 	cancel()
+	// topic readers finish messages in flight within their drain period
+	pubsubDrains.wait()
 	log_.Trace(1).Printf(msg, args...)
 	code := 0
 	for i := range args {
@@ -122,6 +139,17 @@ func UUID(s ...string) string {
 	return uuid.New().String()
 }
 
+func fatal(err error) {
+	switch fn := onFatal.Load(); {
+	case fn != nil:
+		(*fn)(err)
+	case InUnitTests():
+		panic(err)
+	default:
+		Exit("%s", err)
+	}
+}
+
 func Printf(s any) {
 	b, err := json.MarshalIndent(s, "", "  ")
 	if err != nil {
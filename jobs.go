@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"math/rand/v2"
 	"sync"
 	"time"
 )
@@ -136,3 +137,62 @@ func RunAsync[T any](ctx context.Context, tasks ...func() (T, error)) ([]T, erro
 	}
 	return tt, nil
 }
+
+// RetryJob is Job run again on failure, see Retry.
+type RetryJob struct {
+	job      Job
+	attempts int
+	backoff  time.Duration
+	giveUp   func(context.Context, error)
+}
+
+// Retry returns Job running j up to attempts times until it succeeds, so a
+// transient vendor outage does not mean waiting a full interval of Jobs.Run
+// and one-shot jobs do not die on first error. Delay between attempts
+// starts from backoff, a second by default, and doubles after each failure
+// up to 5m, with a random jitter.
+//
+// Example:
+//
+//	ion.Tasks.Run("sync", ion.Retry(ion.JobFunc(sync), 5).GiveUp(alert), time.Hour)
+func Retry(j Job, attempts int, backoff ...time.Duration) *RetryJob {
+	r := &RetryJob{job: j, attempts: max(attempts, 1), backoff: time.Second}
+	if len(backoff) > 0 && backoff[0] > 0 {
+		r.backoff = backoff[0]
+	}
+	return r
+}
+
+// GiveUp sets fn called with the last error when all attempts failed.
+func (r *RetryJob) GiveUp(fn func(context.Context, error)) *RetryJob {
+	r.giveUp = fn
+	return r
+}
+
+func (r *RetryJob) Do(ctx context.Context) error {
+	for i := 1; ; i++ {
+		err := r.job.Do(ctx)
+		if err == nil || ctx.Err() != nil {
+			return err
+		}
+		if i >= r.attempts {
+			if r.giveUp != nil {
+				r.giveUp(ctx, err)
+			}
+			return fmt.Errorf("gave up after %d attempts: %w", i, err)
+		}
+		// doubled until the cap, so many attempts do not overflow
+		d := r.backoff
+		for j := 1; j < i && d < 5*time.Minute; j++ {
+			d *= 2
+		}
+		d = min(d, 5*time.Minute)
+		d += rand.N(d/4 + 1)
+		log_.Infof("Jobs: attempt %d/%d failed due %s, retry in %s", i, r.attempts, err, d.Round(time.Millisecond))
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(d):
+		}
+	}
+}
@@ -0,0 +1,33 @@
+package ion_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sokool/ion"
+)
+
+func TestRetry(t *testing.T) {
+	ctx := context.Background()
+	var calls int
+	j := ion.Retry(ion.JobFunc(func(context.Context) error {
+		if calls++; calls < 3 {
+			return errors.New("unavailable")
+		}
+		return nil
+	}), 3, time.Millisecond)
+	if err := j.Do(ctx); err != nil || calls != 3 {
+		t.Fatalf("expected success on third attempt, got %d calls %v", calls, err)
+	}
+	var gaveUp error
+	calls = 0
+	j = ion.Retry(ion.JobFunc(func(context.Context) error {
+		calls++
+		return errors.New("unavailable")
+	}), 2, time.Millisecond).GiveUp(func(_ context.Context, err error) { gaveUp = err })
+	if err := j.Do(ctx); err == nil || calls != 2 || gaveUp == nil {
+		t.Fatalf("expected give up after 2 attempts, got %d calls %v %v", calls, err, gaveUp)
+	}
+}
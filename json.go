@@ -1,15 +1,21 @@
 package ion
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"net/url"
 	"reflect"
 	"regexp"
+	"slices"
+	"sort"
 	"strconv"
 	"strings"
+	"sync/atomic"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/tidwall/gjson"
 	"github.com/tidwall/sjson"
 )
@@ -19,26 +25,48 @@ import (
 // dot-notation navigation, type checking, and flattening.
 type JSON []byte
 
+// NewJSON validates data and returns it as JSON.
+//
+// With tolerant set, "almost JSON" written by humans or LLMs is accepted:
+// `//` and `/* */` comments, trailing commas and surrounding Markdown code
+// fences are removed before validation. Strict parsing is the default.
+//
+// Example:
+//
+//	j, err := NewJSON(config, true)
+func NewJSON[T ~string | ~[]byte](data T, tolerant ...bool) (JSON, error) {
+	j := JSON(data)
+	if len(tolerant) > 0 && tolerant[0] {
+		j = jsonTolerant(j)
+	}
+	if !json.Valid(j) {
+		return nil, ErrJSON.New("invalid format")
+	}
+	return j, nil
+}
+
 // Select navigates to the specified path using dot notation and returns the found JSON fragment.
 // If the path does not exist, it returns nil (which behaves as an Empty JSON).
+// Malformed paths (like unbalanced brackets) also return nil, see JSONStrictPaths
+// and Lookup to get notified about them. Select never modifies given paths.
 // Example: j.Select("users.0.name")
 func (j JSON) Select(paths ...string) JSON {
 	if len(j) == 0 {
 		return nil
 	}
-
+	paths = slices.Clone(paths)
 	for i := range paths {
-		paths[i] = strings.ReplaceAll(paths[i], "[?(@.", ".#(")
-		paths[i] = strings.ReplaceAll(paths[i], ")]", ")")
-		paths[i] = strings.ReplaceAll(paths[i], "'", "\"")
-		if strings.Contains(paths[i], "[*]") {
-			if strings.HasPrefix(paths[i], "[*]") {
-				paths[i] = "#" + paths[i][3:]
-			} else {
-				paths[i] = strings.ReplaceAll(paths[i], "[*]", ".#")
+		p, err := jsonPath(paths[i])
+		if err != nil {
+			if jsonStrict.Load() {
+				if InUnitTests() {
+					panic(err)
+				}
+				log_.Trace(2).Errorf("%s", err)
 			}
+			return nil
 		}
-		paths[i] = regexp.MustCompile(`\[(\d+)\]`).ReplaceAllString(paths[i], ".$1")
+		paths[i] = p
 	}
 	switch len(paths) {
 	case 0:
@@ -74,12 +102,177 @@ func (j JSON) Select(paths ...string) JSON {
 		}
 		output, err := json.Marshal(merged)
 		if err != nil {
-			fmt.Printf("JSON error %s", err)
+			log_.Trace(2).Errorf("%s", ErrJSON.Wrap(err))
+			return nil
 		}
 		return output
 	}
 }
 
+// Pick projects several paths into a new object in a single evaluation,
+// preserving their structure, missing paths are omitted. Paths with
+// wildcards or queries are stored under their last key like Select does.
+//
+// Example:
+//
+//	JSON(`{"user":{"name":"John","age":30},"meta":{"count":2}}`).Pick("user.name", "meta.count")
+//	// {"user":{"name":"John"},"meta":{"count":2}}
+func (j JSON) Pick(paths ...string) JSON {
+	ps := make([]string, 0, len(paths))
+	for i := range paths {
+		p, err := jsonPath(paths[i])
+		if err != nil {
+			continue
+		}
+		ps = append(ps, p)
+	}
+	o := JSON(`{}`)
+	for i, r := range gjson.GetManyBytes(j, ps...) {
+		if !r.Exists() {
+			continue
+		}
+		k := ps[i]
+		if strings.ContainsAny(k, "#*?") {
+			k = k[strings.LastIndex(k, ".")+1:]
+		}
+		if b, err := sjson.SetRawBytes(o, k, []byte(r.Raw)); err == nil {
+			o = b
+		}
+	}
+	return o
+}
+
+// Project keeps only fields present in json tags of the proto struct,
+// recursively through nested structs, pointers, slices and maps, so large
+// payloads can be trimmed before caching or forwarding. Keys are matched
+// case-insensitively like encoding/json does, fields of types decoding
+// themselves (e.g. time.Time) and interfaces are kept whole.
+//
+// Example:
+//
+//	type order struct {
+//		ID    string `json:"id"`
+//		Lines []struct {
+//			SKU string `json:"sku"`
+//		} `json:"lines"`
+//	}
+//	small := vendorResponse.Project(order{})
+func (j JSON) Project(proto any) JSON {
+	t := reflect.TypeOf(proto)
+	if t == nil || len(j) == 0 {
+		return j
+	}
+	var b bytes.Buffer
+	jsonProject(&b, gjson.ParseBytes(j), t)
+	return b.Bytes()
+}
+
+func jsonProject(b *bytes.Buffer, r gjson.Result, t reflect.Type) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Implements(jsonUnmarshaler) || reflect.PointerTo(t).Implements(jsonUnmarshaler) {
+		b.WriteString(r.Raw)
+		return
+	}
+	switch {
+	case r.IsArray() && (t.Kind() == reflect.Slice || t.Kind() == reflect.Array):
+		b.WriteByte('[')
+		for i, v := range r.Array() {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			jsonProject(b, v, t.Elem())
+		}
+		b.WriteByte(']')
+	case r.IsObject() && t.Kind() == reflect.Map:
+		b.WriteByte('{')
+		n := 0
+		r.ForEach(func(k, v gjson.Result) bool {
+			if n++; n > 1 {
+				b.WriteByte(',')
+			}
+			b.WriteString(k.Raw + ":")
+			jsonProject(b, v, t.Elem())
+			return true
+		})
+		b.WriteByte('}')
+	case r.IsObject() && t.Kind() == reflect.Struct:
+		ff := jsonFields(t)
+		b.WriteByte('{')
+		n := 0
+		r.ForEach(func(k, v gjson.Result) bool {
+			f, ok := ff[strings.ToLower(k.Str)]
+			if !ok {
+				return true
+			}
+			if n++; n > 1 {
+				b.WriteByte(',')
+			}
+			b.WriteString(k.Raw + ":")
+			jsonProject(b, v, f)
+			return true
+		})
+		b.WriteByte('}')
+	default:
+		b.WriteString(r.Raw)
+	}
+}
+
+// jsonFields returns types of struct fields by their lowercase json names,
+// including fields of embedded structs.
+func jsonFields(t reflect.Type) map[string]reflect.Type {
+	ff := make(map[string]reflect.Type)
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		n, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if n == "-" || (!f.IsExported() && !f.Anonymous) {
+			continue
+		}
+		if ft := f.Type; f.Anonymous && n == "" {
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				for k, v := range jsonFields(ft) {
+					if _, ok := ff[k]; !ok {
+						ff[k] = v
+					}
+				}
+				continue
+			}
+		}
+		if n == "" {
+			n = f.Name
+		}
+		ff[strings.ToLower(n)] = f.Type
+	}
+	return ff
+}
+
+// Lookup returns the JSON fragment found at paths, like Select, but reports
+// malformed paths with ErrJSONPath instead of silently returning nil. It
+// never modifies the receiver, errors are returned only.
+func (j JSON) Lookup(paths ...string) (JSON, error) {
+	var errs []error
+	for _, p := range paths {
+		if _, err := jsonPath(p); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return j.Select(paths...), nil
+}
+
+// JSONStrictPaths enables reporting of malformed paths given to Select: they
+// are logged as errors, or panic when running in unit tests, so broken
+// selectors are caught early instead of silently returning empty values.
+func JSONStrictPaths(enable bool) {
+	jsonStrict.Store(enable)
+}
+
 // Read extracts multiple JSON values in a single call.
 //
 // Each argument pair must follow the pattern (path, target), where `path`
@@ -199,7 +392,7 @@ func (j JSON) Flat() Meta {
 		} else {
 			// It's a leaf value (String, Number, Bool, Null)
 			// node.Value() returns the native Go type
-			result[prefix] = node.Value()
+			result[prefix] = jsonValue(node)
 		}
 	}
 
@@ -244,12 +437,20 @@ func (j JSON) Time(path string) time.Time {
 func (j JSON) To(target any, fallback ...any) error {
 	// 1. Primary path: If data exists, unmarshal immediately.
 	if b := j.Select(); !b.IsEmpty() {
-		return json.Unmarshal(b, target)
+		if v := reflect.ValueOf(target); v.Kind() == reflect.Pointer && !v.IsNil() && jsonCasts(v.Type().Elem(), map[reflect.Type]bool{}) {
+			return jsonCastDecode(b, v.Elem())
+		}
+		return jsonDecode(b, target, jsonNumbers.Load())
 	}
 	// 2. If no data and no fallback, do nothing.
 	if len(fallback) == 0 {
 		return nil
 	}
+	// Empty objects and arrays are legitimate values, only missing ones and
+	// nulls are replaced by fallback.
+	if t := j.Type(""); t == JSONObject || t == JSONArray {
+		return jsonDecode(j, target, jsonNumbers.Load())
+	}
 	// 3. Handle Fallback (using Reflection)
 	// We validate types first to ensure safety.
 	tv := reflect.ValueOf(target)
@@ -275,6 +476,69 @@ func (j JSON) IsEmpty() bool {
 	}
 }
 
+// Has reports whether path exists, even when its value is empty, zero or
+// null. Empty path refers to the JSON itself.
+//
+// Example:
+//
+//	j := JSON(`{"count":0,"tags":[]}`)
+//	j.Has("count")                 // true
+//	j.Has("tags")                  // true
+//	j.Select("count").IsEmpty()    // false
+//	j.Select("tags").IsEmpty()     // true
+//	j.Has("missing")               // false
+func (j JSON) Has(path string) bool {
+	return j.Type(path) != JSONMissing
+}
+
+// Type returns type of the value at path, JSONMissing when path does not
+// exist. Empty path refers to the JSON itself.
+func (j JSON) Type(path string) JSONType {
+	var r gjson.Result
+	switch p, err := jsonPath(path); {
+	case err != nil:
+		return JSONMissing
+	case p == "":
+		if !json.Valid(j) {
+			return JSONMissing
+		}
+		r = gjson.ParseBytes(j)
+	default:
+		r = gjson.GetBytes(j, p)
+	}
+	switch {
+	case !r.Exists():
+		return JSONMissing
+	case r.IsObject():
+		return JSONObject
+	case r.IsArray():
+		return JSONArray
+	}
+	switch r.Type {
+	case gjson.Null:
+		return JSONNull
+	case gjson.True, gjson.False:
+		return JSONBool
+	case gjson.Number:
+		return JSONNumber
+	default:
+		return JSONString
+	}
+}
+
+// JSONType is a type of JSON value returned by JSON.Type.
+type JSONType string
+
+const (
+	JSONMissing JSONType = ""
+	JSONNull    JSONType = "null"
+	JSONBool    JSONType = "boolean"
+	JSONNumber  JSONType = "number"
+	JSONString  JSONType = "string"
+	JSONArray   JSONType = "array"
+	JSONObject  JSONType = "object"
+)
+
 // IsObject checks if the current JSON node represents a JSON object {...}.
 func (j JSON) IsObject() bool {
 	if j.IsEmpty() {
@@ -316,6 +580,67 @@ func (j JSON) Number(path string) float64 {
 	return gjson.GetBytes(j, path).Float()
 }
 
+// Int returns the integer value at the specified path.
+// Floats are truncated, numeric strings are parsed.
+// It returns 0 if the path does not exist or is not a number.
+func (j JSON) Int(path string) int {
+	return int(j.Int64(path))
+}
+
+// Int64 returns the int64 value at the specified path. Integers, also given
+// as strings, are parsed exactly without going through float64, so 64-bit
+// IDs keep their precision.
+// It returns 0 if the path does not exist or is not a number.
+func (j JSON) Int64(path string) int64 {
+	return gjson.GetBytes(j, path).Int()
+}
+
+// Duration returns the time.Duration value at the specified path.
+// Strings are parsed with time.ParseDuration ("5s", "1h30m"), numbers and
+// numeric strings are treated as seconds. It returns 0 when the value
+// cannot be converted.
+func (j JSON) Duration(path string) time.Duration {
+	r := gjson.GetBytes(j, path)
+	switch r.Type {
+	case gjson.Number:
+		return time.Duration(r.Float() * float64(time.Second))
+	case gjson.String:
+		s := strings.TrimSpace(r.String())
+		if d, err := time.ParseDuration(s); err == nil {
+			return d
+		}
+		if f, err := strconv.ParseFloat(s, 64); err == nil {
+			return time.Duration(f * float64(time.Second))
+		}
+	}
+	return 0
+}
+
+// UUID returns the UUID string at the specified path in its canonical form.
+// It returns an empty string if the path does not exist or is not a valid UUID.
+func (j JSON) UUID(path string) string {
+	u, err := uuid.Parse(j.Text(path))
+	if err != nil {
+		return ""
+	}
+	return u.String()
+}
+
+// Strings returns the array at the specified path as []string.
+// Non-string elements are converted to their string representation.
+// It returns nil if the path does not exist or is not an array.
+func (j JSON) Strings(path string) []string {
+	r := gjson.GetBytes(j, path)
+	if !r.IsArray() {
+		return nil
+	}
+	var ss []string
+	for _, v := range r.Array() {
+		ss = append(ss, v.String())
+	}
+	return ss
+}
+
 // Bool returns the boolean value at the specified path.
 // It returns false if the path does not exist.
 func (j JSON) Bool(path string) bool {
@@ -330,6 +655,50 @@ func (j JSON) String() string {
 	return string(j)
 }
 
+// Compact returns a copy of JSON with insignificant whitespace removed.
+// Invalid JSON is returned unchanged.
+func (j JSON) Compact() JSON {
+	var b bytes.Buffer
+	if err := json.Compact(&b, j); err != nil {
+		return j
+	}
+	return b.Bytes()
+}
+
+// Pretty returns an indented copy of JSON, by default indentation is a tab.
+// Invalid JSON is returned unchanged.
+func (j JSON) Pretty(indent ...string) JSON {
+	var b bytes.Buffer
+	i := "\t"
+	if len(indent) > 0 {
+		i = indent[0]
+	}
+	if err := json.Indent(&b, j, "", i); err != nil {
+		return j
+	}
+	return b.Bytes()
+}
+
+// Canonical returns a stable representation of JSON suitable for hashing or
+// signing: object keys are sorted, whitespace is removed, numbers keep their
+// original literal and HTML characters are not escaped. Two semantically equal
+// documents produce identical bytes. Invalid JSON is returned unchanged.
+func (j JSON) Canonical() JSON {
+	var v any
+	d := json.NewDecoder(bytes.NewReader(j))
+	d.UseNumber()
+	if err := d.Decode(&v); err != nil {
+		return j
+	}
+	var b bytes.Buffer
+	e := json.NewEncoder(&b)
+	e.SetEscapeHTML(false)
+	if err := e.Encode(v); err != nil {
+		return j
+	}
+	return bytes.TrimRight(b.Bytes(), "\n")
+}
+
 // Sprintf formats a string using values extracted from the JSON at the specified paths.
 //
 // Unlike Readf (which works on raw JSON bytes), Sprintf converts JSON values to
@@ -368,6 +737,193 @@ func (j JSON) Each(paths ...string) Iterator[JSON, string] {
 	}
 }
 
+// Keys returns keys of the object in document order.
+// It returns nil when JSON is not an object.
+func (j JSON) Keys() []string {
+	var kk []string
+	for k := range j.Entries() {
+		kk = append(kk, k)
+	}
+	return kk
+}
+
+// Entries returns a function that iterates over key-value pairs of the object.
+// Nothing is yielded when JSON is not an object.
+//
+// Usage:
+//
+//	for key, value := range doc.Select("config").Entries() {
+//		fmt.Printf("%s = %s\n", key, value)
+//	}
+func (j JSON) Entries() Iterator[string, JSON] {
+	return func(yield func(string, JSON) bool) {
+		r := gjson.ParseBytes(j)
+		if !r.IsObject() {
+			return
+		}
+		r.ForEach(func(key, value gjson.Result) bool {
+			return yield(key.String(), JSON(value.Raw))
+		})
+	}
+}
+
+// Values converts object to url.Values for form-encoded requests and query
+// strings. Nested keys are written in bracket convention (a[b]=1) or, when
+// dots is true, in dot convention (a.b=1). Arrays of scalars repeat the key
+// (a[]=1&a[]=2 or a=1&a=2), arrays of objects are indexed (a[0][b]=1 or
+// a.0.b=1). Null values are encoded as empty strings.
+//
+// Example:
+//
+//	q := ion.JSON(`{"filter":{"status":"paid"},"tags":["a","b"]}`).Values()
+//	q.Encode() // filter%5Bstatus%5D=paid&tags%5B%5D=a&tags%5B%5D=b
+func (j JSON) Values(dots ...bool) url.Values {
+	v := url.Values{}
+	dot := len(dots) > 0 && dots[0]
+	key := func(prefix, k string) string {
+		switch {
+		case prefix == "":
+			return k
+		case dot:
+			return prefix + "." + k
+		default:
+			return prefix + "[" + k + "]"
+		}
+	}
+	var walk func(prefix string, n gjson.Result)
+	walk = func(prefix string, n gjson.Result) {
+		switch {
+		case n.IsObject():
+			n.ForEach(func(k, x gjson.Result) bool {
+				walk(key(prefix, k.String()), x)
+				return true
+			})
+		case n.IsArray():
+			i := 0
+			n.ForEach(func(_, x gjson.Result) bool {
+				if x.IsObject() || x.IsArray() {
+					walk(key(prefix, strconv.Itoa(i)), x)
+				} else if dot {
+					walk(prefix, x)
+				} else {
+					walk(prefix+"[]", x)
+				}
+				i++
+				return true
+			})
+		case prefix == "":
+		case n.Type == gjson.Null:
+			v.Add(prefix, "")
+		case n.Type == gjson.String:
+			v.Add(prefix, n.Str)
+		default:
+			v.Add(prefix, n.Raw)
+		}
+	}
+	if r := gjson.ParseBytes(j); r.IsObject() {
+		walk("", r)
+	}
+	return v
+}
+
+// Redact returns a copy of JSON safe for logging, with sensitive data masked
+// by the package Redactor (see UseRedactor) and values at given paths masked
+// as well. When redaction is disabled with UseRedactor(nil), only values at
+// given paths are masked.
+//
+// Example:
+//
+//	log.Debugf("request %s", body.Redact("customer.iban"))
+func (j JSON) Redact(paths ...string) JSON {
+	r := Redaction()
+	if r == nil {
+		r = &Redactor{Mask: "***"} // no rules, paths only
+	}
+	return r.JSON(j, paths...)
+}
+
+// Map returns a new array built from results of fn called on every element.
+// Nil results are stored as null. It returns nil when JSON is not an array.
+func (j JSON) Map(fn func(JSON) JSON) JSON {
+	if !gjson.ParseBytes(j).IsArray() {
+		return nil
+	}
+	var b bytes.Buffer
+	b.WriteByte('[')
+	for v, i := range j.Each() {
+		if i != "0" {
+			b.WriteByte(',')
+		}
+		r := fn(v)
+		if len(r) == 0 {
+			r = JSON("null")
+		}
+		b.Write(r)
+	}
+	b.WriteByte(']')
+	return b.Bytes()
+}
+
+// Filter returns a new array with elements for which fn returns true.
+// It returns nil when JSON is not an array.
+func (j JSON) Filter(fn func(JSON) bool) JSON {
+	if !gjson.ParseBytes(j).IsArray() {
+		return nil
+	}
+	var b bytes.Buffer
+	b.WriteByte('[')
+	for v := range j.Each() {
+		if !fn(v) {
+			continue
+		}
+		if b.Len() > 1 {
+			b.WriteByte(',')
+		}
+		b.Write(v)
+	}
+	b.WriteByte(']')
+	return b.Bytes()
+}
+
+// SortBy returns a new array sorted by value found at path of each element,
+// numbers are compared numerically and strings lexically, missing values go
+// first. Prefix path with "-" for descending order, empty path sorts by the
+// elements itself. Sorting is stable. It returns nil when JSON is not an array.
+//
+// Example:
+//
+//	j.Select("items").SortBy("-price")
+func (j JSON) SortBy(path string) JSON {
+	if !gjson.ParseBytes(j).IsArray() {
+		return nil
+	}
+	desc := strings.HasPrefix(path, "-")
+	path = strings.TrimPrefix(path, "-")
+	key := func(r gjson.Result) gjson.Result {
+		if path == "" {
+			return r
+		}
+		return r.Get(path)
+	}
+	rr := gjson.ParseBytes(j).Array()
+	sort.SliceStable(rr, func(a, b int) bool {
+		if desc {
+			a, b = b, a
+		}
+		return key(rr[a]).Less(key(rr[b]), true)
+	})
+	var b bytes.Buffer
+	b.WriteByte('[')
+	for i := range rr {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(rr[i].Raw)
+	}
+	b.WriteByte(']')
+	return b.Bytes()
+}
+
 // UnmarshalJSON ...
 func (j *JSON) UnmarshalJSON(data []byte) error {
 	if j == nil {
@@ -392,7 +948,7 @@ func (j JSON) Meta() Meta {
 		return Meta{}
 	}
 	var m Meta
-	if err := json.Unmarshal(j, &m); err != nil {
+	if err := jsonDecode(j, &m, jsonNumbers.Load()); err != nil {
 		return Meta{}
 	}
 	return m
@@ -409,14 +965,14 @@ func (j JSON) Meta() Meta {
 // The receiver `j` is modified in place.
 func (j *JSON) Merge(fragments ...JSON) error {
 	var base map[string]any
-	if err := json.Unmarshal(*j, &base); err != nil {
+	if err := jsonDecode(*j, &base, true); err != nil {
 		// If the receiver isn't a valid JSON object, start with an empty one.
 		base = make(map[string]any)
 	}
 
 	for _, fragment := range fragments {
 		var fragMap map[string]any
-		if err := json.Unmarshal(fragment, &fragMap); err != nil {
+		if err := jsonDecode(fragment, &fragMap, true); err != nil {
 			// Skip fragments that are not valid JSON objects.
 			continue
 		}
@@ -468,7 +1024,7 @@ func (j *JSON) mergeMaps(base, overlay map[string]any) map[string]any {
 			// This is a special behavior from the original implementation.
 			concatenated := baseStr + overlayStr
 			var jsonVal interface{}
-			if err := json.Unmarshal([]byte(concatenated), &jsonVal); err == nil {
+			if err := jsonDecode([]byte(concatenated), &jsonVal, true); err == nil {
 				base[key] = jsonVal
 			} else {
 				base[key] = concatenated
@@ -482,6 +1038,285 @@ func (j *JSON) mergeMaps(base, overlay map[string]any) map[string]any {
 	return base
 }
 
+// JSONNumbers makes Meta, Flat and To (into untyped targets like any or
+// map[string]any) keep numbers as json.Number instead of float64, so large
+// integers such as 64-bit IDs don't lose precision. Merge always keeps them.
+func JSONNumbers(enable bool) {
+	jsonNumbers.Store(enable)
+}
+
+// jsonDecode unmarshals b into v, keeping numbers as json.Number when number is set.
+func jsonDecode(b []byte, v any, number bool) error {
+	if !number {
+		return json.Unmarshal(b, v)
+	}
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.UseNumber()
+	if err := d.Decode(v); err != nil {
+		return err
+	}
+	if d.More() {
+		return ErrJSON.New("unexpected data after top-level value")
+	}
+	return nil
+}
+
+// jsonTolerant strips comments, trailing commas and Markdown fences outside of strings.
+func jsonTolerant(b []byte) JSON {
+	b = bytes.TrimSpace(b)
+	if bytes.HasPrefix(b, []byte("```")) {
+		if i := bytes.IndexByte(b, '\n'); i >= 0 {
+			b = bytes.TrimSuffix(bytes.TrimSpace(b[i+1:]), []byte("```"))
+		}
+	}
+	o := make(JSON, 0, len(b))
+	for i := 0; i < len(b); i++ {
+		switch c := b[i]; {
+		case c == '"':
+			n := i + 1
+			for ; n < len(b) && b[n] != '"'; n++ {
+				if b[n] == '\\' {
+					n++
+				}
+			}
+			o = append(o, b[i:min(n+1, len(b))]...)
+			i = n
+		case c == '/' && i+1 < len(b) && b[i+1] == '/':
+			for i < len(b) && b[i] != '\n' {
+				i++
+			}
+		case c == '/' && i+1 < len(b) && b[i+1] == '*':
+			n := bytes.Index(b[i+2:], []byte("*/"))
+			if n < 0 {
+				return o
+			}
+			i += n + 3
+		case c == ']' || c == '}':
+			t := bytes.TrimRight(o, " \t\r\n")
+			if len(t) > 0 && t[len(t)-1] == ',' {
+				o = append(t[:len(t)-1], o[len(t):]...)
+			}
+			o = append(o, c)
+		default:
+			o = append(o, c)
+		}
+	}
+	return o
+}
+
+// jsonCast decodes scalar j into target with conversion registered by
+// RegisterCast from string, float64 or bool, it reports whether one was found.
+func jsonCast(j JSON, target any) (bool, error) {
+	t := reflect.TypeOf(target)
+	if t == nil || t.Kind() != reflect.Pointer {
+		return false, nil
+	}
+	var v any
+	switch r := gjson.ParseBytes(j); r.Type {
+	case gjson.String:
+		v = r.Str
+	case gjson.Number:
+		v = r.Num
+	case gjson.True, gjson.False:
+		v = r.Bool()
+	default:
+		return false, nil
+	}
+	fn, ok := castFunc(reflect.TypeOf(v), t.Elem())
+	if !ok {
+		return false, nil
+	}
+	o, err := fn(v)
+	if err != nil {
+		return true, err
+	}
+	reflect.ValueOf(target).Elem().Set(reflect.ValueOf(o))
+	return true, nil
+}
+
+// jsonCasts tells whether t, or type nested in it, has conversion registered
+// by RegisterCast.
+func jsonCasts(t reflect.Type, seen map[reflect.Type]bool) bool {
+	if seen[t] {
+		return false
+	}
+	seen[t] = true
+	castsMu.RLock()
+	for k := range casts {
+		if k[1] == t {
+			castsMu.RUnlock()
+			return true
+		}
+	}
+	castsMu.RUnlock()
+	switch t.Kind() {
+	case reflect.Pointer, reflect.Slice, reflect.Array, reflect.Map:
+		return jsonCasts(t.Elem(), seen)
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			if f := t.Field(i); (f.IsExported() || f.Anonymous) && jsonCasts(f.Type, seen) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// jsonCastDecode decodes j into addressable v like jsonDecode, applying
+// conversions registered by RegisterCast to nested values as well.
+func jsonCastDecode(j JSON, v reflect.Value) error {
+	t := v.Type()
+	if ok, err := jsonCast(j, v.Addr().Interface()); ok {
+		return err
+	}
+	if !jsonCasts(t, map[reflect.Type]bool{}) || reflect.PointerTo(t).Implements(reflect.TypeFor[json.Unmarshaler]()) {
+		return jsonDecode(j, v.Addr().Interface(), jsonNumbers.Load())
+	}
+	switch r := gjson.ParseBytes(j); {
+	case r.Type == gjson.Null:
+		v.SetZero()
+	case t.Kind() == reflect.Pointer:
+		if v.IsNil() {
+			v.Set(reflect.New(t.Elem()))
+		}
+		return jsonCastDecode(j, v.Elem())
+	case t.Kind() == reflect.Slice && r.IsArray():
+		aa := r.Array()
+		s := reflect.MakeSlice(t, len(aa), len(aa))
+		for i := range aa {
+			if err := jsonCastDecode(JSON(aa[i].Raw), s.Index(i)); err != nil {
+				return err
+			}
+		}
+		v.Set(s)
+	case t.Kind() == reflect.Map && t.Key().Kind() == reflect.String && r.IsObject():
+		m := reflect.MakeMap(t)
+		var err error
+		r.ForEach(func(k, x gjson.Result) bool {
+			e := reflect.New(t.Elem()).Elem()
+			if err = jsonCastDecode(JSON(x.Raw), e); err != nil {
+				return false
+			}
+			m.SetMapIndex(reflect.ValueOf(k.Str).Convert(t.Key()), e)
+			return true
+		})
+		if err != nil {
+			return err
+		}
+		v.Set(m)
+	case t.Kind() == reflect.Struct && r.IsObject():
+		return jsonCastFields(r, v)
+	default:
+		return jsonDecode(j, v.Addr().Interface(), jsonNumbers.Load())
+	}
+	return nil
+}
+
+// jsonCastFields decodes fields of struct v from object r, matching their
+// json names exactly or case-insensitively, as encoding/json does.
+func jsonCastFields(r gjson.Result, v reflect.Value) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		n, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if n == "-" || (!f.IsExported() && !f.Anonymous) {
+			continue
+		}
+		if f.Anonymous && n == "" && f.Type.Kind() == reflect.Struct {
+			if err := jsonCastFields(r, v.Field(i)); err != nil {
+				return err
+			}
+			continue
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if n == "" {
+			n = f.Name
+		}
+		var x, fold gjson.Result
+		r.ForEach(func(k, e gjson.Result) bool {
+			if k.Str == n {
+				x = e
+				return false
+			}
+			if !fold.Exists() && strings.EqualFold(k.Str, n) {
+				fold = e
+			}
+			return true
+		})
+		if !x.Exists() {
+			x = fold
+		}
+		if !x.Exists() {
+			continue
+		}
+		if err := jsonCastDecode(JSON(x.Raw), v.Field(i)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// jsonValue returns native Go value of r, numbers are json.Number in JSONNumbers mode.
+func jsonValue(r gjson.Result) any {
+	if r.Type == gjson.Number && jsonNumbers.Load() {
+		return json.Number(r.Raw)
+	}
+	return r.Value()
+}
+
+// jsonPath validates p and translates JSONPath-like syntax ([0], [*],
+// [?(@.x == 'y')]) into gjson syntax.
+func jsonPath(p string) (string, error) {
+	var open []rune
+	var quote rune
+	for _, r := range p {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '\'' || r == '"':
+			quote = r
+		case r == '[' || r == '(':
+			open = append(open, r)
+		case r == ']' || r == ')':
+			if n := len(open); n == 0 || (r == ']') != (open[n-1] == '[') {
+				return "", ErrJSONPath.New("%q has unexpected %c", p, r)
+			}
+			open = open[:len(open)-1]
+		}
+	}
+	if len(open) > 0 || quote != 0 {
+		return "", ErrJSONPath.New("%q is not closed", p)
+	}
+	if strings.HasSuffix(p, ".") {
+		return "", ErrJSONPath.New("%q ends with a dot", p)
+	}
+	p = strings.ReplaceAll(p, "[?(@.", ".#(")
+	p = strings.ReplaceAll(p, ")]", ")")
+	p = strings.ReplaceAll(p, "'", "\"")
+	if strings.Contains(p, "[*]") {
+		if strings.HasPrefix(p, "[*]") {
+			p = "#" + p[3:]
+		} else {
+			p = strings.ReplaceAll(p, "[*]", ".#")
+		}
+	}
+	return jsonIndex.ReplaceAllString(p, ".$1"), nil
+}
+
+var (
+	ErrJSON     = Errorf("json")
+	ErrJSONPath = ErrJSON.New("path")
+	jsonStrict  atomic.Bool
+	jsonNumbers atomic.Bool
+	jsonIndex   = regexp.MustCompile(`\[(\d+)\]`)
+	// jsonUnmarshaler is a type of values decoding JSON themselves.
+	jsonUnmarshaler = reflect.TypeFor[json.Unmarshaler]()
+)
+
 type Meta map[string]any
 
 func (m Meta) String() string {
@@ -502,6 +1337,104 @@ func (m Meta) JSON(path ...string) JSON {
 	return b
 }
 
-func (m Meta) To() any {
-	return nil
+// Select returns JSON found at path, see JSON.Select.
+func (m Meta) Select(paths ...string) JSON {
+	return m.JSON().Select(paths...)
+}
+
+// Read extracts values at paths into targets, see JSON.Read.
+func (m Meta) Read(pathTargets ...any) error {
+	return m.JSON().Read(pathTargets...)
+}
+
+// To decodes Meta into target, see JSON.To.
+func (m Meta) To(target any, fallback ...any) error {
+	if m.IsEmpty() {
+		return JSON(nil).To(target, fallback...)
+	}
+	return m.JSON().To(target, fallback...)
+}
+
+// Each iterates over elements at path, see JSON.Each.
+func (m Meta) Each(paths ...string) Iterator[JSON, string] {
+	return m.JSON().Each(paths...)
+}
+
+// Has reports whether path exists, see JSON.Has.
+func (m Meta) Has(path string) bool {
+	return m.JSON().Has(path)
+}
+
+// Text returns string at path.
+func (m Meta) Text(path string) string {
+	return m.JSON().Text(path)
+}
+
+// Number returns number at path.
+func (m Meta) Number(path string) float64 {
+	return m.JSON().Number(path)
+}
+
+// Int returns integer at path.
+func (m Meta) Int(path string) int {
+	return m.JSON().Int(path)
+}
+
+// Bool returns boolean at path.
+func (m Meta) Bool(path string) bool {
+	return m.JSON().Bool(path)
+}
+
+// Time returns time at path, see JSON.Time.
+func (m Meta) Time(path string) time.Time {
+	return m.JSON().Time(path)
+}
+
+// Flat flattens Meta into one-dimensional map, see JSON.Flat.
+func (m Meta) Flat() Meta {
+	return m.JSON().Flat()
+}
+
+// Set stores value at dot path, creating nested Meta when needed.
+// Unlike JSON.Set it modifies the map in place.
+//
+// Example:
+//
+//	m := ion.Meta{}
+//	m.Set("user.address.city", "Warsaw")
+func (m Meta) Set(path string, value any) {
+	kk := strings.Split(path, ".")
+	for _, k := range kk[:len(kk)-1] {
+		n, ok := m.child(k)
+		if !ok {
+			n = Meta{}
+			m[k] = n
+		}
+		m = n
+	}
+	m[kk[len(kk)-1]] = value
+}
+
+// Delete removes value at dot path, it does nothing when path is missing.
+func (m Meta) Delete(path string) {
+	kk := strings.Split(path, ".")
+	for _, k := range kk[:len(kk)-1] {
+		n, ok := m.child(k)
+		if !ok {
+			return
+		}
+		m = n
+	}
+	delete(m, kk[len(kk)-1])
+}
+
+// child returns nested object stored under k.
+func (m Meta) child(k string) (Meta, bool) {
+	switch n := m[k].(type) {
+	case Meta:
+		return n, true
+	case map[string]any:
+		return n, true
+	}
+	return nil, false
 }
@@ -0,0 +1,98 @@
+package ion
+
+import (
+	"slices"
+	"time"
+
+	"github.com/tidwall/gjson"
+)
+
+// Schema infers JSON Schema from the receiver and other sample documents:
+// types of values (integer when all numbers are whole), properties of objects
+// with keys present in every sample marked as required, and shapes of array
+// items. Values of different types across samples give a list of types,
+// nulls add the "null" type. Strings which are all RFC 3339 times get
+// the date-time format.
+//
+// Example:
+//
+//	s := ion.JSON(`{"id":1,"tags":["a"]}`).Schema(ion.JSON(`{"id":2,"note":null}`))
+//	// {"properties":{"id":{"type":"integer"},"note":{"type":"null"},
+//	//   "tags":{"items":{"type":"string"},"type":"array"}},"required":["id"],"type":"object"}
+//
+// It is handy for LLM tool parameters and contract tests built from real payloads.
+func (j JSON) Schema(samples ...JSON) JSON {
+	var rr []gjson.Result
+	for _, s := range append([]JSON{j}, samples...) {
+		if len(s) != 0 {
+			rr = append(rr, gjson.ParseBytes(s))
+		}
+	}
+	return jsonSchema(rr).JSON()
+}
+
+func jsonSchema(rr []gjson.Result) Meta {
+	m := Meta{}
+	var tt []string
+	var objects, items, texts []gjson.Result
+	integers := true
+	for _, r := range rr {
+		t := string(JSON(r.Raw).Type(""))
+		switch {
+		case r.IsObject():
+			objects = append(objects, r)
+		case r.IsArray():
+			items = append(items, r.Array()...)
+		case r.Type == gjson.Number:
+			integers = integers && r.Num == float64(int64(r.Num))
+		case r.Type == gjson.String:
+			texts = append(texts, r)
+		}
+		if !slices.Contains(tt, t) {
+			tt = append(tt, t)
+		}
+	}
+	if i := slices.Index(tt, string(JSONNumber)); i >= 0 && integers {
+		tt[i] = "integer"
+	}
+	slices.Sort(tt)
+	switch len(tt) {
+	case 0:
+	case 1:
+		m["type"] = tt[0]
+	default:
+		m["type"] = tt
+	}
+	if len(objects) > 0 {
+		props, count := map[string][]gjson.Result{}, map[string]int{}
+		for _, o := range objects {
+			o.ForEach(func(k, v gjson.Result) bool {
+				props[k.Str] = append(props[k.Str], v)
+				count[k.Str]++
+				return true
+			})
+		}
+		pp, req := Meta{}, []string{}
+		for k, vv := range props {
+			pp[k] = jsonSchema(vv)
+			if count[k] == len(objects) {
+				req = append(req, k)
+			}
+		}
+		slices.Sort(req)
+		m["properties"] = pp
+		if len(req) > 0 {
+			m["required"] = req
+		}
+	}
+	if slices.Contains(tt, string(JSONArray)) {
+		m["items"] = jsonSchema(items)
+	}
+	if len(texts) > 0 && len(tt) == 1 && slices.IndexFunc(texts, func(r gjson.Result) bool {
+		_, err := time.Parse(time.RFC3339Nano, r.Str)
+		return err != nil
+	}) < 0 {
+		m["format"] = "date-time"
+	}
+	return m
+}
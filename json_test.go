@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"reflect"
 	"testing"
+	"time"
 
 	. "github.com/sokool/ion"
 )
@@ -117,6 +118,42 @@ func TestJSON(t *testing.T) {
 	}
 }
 
+func TestJSON_Getters(t *testing.T) {
+	m := JSON(`{
+	"id": 9007199254740993,
+	"age": 30.6,
+	"timeout": "1m30s",
+	"ttl": 2.5,
+	"uuid": "6BA7B810-9DAD-11D1-80B4-00C04FD430C8",
+	"broken": "6ba7b810",
+	"tags": ["a", 1, true]
+}`)
+	if n := m.Int("age"); n != 30 {
+		t.Fatalf("expected 30, got %d", n)
+	}
+	if n := m.Int64("id"); n != 9007199254740993 {
+		t.Fatalf("expected 9007199254740993, got %d", n)
+	}
+	if d := m.Duration("timeout"); d != 90*time.Second {
+		t.Fatalf("expected 1m30s, got %s", d)
+	}
+	if d := m.Duration("ttl"); d != 2500*time.Millisecond {
+		t.Fatalf("expected 2.5s, got %s", d)
+	}
+	if s := m.UUID("uuid"); s != "6ba7b810-9dad-11d1-80b4-00c04fd430c8" {
+		t.Fatalf("expected canonical uuid, got %s", s)
+	}
+	if s := m.UUID("broken"); s != "" {
+		t.Fatalf("expected empty uuid, got %s", s)
+	}
+	if ss := m.Strings("tags"); fmt.Sprintf("%v", ss) != "[a 1 true]" {
+		t.Fatalf("expected [a 1 true], got %v", ss)
+	}
+	if ss := m.Strings("age"); ss != nil {
+		t.Fatalf("expected nil, got %v", ss)
+	}
+}
+
 func TestJSON_Each(t *testing.T) {
 	cases := []struct {
 		name           string
@@ -307,3 +344,270 @@ func TestJSON_Merge(t *testing.T) {
 		})
 	}
 }
+
+func TestJSON_Canonical(t *testing.T) {
+	a := JSON(`{ "b": [1, 2.50, {"y": "<a>", "x": null}], "a": 12345678901234567890 }`)
+	b := JSON(`{"a":12345678901234567890,"b":[1,2.50,{"x":null,"y":"<a>"}]}`)
+	if c := a.Canonical(); !bytes.Equal(c, b) {
+		t.Fatalf("expected %s, got %s", b, c)
+	}
+	if c := JSON(`{"a": 1}`).Compact(); string(c) != `{"a":1}` {
+		t.Fatalf("expected compact, got %s", c)
+	}
+	if p := JSON(`{"a":1}`).Pretty("  "); string(p) != "{\n  \"a\": 1\n}" {
+		t.Fatalf("expected pretty, got %s", p)
+	}
+	if c := JSON(`{broken`).Canonical(); string(c) != `{broken` {
+		t.Fatalf("expected unchanged, got %s", c)
+	}
+}
+
+func TestJSON_Lookup(t *testing.T) {
+	m := JSON(`{"jobs":[{"title":"developer"},{"title":"manager"}]}`)
+	paths := []string{"jobs[1].title", "jobs[0].title"}
+	if s := m.Select(paths...); string(s) != `{"title":"developer"}` {
+		t.Fatalf("unexpected select %s", s)
+	}
+	if paths[0] != "jobs[1].title" {
+		t.Fatalf("expected unchanged paths, got %v", paths)
+	}
+	for _, p := range []string{"jobs[1.title", "jobs].title", "jobs[?(@.title == 'ceo']", "jobs."} {
+		if j, err := m.Lookup(p); !ErrJSONPath.In(err) || j != nil {
+			t.Fatalf("expected path error for %s, got %s %v", p, j, err)
+		}
+	}
+	if j, err := m.Lookup("jobs[?(@.title == 'manager')].title"); err != nil || j.String() != `"manager"` {
+		t.Fatalf("expected manager, got %s %v", j, err)
+	}
+	if _, err := m.Lookup("jobs[0].title", "jobs[1", "jobs."); len(ErrJSON.Split(err)) != 2 {
+		t.Fatalf("expected both path errors, got %v", err)
+	}
+	if string(m) != `{"jobs":[{"title":"developer"},{"title":"manager"}]}` {
+		t.Fatalf("expected unchanged receiver, got %s", m)
+	}
+	JSONStrictPaths(true)
+	defer JSONStrictPaths(false)
+	defer func() {
+		if r := recover(); r == nil {
+			t.Fatal("expected panic in strict mode")
+		}
+	}()
+	m.Select("jobs[1.title")
+}
+
+func TestJSON_Pick(t *testing.T) {
+	m := JSON(`{"user":{"name":"John","age":30},"meta":{"count":2},"jobs":[{"title":"dev"},{"title":"ceo"}]}`)
+	cases := []struct {
+		paths    []string
+		expected string
+	}{
+		{[]string{"user.name", "meta.count"}, `{"user":{"name":"John"},"meta":{"count":2}}`},
+		{[]string{"user.name", "missing"}, `{"user":{"name":"John"}}`},
+		{[]string{"jobs[*].title"}, `{"title":["dev","ceo"]}`},
+		{nil, `{}`},
+	}
+	for _, tc := range cases {
+		if p := m.Pick(tc.paths...); string(p) != tc.expected {
+			t.Fatalf("expected %s, got %s", tc.expected, p)
+		}
+	}
+}
+
+func TestJSON_MapFilterSort(t *testing.T) {
+	m := JSON(`[{"n":"b","p":20},{"n":"a","p":5},{"n":"c"},{"n":"d","p":20.5}]`)
+	if s := m.Map(func(j JSON) JSON { return j.Select("n") }); string(s) != `["b","a","c","d"]` {
+		t.Fatalf("unexpected map %s", s)
+	}
+	if s := m.Filter(func(j JSON) bool { return j.Number("p") > 10 }); string(s) != `[{"n":"b","p":20},{"n":"d","p":20.5}]` {
+		t.Fatalf("unexpected filter %s", s)
+	}
+	if s := m.SortBy("p").Map(func(j JSON) JSON { return j.Select("n") }); string(s) != `["c","a","b","d"]` {
+		t.Fatalf("unexpected sort %s", s)
+	}
+	if s := m.SortBy("-n").Map(func(j JSON) JSON { return j.Select("n") }); string(s) != `["d","c","b","a"]` {
+		t.Fatalf("unexpected desc sort %s", s)
+	}
+	if s := JSON(`[]`).Filter(func(JSON) bool { return true }); string(s) != `[]` {
+		t.Fatalf("unexpected empty filter %s", s)
+	}
+	if s := JSON(`{"a":1}`).Map(func(j JSON) JSON { return j }); s != nil {
+		t.Fatalf("expected nil for object, got %s", s)
+	}
+}
+
+func TestJSON_Entries(t *testing.T) {
+	m := JSON(`{"b":1,"a":{"c":true},"d":[1]}`)
+	if k := m.Keys(); fmt.Sprintf("%v", k) != "[b a d]" {
+		t.Fatalf("expected [b a d], got %v", k)
+	}
+	var s string
+	for k, v := range m.Entries() {
+		s += k + "=" + v.String() + ";"
+	}
+	if s != `b=1;a={"c":true};d=[1];` {
+		t.Fatalf("unexpected entries %s", s)
+	}
+	if k := JSON(`[1,2]`).Keys(); k != nil {
+		t.Fatalf("expected nil keys for array, got %v", k)
+	}
+}
+
+func TestJSON_Numbers(t *testing.T) {
+	j := JSON(`{"id":9007199254740993,"name":"a"}`)
+	if err := j.Merge(JSON(`{"name":"b"}`)); err != nil || j.Int64("id") != 9007199254740993 {
+		t.Fatalf("expected exact id after merge, got %s %v", j, err)
+	}
+	JSONNumbers(true)
+	defer JSONNumbers(false)
+	if id := j.Meta()["id"]; fmt.Sprint(id) != "9007199254740993" {
+		t.Fatalf("expected exact id in meta, got %v", id)
+	}
+	if id := j.Flat()["id"]; fmt.Sprint(id) != "9007199254740993" {
+		t.Fatalf("expected exact id in flat, got %v", id)
+	}
+	var v any
+	if err := j.Select("id").To(&v); err != nil || fmt.Sprint(v) != "9007199254740993" {
+		t.Fatalf("expected exact id, got %v %v", v, err)
+	}
+}
+
+func TestNewJSON(t *testing.T) {
+	s := "```json\n{\n\t// name of the app\n\t\"name\": \"ion // not a comment\", /* inline */\n\t\"tags\": [\"a\", \"b\",],\n}\n```"
+	if _, err := NewJSON(s); !ErrJSON.In(err) {
+		t.Fatalf("expected strict error, got %v", err)
+	}
+	j, err := NewJSON(s, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if j.Text("name") != "ion // not a comment" || len(j.Strings("tags")) != 2 {
+		t.Fatalf("unexpected json %s", j)
+	}
+	if _, err = NewJSON([]byte(`{"a":1}`)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestJSON_Redact(t *testing.T) {
+	j := JSON(`{"user":{"name":"Tom","token":"abc","iban":"PL61109010140000071219812874"}}`)
+	exp := `{"user":{"name":"Tom","token":"***","iban":"***"}}`
+	if s := j.Redact("user.iban"); string(s) != exp {
+		t.Fatalf("expected %s, got %s", exp, s)
+	}
+	if s := JSON(`token=abc tom@example.com`).Redact(); string(s) != `token=abc ***` {
+		t.Fatalf("expected text redaction of invalid json, got %s", s)
+	}
+	UseRedactor(nil)
+	defer UseRedactor(NewRedactor())
+	if s := j.Redact(); string(s) != string(j) {
+		t.Fatalf("expected json unchanged when redaction is disabled, got %s", s)
+	}
+	exp = `{"user":{"name":"Tom","token":"abc","iban":"***"}}`
+	if s := j.Redact("user.iban"); string(s) != exp {
+		t.Fatalf("expected given paths masked when redaction is disabled, got %s", s)
+	}
+}
+
+func TestJSON_Values(t *testing.T) {
+	j := JSON(`{"a":{"b":1,"c":null},"tags":["x","y"],"items":[{"id":"p1"}],"ok":true}`)
+	if s := j.Values().Encode(); s != "a%5Bb%5D=1&a%5Bc%5D=&items%5B0%5D%5Bid%5D=p1&ok=true&tags%5B%5D=x&tags%5B%5D=y" {
+		t.Fatalf("unexpected brackets encoding %s", s)
+	}
+	if s := j.Values(true).Encode(); s != "a.b=1&a.c=&items.0.id=p1&ok=true&tags=x&tags=y" {
+		t.Fatalf("unexpected dots encoding %s", s)
+	}
+	if v := JSON(`[1,2]`).Values(); len(v) != 0 {
+		t.Fatalf("expected no values of array, got %v", v)
+	}
+}
+
+func TestMeta(t *testing.T) {
+	m := Meta{"user": map[string]any{"name": "Tom", "age": 42}, "at": "2024-05-01T10:00:00Z"}
+	if m.Text("user.name") != "Tom" || m.Int("user.age") != 42 || m.Time("at").Day() != 1 {
+		t.Fatalf("unexpected accessors of %s", m)
+	}
+	var name string
+	var age int
+	if err := m.Read("user.name", &name, "user.age", &age); err != nil || name != "Tom" || age != 42 {
+		t.Fatalf("unexpected read %s %d %v", name, age, err)
+	}
+	m.Set("user.address.city", "Warsaw")
+	m.Delete("user.age")
+	m.Delete("missing.path")
+	if s := m.Select("user").String(); s != `{"address":{"city":"Warsaw"},"name":"Tom"}` {
+		t.Fatalf("unexpected user %s", s)
+	}
+	var n int
+	for range m.Each("user") {
+		n++
+	}
+	if n != 2 {
+		t.Fatalf("expected 2 user fields, got %d", n)
+	}
+	var u struct{ Name string }
+	if err := m.Select("user").To(&u); err != nil || u.Name != "Tom" {
+		t.Fatalf("unexpected %v %v", u, err)
+	}
+}
+
+func TestJSON_Has(t *testing.T) {
+	j := JSON(`{"count":0,"name":"","tags":[],"opts":{},"none":null,"ok":false,"list":[{"id":1}]}`)
+	for p, exp := range map[string]JSONType{
+		"count": JSONNumber, "name": JSONString, "tags": JSONArray, "opts": JSONObject,
+		"none": JSONNull, "ok": JSONBool, "list[0].id": JSONNumber, "missing": JSONMissing,
+		"list[0": JSONMissing, "": JSONObject,
+	} {
+		if typ := j.Type(p); typ != exp {
+			t.Fatalf("expected %q type of %q, got %q", exp, p, typ)
+		}
+		if j.Has(p) != (exp != JSONMissing) {
+			t.Fatalf("unexpected Has(%q)", p)
+		}
+	}
+	tags := []string{"fallback"}
+	if err := j.Select("tags").To(&tags, []string{"fallback"}); err != nil || tags == nil || len(tags) != 0 {
+		t.Fatalf("expected empty tags instead of fallback, got %v %v", tags, err)
+	}
+	var n []string
+	if err := j.Select("missing").To(&n, []string{"fallback"}); err != nil || len(n) != 1 {
+		t.Fatalf("expected fallback, got %v %v", n, err)
+	}
+}
+
+func TestJSON_Schema(t *testing.T) {
+	s := JSON(`{"id":1,"tags":["a"],"at":"2024-05-01T10:00:00Z","user":{"name":"Tom"}}`).
+		Schema(JSON(`{"id":2.5,"tags":[],"note":null,"user":{"name":"Ann","age":3}}`))
+	exp := `{"properties":{"at":{"format":"date-time","type":"string"},"id":{"type":"number"},"note":{"type":"null"},` +
+		`"tags":{"items":{"type":"string"},"type":"array"},"user":{"properties":{"age":{"type":"integer"},"name":{"type":"string"}},` +
+		`"required":["name"],"type":"object"}},"required":["id","tags","user"],"type":"object"}`
+	if string(s) != exp {
+		t.Fatalf("expected\n%s\ngot\n%s", exp, s)
+	}
+	if s = JSON(`[1,"a",null]`).Schema(); string(s) != `{"items":{"type":["integer","null","string"]},"type":"array"}` {
+		t.Fatalf("unexpected mixed array schema %s", s)
+	}
+}
+
+func TestJSON_Project(t *testing.T) {
+	type line struct {
+		SKU string `json:"sku"`
+	}
+	type base struct {
+		ID string `json:"id"`
+	}
+	type order struct {
+		base
+		Lines  []line          `json:"lines"`
+		At     time.Time       `json:"at"`
+		Attrs  map[string]line `json:"attrs"`
+		Any    any             `json:"any"`
+		Secret string          `json:"-"`
+		Note   *string
+	}
+	j := JSON(`{"id":"o1","extra":1,"Secret":"x","note":"n","lines":[{"sku":"a","qty":2}],` +
+		`"at":"2024-05-01T10:00:00Z","attrs":{"x":{"sku":"b","y":1}},"any":{"deep":true}}`)
+	exp := `{"id":"o1","note":"n","lines":[{"sku":"a"}],"at":"2024-05-01T10:00:00Z","attrs":{"x":{"sku":"b"}},"any":{"deep":true}}`
+	if s := j.Project(&order{}); string(s) != exp {
+		t.Fatalf("expected\n%s\ngot\n%s", exp, s)
+	}
+}
@@ -2,6 +2,9 @@ package ion
 
 import (
 	"context"
+	"math"
+	"sync"
+	"time"
 
 	"golang.org/x/time/rate"
 )
@@ -10,27 +13,127 @@ type Limiter interface {
 	Check(ctx context.Context, key string) error
 }
 
+// LimiterN is a Limiter taking n tokens at once, like a budget of LLM tokens
+// per minute. Limiters created by default NewLimiter implement it.
+type LimiterN interface {
+	Limiter
+	CheckN(ctx context.Context, key string, n int) error
+}
+
 type LimiterFunc func(rps float64) Limiter
 
 func UseLimiter(f LimiterFunc) {
 	NewLimiter = f
 }
 
+// NewLimiter creates token-bucket Limiter allowing rps requests per second for
+// each key. Buckets are kept in the process, their state is saved in the Store
+// (see UseStore) at most once a second, so a restarted process continues with
+// the budget left by the previous one instead of bursting past quotas tracked
+// by vendors. Processes running at the same time do not share the budget.
 var NewLimiter LimiterFunc = func(rps float64) Limiter {
-	return &limiter{rps: rps, limiters: make(map[string]*rate.Limiter)}
+	return newLimiter(rps, 1)
+}
+
+// newLimiter creates token-bucket limiter refilled with rps tokens per second
+// up to burst tokens.
+func newLimiter(rps float64, burst int) *limiter {
+	return &limiter{rps: rps, burst: max(burst, 1), limiters: make(map[string]*limiterEntry)}
 }
 
 type limiter struct {
+	mu       sync.Mutex
 	rps      float64
-	limiters map[string]*rate.Limiter
+	burst    int
+	limiters map[string]*limiterEntry
+}
+
+// limiterEntry is a bucket of the key and time its state was saved.
+type limiterEntry struct {
+	*rate.Limiter
+	stored time.Time
 }
 
 func (l *limiter) Check(ctx context.Context, key string) error {
-	if _, ok := l.limiters[key]; ok {
-		l.limiters[key] = rate.NewLimiter(rate.Limit(l.rps), 1)
-	}
-	if l.limiters[key].Allow() {
+	return l.CheckN(ctx, key, 1)
+}
+
+// CheckN waits until n tokens are available, n larger than the burst waits
+// for the full bucket.
+func (l *limiter) CheckN(ctx context.Context, key string, n int) error {
+	rl := l.limiter(ctx, key)
+	defer l.store(ctx, key, rl, false)
+	n = min(n, rl.Burst())
+	if rl.AllowN(time.Now(), n) {
 		return nil
 	}
-	return l.limiters[key].Wait(ctx)
+	return rl.WaitN(ctx, n)
+}
+
+// take consumes n tokens without waiting, so the following checks wait for
+// them. It settles usage known only after the call, like generated tokens.
+func (l *limiter) take(ctx context.Context, key string, n int) {
+	if n <= 0 {
+		return
+	}
+	rl := l.limiter(ctx, key)
+	rl.ReserveN(time.Now(), min(n, rl.Burst()))
+	l.store(ctx, key, rl, true)
+}
+
+// limiter returns bucket of the key, restoring its tokens from the Store when
+// seen first time by this process. Full buckets of other keys are dropped, as
+// they do not differ from new ones.
+func (l *limiter) limiter(ctx context.Context, key string) *limiterEntry {
+	l.mu.Lock()
+	rl, ok := l.limiters[key]
+	l.mu.Unlock()
+	if ok {
+		return rl
+	}
+	// loaded without the lock, so Store calls do not hold back other keys
+	rl = &limiterEntry{Limiter: rate.NewLimiter(rate.Limit(l.rps), l.burst)}
+	var s limiterState
+	if Get(WithCache(ctx), "limiter:%s", &s, key) > 0 {
+		t := s.Tokens + time.Since(s.At).Seconds()*l.rps
+		if n := float64(rl.Burst()) - t; n > 0 {
+			rl.ReserveN(time.Now(), min(int(math.Ceil(n)), rl.Burst()))
+		}
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if x, ok := l.limiters[key]; ok {
+		return x // created meanwhile
+	}
+	for k, e := range l.limiters {
+		if e.Tokens() >= float64(e.Burst()) {
+			delete(l.limiters, k)
+		}
+	}
+	l.limiters[key] = rl
+	return rl
+}
+
+// store saves state of the bucket, unless it was saved within the last second
+// and force is false.
+func (l *limiter) store(ctx context.Context, key string, rl *limiterEntry, force bool) {
+	if l.rps <= 0 || ctx.Err() != nil {
+		return
+	}
+	l.mu.Lock()
+	skip := !force && time.Since(rl.stored) < time.Second
+	if !skip {
+		rl.stored = time.Now()
+	}
+	l.mu.Unlock()
+	if skip {
+		return
+	}
+	ttl := time.Duration(float64(rl.Burst()) / l.rps * float64(time.Second))
+	Set(WithCache(ctx), "limiter:"+key, limiterState{Tokens: rl.Tokens(), At: time.Now()}, ttl+time.Second)
+}
+
+type limiterState struct {
+	Tokens float64   `json:"tokens"`
+	At     time.Time `json:"at"`
 }
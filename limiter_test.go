@@ -0,0 +1,42 @@
+package ion_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/sokool/ion"
+)
+
+func TestLimiter_Restore(t *testing.T) {
+	ctx := context.Background()
+	if err := ion.NewLimiter(1).Check(ctx, "restore"); err != nil {
+		t.Fatal(err)
+	}
+	// a new limiter, like after process restart, continues with stored budget
+	c, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+	defer cancel()
+	if err := ion.NewLimiter(1).Check(c, "restore"); err == nil {
+		t.Fatal("expected limiter to wait for the token refill")
+	}
+	if err := ion.NewLimiter(1).Check(ctx, "other"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLimiter_CheckN(t *testing.T) {
+	ctx := context.Background()
+	l, ok := ion.NewLimiter(1).(ion.LimiterN)
+	if !ok {
+		t.Fatal("expected default limiter to implement LimiterN")
+	}
+	// n larger than the burst waits for the full bucket instead of failing
+	if err := l.CheckN(ctx, "check-n", 10); err != nil {
+		t.Fatal(err)
+	}
+	c, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+	defer cancel()
+	if err := l.CheckN(c, "check-n", 1); err == nil {
+		t.Fatal("expected limiter to wait for the token refill")
+	}
+}
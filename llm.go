@@ -3,6 +3,7 @@ package ion
 import (
 	"context"
 	"fmt"
+	"maps"
 	"strconv"
 	"strings"
 	"time"
@@ -19,14 +20,41 @@ type LLM struct {
 	Cache time.Duration
 	// Temperature controls the randomness of the generated output.
 	Temperature float64
+	// MaxTokens limits length of the generated output, vendor default when zero.
+	MaxTokens int
+	// TopP limits sampling to tokens of the given cumulative probability, in (0..1].
+	TopP float64
+	// Stop sequences end generation, up to 4 for ChatGPT and Claude, 5 for Gemini.
+	Stop []string
+	// Seed makes sampling repeatable where vendor supports it, Claude does not.
+	Seed int
+	// Logprobs adds log probabilities of generated tokens to replies, see
+	// Message.Logprobs, Claude does not support it.
+	Logprobs bool
+	// TopLogprobs is a number of most likely candidates, up to 20, returned
+	// for every token when Logprobs is enabled.
+	TopLogprobs int
 	// Tools provides functions and schemas for tool-based completions.
 	Tool []Tool
+	// Gemini specific settings, ignored by other vendors.
+	Gemini *GeminiSettings `json:",omitempty"`
+	// Builtin tools run by OpenAI Responses API, ie. {"type": "web_search"},
+	// setting them selects the API.
+	Builtin []Meta `json:",omitempty"`
 	// Instruction base information for llm model like a role, or style etc...
 	Instruction string
 	// Persistent keep each message in storage
 	Persistent bool
+	// Retries of rate limited and failed vendor calls, 3 when zero, negative disables them.
+	Retries int
 	// Options
 	Options Meta
+
+	// schema of expected reply, see Structured
+	schema JSON
+	guards []func(direction, content string) error
+	// injection checks tool results, see Detect
+	injection *InjectionDetector
 }
 
 // JSON takes the response from LLM strips Markdown JSON fences,
@@ -41,20 +69,38 @@ func (c *LLM) JSON(text string) (JSON, error) {
 	return JSON(strings.NewReplacer("```json", "", "```", "").Replace(o)), nil
 }
 
-func (c *LLM) Response(ctx context.Context, m ...Message) ([]Message, error) {
+func (c *LLM) Response(ctx context.Context, m ...Message) (o []Message, err error) {
 	api, vendor, err := c.api()
 	if err != nil {
 		return nil, ErrCompletion.Wrap(err)
 	}
-	switch vendor {
-	case "ChatGPT":
-		return c.chatGPT(ctx, api, m...)
-	case "Gemini":
-		return c.gemini(ctx, api, m...)
-	default:
-		return c.chatGPT(ctx, api, m...)
+	if vendor == "ChatGPT" && c.responses(api) {
+		vendor = "Responses"
 	}
-
+	if err = c.validate(vendor); err != nil {
+		return nil, err
+	}
+	nested := ctx.Value(llmGuarded{}) == c
+	if !nested {
+		if err = c.guard(GuardInput, m); err != nil {
+			return nil, err
+		}
+		ctx = context.WithValue(ctx, llmGuarded{}, c)
+		defer func(since time.Time) { c.audit(ctx, vendor, m, o, since, err) }(time.Now())
+	}
+	complete := (*LLM).openAI
+	if vendor != "Responses" {
+		v, _ := llmVendorOf(c.Model)
+		complete = v.Complete
+	}
+	o, err = complete(c, ctx, api, m...)
+	if err != nil || nested {
+		return o, err
+	}
+	if err = c.guard(GuardOutput, o[min(len(m), len(o)):]); err != nil {
+		return nil, err
+	}
+	return o, nil
 }
 
 func (c *LLM) Read(message string) (string, error) {
@@ -77,6 +123,16 @@ func (c *LLM) MDTool(markdown string, fn Function) error {
 	return nil
 }
 
+// MDToolCtx is MDTool with a handler receiving context of the completion request.
+func (c *LLM) MDToolCtx(markdown string, fn FunctionCtx) error {
+	t, err := NewToolMDCtx(markdown, fn)
+	if err != nil {
+		return err
+	}
+	c.Tool = append(c.Tool, t)
+	return nil
+}
+
 func (c *LLM) Option(name string, value any) *LLM {
 	if c.Options == nil {
 		c.Options = Meta{}
@@ -119,11 +175,13 @@ func (c *LLM) gemini(ctx context.Context, api *API, m ...Message) ([]Message, er
 				},
 			})
 		case "user":
+			pts := []Meta{{"text": txt}}
+			for _, f := range m[i].Files {
+				pts = append(pts, Meta{"fileData": Meta{"fileUri": f.ID, "mimeType": f.MIME}})
+			}
 			cts = append(cts, Meta{
-				"role": "user",
-				"parts": []Meta{
-					{"text": txt},
-				},
+				"role":  "user",
+				"parts": pts,
 			})
 		}
 	}
@@ -141,9 +199,10 @@ func (c *LLM) gemini(ctx context.Context, api *API, m ...Message) ([]Message, er
 	if len(fns) > 0 {
 		tls = append(tls, Meta{"functionDeclarations": fns})
 	}
-	if _, ok := c.Options["google_search"]; ok {
+	if _, ok := c.Options["google_search"]; ok && (c.Gemini == nil || !c.Gemini.GoogleSearch) {
 		tls = append(tls, Meta{"google_search": Meta{}})
 	}
+	tls = append(tls, c.Gemini.tools()...)
 	req := Meta{}
 	if len(sys) != 0 {
 		req["system_instruction"] = Meta{"parts": sys}
@@ -154,14 +213,29 @@ func (c *LLM) gemini(ctx context.Context, api *API, m ...Message) ([]Message, er
 	if len(tls) != 0 {
 		req["tools"] = tls
 	}
-	res, err := api.
+	if ss := c.Gemini.safety(); len(ss) > 0 {
+		req["safetySettings"] = ss
+	}
+	gen := c.params("Gemini")
+	c.Gemini.config(gen)
+	if c.schema != nil {
+		gen["responseMimeType"], gen["responseJsonSchema"] = "application/json", c.schema
+	}
+	if len(gen) != 0 {
+		req["generationConfig"] = gen
+	}
+	res, cached, err := c.completion(ctx, api.
 		Endpoint("/v1beta/models/%s:generateContent", c.Model).
 		Context(ctx).
-		Cache(c.Cache, c.Name).
-		Post(req)
+		Cache(c.Cache, c.Name), req)
 	if err != nil {
 		return nil, ErrCompletion.Wrap(err)
 	}
+	u, n := c.usage("Gemini", res, cached), len(m)
+	if r := res.Text("promptFeedback.blockReason"); r != "" {
+		return nil, ErrCompletion.New("%s blocked prompt as %s", api.Name, r)
+	}
+	var thoughts string
 	for cds := range res.Each("candidates") {
 		rol := cds.Text("content.role")
 		switch rol {
@@ -172,15 +246,32 @@ func (c *LLM) gemini(ctx context.Context, api *API, m ...Message) ([]Message, er
 			fnn := p.Text("functionCall.name")
 			fna := p.Select("functionCall.args")
 			txt := p.Text("text")
+			if p.Bool("thought") {
+				thoughts += txt
+				continue
+			}
 			if m, err = c.tool(ctx, m, "", fnn, fna); err != nil {
 				return nil, err
 			}
 			if txt != "" {
-				m = append(m, Message{Role: rol, Content: txt})
+				x := Message{Role: rol, Content: txt}
+				if lp := c.logprobs("Gemini", cds); len(lp) > 0 {
+					x.Meta = Meta{"logprobs": lp}
+				}
+				if thoughts != "" {
+					if x.Meta == nil {
+						x.Meta = Meta{}
+					}
+					x.Meta["thoughts"], thoughts = thoughts, ""
+				}
+				m = append(m, x)
 			}
 		}
 	}
 
+	if len(m) > n {
+		m[n].Usage = u
+	}
 	return m, nil
 }
 
@@ -206,22 +297,45 @@ func (c *LLM) chatGPT(ctx context.Context, api *API, msg ...Message) ([]Message,
 		}
 
 		y := Meta{"role": m.Role, "content": m.Content}
+		if len(m.Files) > 0 {
+			cc := []Meta{{"type": "text", "text": m.Content}}
+			for _, f := range m.Files {
+				cc = append(cc, Meta{"type": "file", "file": Meta{"file_id": f.ID}})
+			}
+			y["content"] = cc
+		}
 		if m.Role == "function" {
 			y["role"], y["tool_call_id"] = "tool", m.ID
 		}
 		mm = append(mm, y)
 	}
-	res, err := api.Endpoint("/v1/chat/completions").Context(ctx).Cache(c.Cache, c.Name).Post(Meta{
+	req := Meta{
 		"model":       c.Model,
 		"tools":       tools,
 		"temperature": c.Temperature,
 		"messages":    mm,
-	})
+	}
+	maps.Copy(req, c.params("ChatGPT"))
+	if c.schema != nil {
+		req["response_format"] = Meta{
+			"type":        "json_schema",
+			"json_schema": Meta{"name": "reply", "schema": c.schema, "strict": llmStrict(c.schema)},
+		}
+	}
+	if b := BuildInfo(); b.Version != "" {
+		req["metadata"] = b.Meta()
+	}
+	res, cached, err := c.completion(ctx, api.Endpoint("/v1/chat/completions").Context(ctx).Cache(c.Cache, c.Name), req)
 	if err != nil {
 		return nil, ErrCompletion.Wrap(err)
 	}
+	u := c.usage("ChatGPT", res, cached)
 	if s := res.Text("choices.0.message.content"); s != "" {
-		msg = append(msg, Message{Role: res.Text("choices.0.message.role"), Content: s})
+		x := Message{Role: res.Text("choices.0.message.role"), Content: s, Usage: u}
+		if lp := c.logprobs("ChatGPT", res.Select("choices.0")); len(lp) > 0 {
+			x.Meta = Meta{"logprobs": lp}
+		}
+		msg, u = append(msg, x), nil
 	}
 
 	for fcs := range res.Each("choices.0.message.tool_calls") {
@@ -230,7 +344,7 @@ func (c *LLM) chatGPT(ctx context.Context, api *API, msg ...Message) ([]Message,
 		fna := fcs.Select("function.arguments").Meta()
 
 		fna["_method"], fna["_methodID"] = fnn, fid
-		msg = append(msg, Message{Role: "assistant", Meta: Meta{"tool_calls": fcs}})
+		msg, u = append(msg, Message{Role: "assistant", Meta: Meta{"tool_calls": fcs}, Usage: u}), nil
 		if msg, err = c.tool(ctx, msg, fid, fnn, fna.JSON()); err != nil {
 			return nil, err
 		}
@@ -239,12 +353,130 @@ func (c *LLM) chatGPT(ctx context.Context, api *API, msg ...Message) ([]Message,
 	return msg, nil
 }
 
+// claude calls Anthropic Messages API. System messages and Instruction go to
+// the system prompt, tool calls and results are exchanged as tool_use and
+// tool_result content blocks, consecutive messages of the same role are
+// merged as the API expects roles to alternate.
+func (c *LLM) claude(ctx context.Context, api *API, msg ...Message) ([]Message, error) {
+	var tools []Meta
+	for _, t := range c.Tool {
+		for _, s := range t.Schemas {
+			f := s.JSON("function")
+			tools = append(tools, Meta{
+				"name":         f.Text("name"),
+				"description":  f.Text("description"),
+				"input_schema": f.Select("parameters"),
+			})
+		}
+	}
+
+	var sys []string
+	if c.Instruction != "" {
+		sys = append(sys, c.Instruction)
+	}
+	if c.schema != nil {
+		sys = append(sys, "Reply with a JSON document only, matching this JSON Schema:\n"+c.schema.String())
+	}
+	var mm []Meta
+	add := func(role string, block Meta) {
+		if n := len(mm); n > 0 && mm[n-1]["role"] == role {
+			mm[n-1]["content"] = append(mm[n-1]["content"].([]Meta), block)
+			return
+		}
+		mm = append(mm, Meta{"role": role, "content": []Meta{block}})
+	}
+	for _, m := range msg {
+		switch {
+		case m.Role == "system":
+			sys = append(sys, m.Content)
+		case m.Role == "function":
+			add("user", Meta{"type": "tool_result", "tool_use_id": m.ID, "content": m.Content, "is_error": m.Meta.Bool("error")})
+		case m.Meta.Has("tool_use"):
+			add("assistant", Meta{
+				"type":  "tool_use",
+				"id":    m.Meta.Text("tool_use.id"),
+				"name":  m.Meta.Text("tool_use.name"),
+				"input": m.Meta.Select("tool_use.input"),
+			})
+		case m.Content != "" || len(m.Files) > 0:
+			if m.Content != "" {
+				add(m.Role, Meta{"type": "text", "text": m.Content})
+			}
+			for _, f := range m.Files {
+				t := "document"
+				if strings.HasPrefix(f.MIME, "image/") {
+					t = "image"
+				}
+				add(m.Role, Meta{"type": t, "source": Meta{"type": "file", "file_id": f.ID}})
+			}
+		}
+	}
+	req := Meta{
+		"model":      c.Model,
+		"max_tokens": 4096,
+		"messages":   mm,
+	}
+	if c.Temperature != 0 {
+		req["temperature"] = c.Temperature
+	}
+	if n, ok := c.Options["max_tokens"]; ok {
+		req["max_tokens"] = n
+	}
+	maps.Copy(req, c.params("Claude"))
+	if len(sys) > 0 {
+		req["system"] = strings.Join(sys, "\n\n")
+	}
+	if len(tools) > 0 {
+		req["tools"] = tools
+	}
+	if _, ok := api.Headers["Anthropic-Version"]; !ok {
+		api.Header("anthropic-version", "2023-06-01")
+	}
+	for _, m := range msg {
+		if _, ok := api.Headers["Anthropic-Beta"]; !ok && len(m.Files) > 0 {
+			api.Header("anthropic-beta", "files-api-2025-04-14")
+		}
+	}
+	res, cached, err := c.completion(ctx, api.Endpoint("/v1/messages").Context(ctx).Cache(c.Cache, c.Name), req)
+	if err != nil {
+		return nil, ErrCompletion.Wrap(err)
+	}
+	u := c.usage("Claude", res, cached)
+	var calls []JSON
+	for b := range res.Each("content") {
+		switch b.Text("type") {
+		case "text":
+			if s := b.Text("text"); s != "" {
+				msg, u = append(msg, Message{Role: "assistant", Content: s, Usage: u}), nil
+			}
+		case "tool_use":
+			msg, u = append(msg, Message{Role: "assistant", Meta: Meta{"tool_use": b}, Usage: u}), nil
+			calls = append(calls, b)
+		}
+	}
+	// every tool_use must be answered with tool_result in the next user turn,
+	// so all tools run before Claude is called again
+	var dispatch bool
+	for _, b := range calls {
+		var d bool
+		if msg, d, err = c.call(ctx, msg, b.Text("id"), b.Text("name"), b.Select("input")); err != nil {
+			return nil, err
+		}
+		dispatch = dispatch || d
+	}
+	if dispatch {
+		return c.Response(ctx, msg...)
+	}
+	return msg, nil
+}
+
 func (c *LLM) api() (*API, string, error) {
-	vendor := "ChatGPT"
-	if strings.HasPrefix(c.Model, "gemini") {
-		vendor = "Gemini"
+	v, ok := llmVendorOf(c.Model)
+	if !ok {
+		return nil, "", Errorf("no vendor serves %q model", c.Model)
 	}
-	api, err := NewAPI(fmt.Sprintf("%s_URL", strings.ToTitle(vendor)))
+	vendor := v.Name
+	api, err := NewAPI(v.Env)
 	if err != nil {
 		return nil, vendor, err
 	}
@@ -255,26 +487,52 @@ func (c *LLM) api() (*API, string, error) {
 	if n := api.URL.Query("temperature"); n != "" && c.Temperature == 0 {
 		c.Temperature, _ = strconv.ParseFloat(n, 32)
 	}
-	api.Name = vendor
+	api.Name, api.Errors = vendor, llmErrors
 	return api, vendor, nil
 }
 
 func (c *LLM) tool(ctx context.Context, msg []Message, id, name string, data JSON) ([]Message, error) {
-	if name == "" {
-		return msg, nil
+	msg, dispatch, err := c.call(ctx, msg, id, name, data)
+	// If a tool responds and the result is dispatchable, call the LLM again.
+	if err != nil || !dispatch {
+		return msg, err
+	}
+	return c.Response(ctx, msg...)
+}
+
+// call runs tools of the name and appends their results to msg, true when
+// any result is dispatchable, so the LLM should be called again.
+func (c *LLM) call(ctx context.Context, msg []Message, id, name string, data JSON) ([]Message, bool, error) {
+	if name == "" || agentStopped(ctx, c) {
+		return msg, false, nil
 	}
+	var dispatched bool
 	for i := range c.Tool {
 		if !c.Tool[i].HasName(name) {
 			continue
 		}
-		res, dispatch := c.Tool[i].Execute(data)
-		msg = append(msg, Message{ID: id, Name: name, Role: "function", Content: res})
-		// If a tool responds and the result is dispatchable, call the LLM again.
-		if dispatch {
-			return c.Response(ctx, msg...)
+		now := time.Now()
+		res, dispatch, err := c.Tool[i].run(ctx, data)
+		if err == nil && c.injection != nil {
+			res, err = c.injection.check(ctx, name, res)
+		}
+		m := Message{ID: id, Name: name, Role: "function", Content: res}
+		if err != nil {
+			log_.Errorf("Tool %s failed %s", name, err)
+			m.Content, m.Meta, dispatch = string(Meta{"error": err.Error()}.JSON()), Meta{"error": true}, true
 		}
+		msg = append(msg, m)
+		// Agent run records the call and may stop the loop.
+		if r, ok := agentOf(ctx, c); ok {
+			next, err := r.step(msg, m, data, time.Since(now))
+			if err != nil {
+				return nil, false, err
+			}
+			dispatch = dispatch && next
+		}
+		dispatched = dispatched || dispatch
 	}
-	return msg, nil
+	return msg, dispatched, nil
 }
 
 type Message struct {
@@ -283,6 +541,10 @@ type Message struct {
 	Role    string `json:"role"`
 	Content string `json:"content"`
 	Meta    Meta   `json:"meta"`
+	// Usage of completion call which returned the message.
+	Usage *Usage `json:"usage,omitempty"`
+	// Files uploaded with LLM.Upload sent along with user message content.
+	Files []LLMFile `json:"files,omitempty"`
 }
 
 var (
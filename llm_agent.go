@@ -0,0 +1,160 @@
+package ion
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Agent runs LLM with tools in a bounded loop, where the model calls tools
+// and reads their results until it answers. Unlike plain LLM.Response the
+// loop stops with ErrAgent when iterations, tokens or cost exceed limits,
+// and every tool call is recorded in Trace.
+//
+// Example:
+//
+//	a := ion.NewAgent(&ion.LLM{Model: "gpt-4o"}, search, fetch)
+//	a.MaxCost = 0.5
+//	a.Stop = func(s ion.AgentStep) bool { return s.Tool == "final_answer" }
+//	m, err := a.Run(ctx, ion.Message{Role: "user", Content: task})
+type Agent struct {
+	LLM *LLM
+	// MaxIterations of the model reading tool results, 10 when zero.
+	MaxIterations int
+	// MaxTokens of all completions of a run, zero is unlimited.
+	MaxTokens int
+	// MaxCost of all completions of a run, zero is unlimited, see UseLLMPrices.
+	MaxCost float64
+	// Stop ends the run after the step without asking the model again.
+	Stop func(AgentStep) bool
+	// Trace of tool calls of the last run.
+	Trace []AgentStep
+}
+
+// AgentStep is a tool call made by the model. Thought is the text the model
+// wrote along with the call and Usage is the total of the run so far.
+type AgentStep struct {
+	Thought  string        `json:"thought,omitempty"`
+	Tool     string        `json:"tool"`
+	Input    JSON          `json:"input"`
+	Output   string        `json:"output"`
+	Error    bool          `json:"error,omitempty"`
+	Usage    Usage         `json:"usage"`
+	Duration time.Duration `json:"duration"`
+}
+
+// NewAgent creates Agent of a copy of l with tools added to ones l already
+// has, l is left unchanged.
+func NewAgent(l *LLM, t ...Tool) *Agent {
+	c := *l
+	c.Tool = append(slices.Clone(l.Tool), t...)
+	return &Agent{LLM: &c}
+}
+
+// Run completes messages, returning them with replies and tool calls of the
+// run. When a limit is exceeded messages exchanged so far are returned with
+// ErrAgent.
+func (a *Agent) Run(ctx context.Context, m ...Message) ([]Message, error) {
+	r := &agentRun{agent: a, start: len(m)}
+	a.Trace = nil
+	o, err := a.LLM.Response(context.WithValue(ctx, agentRunKey{}, r), m...)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err != nil {
+		if r.err != nil {
+			return r.msg, r.err
+		}
+		return nil, err
+	}
+	return o, r.limits(o)
+}
+
+// agentRun is a state of Agent.Run, passed to LLM.tool in context.
+type agentRun struct {
+	mu         sync.Mutex
+	agent      *Agent
+	start      int
+	iterations int
+	stopped    bool
+	msg        []Message
+	err        error
+}
+
+type agentRunKey struct{}
+
+// step records tool call result m made with msg, and tells whether the
+// model can be asked again, false when the run is stopped.
+func (r *agentRun) step(msg []Message, m Message, in JSON, took time.Duration) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	s := AgentStep{Tool: m.Name, Input: in, Output: m.Content, Error: m.Meta.Bool("error"), Usage: r.usage(msg), Duration: took}
+	for i := len(msg) - 2; i >= r.start && msg[i].Role == "assistant"; i-- {
+		if msg[i].Content != "" {
+			s.Thought = msg[i].Content
+			break
+		}
+	}
+	a := r.agent
+	a.Trace = append(a.Trace, s)
+	r.msg = msg
+	Metrics.Count("llm_agent_steps_total{name=%q,tool=%q}", 1, a.LLM.Name, s.Tool)
+	if a.Stop != nil && a.Stop(s) {
+		r.stopped = true
+		return false, nil
+	}
+	n := a.MaxIterations
+	if n == 0 {
+		n = 10
+	}
+	if r.iterations++; r.iterations > n {
+		r.err = ErrAgent.New("%d iterations limit exceeded", n)
+	} else {
+		r.err = r.limits(msg)
+	}
+	return r.err == nil, r.err
+}
+
+// limits checks token and cost budgets against usage of msg.
+func (r *agentRun) limits(msg []Message) error {
+	u, a := r.usage(msg), r.agent
+	switch {
+	case a.MaxTokens > 0 && u.Input+u.Output > a.MaxTokens:
+		return ErrAgent.New("%d tokens used, %d limit exceeded", u.Input+u.Output, a.MaxTokens)
+	case a.MaxCost > 0 && u.Cost > a.MaxCost:
+		return ErrAgent.New("%.4f cost, %.4f limit exceeded", u.Cost, a.MaxCost)
+	}
+	return nil
+}
+
+// usage sums usage of messages of the run, input ones excluded.
+func (r *agentRun) usage(msg []Message) Usage {
+	var u Usage
+	for _, x := range msg[min(r.start, len(msg)):] {
+		if x.Usage != nil {
+			u = u.Add(*x.Usage)
+		}
+	}
+	return u
+}
+
+// agentOf returns the agent run of ctx, when c is its LLM, so LLM called
+// by a tool of the agent is not taken for it.
+func agentOf(ctx context.Context, c *LLM) (*agentRun, bool) {
+	r, ok := ctx.Value(agentRunKey{}).(*agentRun)
+	return r, ok && r.agent.LLM == c
+}
+
+// agentStopped reports whether the agent run of ctx, with c as its LLM, was
+// stopped.
+func agentStopped(ctx context.Context, c *LLM) bool {
+	r, ok := agentOf(ctx, c)
+	if !ok {
+		return false
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.stopped
+}
+
+var ErrAgent = ErrAI.New("agent")
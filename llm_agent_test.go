@@ -0,0 +1,103 @@
+package ion_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/sokool/ion"
+)
+
+func TestAgent_Limits(t *testing.T) {
+	ion.UseLLMPrices(map[string]ion.LLMPrice{"claude-agent": {Input: 10_000, Output: 10_000}})
+	defer ion.UseLLMPrices(nil)
+	var calls int
+	// the model never answers, it calls the tool again and again
+	ion.Endpoints.Handler(func(r *http.Request, w *httptest.ResponseRecorder) {
+		calls++
+		_ = json.NewEncoder(w).Encode(ion.Meta{
+			"content": []ion.Meta{
+				{"type": "text", "text": "looking up"},
+				{"type": "tool_use", "id": fmt.Sprintf("t%d", calls), "name": "order", "input": ion.Meta{"number": "A1"}},
+			},
+			"usage": ion.Meta{"input_tokens": 100, "output_tokens": 50},
+		})
+	}, "ANTHROPIC_URL")
+	type test struct {
+		name  string
+		agent ion.Agent
+		err   bool
+		calls int
+	}
+	for _, c := range []test{
+		{name: "iterations", agent: ion.Agent{MaxIterations: 2}, err: true, calls: 3},
+		{name: "tokens", agent: ion.Agent{MaxTokens: 400}, err: true, calls: 3},
+		{name: "cost", agent: ion.Agent{MaxCost: 2}, err: true, calls: 2},
+		{name: "stop", agent: ion.Agent{Stop: func(s ion.AgentStep) bool { return s.Tool == "order" }}, calls: 1},
+	} {
+		calls = 0
+		tl, err := ion.NewTool("order", "finds order", func(q OrderQuery) (string, bool) { return q.Number, true })
+		if err != nil {
+			t.Fatal(err)
+		}
+		a := c.agent
+		a.LLM = &ion.LLM{Model: "claude-agent", Tool: []ion.Tool{tl}}
+		mm, err := a.Run(context.Background(), ion.Message{Role: "user", Content: "find order A1"})
+		if c.err != errors.Is(err, ion.ErrAgent) || calls != c.calls {
+			t.Fatalf("%s: expected %d calls and limit error %t, got %d calls %v", c.name, c.calls, c.err, calls, err)
+		}
+		if len(a.Trace) != c.calls || a.Trace[0].Thought != "looking up" || a.Trace[0].Output != "A1" {
+			t.Fatalf("%s: expected %d steps traced, got %+v", c.name, c.calls, a.Trace)
+		}
+		if len(mm) == 0 || mm[len(mm)-1].Role != "function" {
+			t.Fatalf("%s: expected messages exchanged so far, got %v", c.name, mm)
+		}
+	}
+}
+
+func TestAgent_NestedLLM(t *testing.T) {
+	calls := map[string]int{}
+	ion.Endpoints.Handler(func(r *http.Request, w *httptest.ResponseRecorder) {
+		b, _ := io.ReadAll(r.Body)
+		model := ion.JSON(b).Text("model")
+		switch calls[model]++; {
+		case calls[model] == 1 && model == "claude-agent":
+			_ = json.NewEncoder(w).Encode(ion.Meta{"content": []ion.Meta{{"type": "tool_use", "id": "a1", "name": "ask", "input": ion.Meta{"number": "A1"}}}})
+		case calls[model] == 1:
+			_ = json.NewEncoder(w).Encode(ion.Meta{"content": []ion.Meta{{"type": "tool_use", "id": "o1", "name": "order", "input": ion.Meta{"number": "A1"}}}})
+		default:
+			_ = json.NewEncoder(w).Encode(ion.Meta{"content": []ion.Meta{{"type": "text", "text": "A1 shipped"}}})
+		}
+	}, "ANTHROPIC_URL")
+	order, err := ion.NewTool("order", "finds order", func(q OrderQuery) (string, bool) { return q.Number, true })
+	if err != nil {
+		t.Fatal(err)
+	}
+	inner := &ion.LLM{Model: "claude-inner", Tool: []ion.Tool{order}}
+	ask, err := ion.NewToolCtx("ask", "asks about order", func(ctx context.Context, q OrderQuery) (string, bool, error) {
+		mm, err := inner.Response(ctx, ion.Message{Role: "user", Content: q.Number})
+		if err != nil {
+			return "", false, err
+		}
+		return mm[len(mm)-1].Content, true, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	l := &ion.LLM{Model: "claude-agent"}
+	a := ion.NewAgent(l, ask)
+	if _, err = a.Run(context.Background(), ion.Message{Role: "user", Content: "where is A1"}); err != nil {
+		t.Fatal(err)
+	}
+	if len(l.Tool) != 0 {
+		t.Fatalf("expected tools of given LLM unchanged, got %d", len(l.Tool))
+	}
+	if len(a.Trace) != 1 || a.Trace[0].Tool != "ask" || a.Trace[0].Output != "A1 shipped" {
+		t.Fatalf("expected only agent tool call traced, got %+v", a.Trace)
+	}
+}
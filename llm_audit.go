@@ -0,0 +1,125 @@
+package ion
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+)
+
+// LLMAudit is a record of a single LLM.Response call, messages sent and
+// returned have their content redacted with Redaction and Text.RedactPII.
+// Error is set when the call failed, then Response is empty.
+type LLMAudit struct {
+	ID       string        `json:"id"`
+	Time     time.Time     `json:"time"`
+	Name     string        `json:"name,omitempty"`
+	Vendor   string        `json:"vendor"`
+	Model    string        `json:"model"`
+	Request  []Message     `json:"request"`
+	Response []Message     `json:"response,omitempty"`
+	Usage    Usage         `json:"usage"`
+	Latency  time.Duration `json:"latency"`
+	Error    string        `json:"error,omitempty"`
+}
+
+// AuditSink stores LLMAudit records, see AuditStore, AuditSQL and AuditTopic.
+type AuditSink func(context.Context, LLMAudit) error
+
+// UseLLMAudit enables auditing of every completion, records are written to
+// all given sinks once the call is done, sink errors are logged only. Calling
+// it without sinks disables auditing.
+//
+// Example:
+//
+//	ion.UseLLMAudit(
+//		ion.AuditStore("audit:llm", 90*24*time.Hour),
+//		ion.AuditTopic(ion.MustTopic[ion.LLMAudit](ctx, "llm-audit")),
+//	)
+func UseLLMAudit(sinks ...AuditSink) {
+	llmAuditMu.Lock()
+	defer llmAuditMu.Unlock()
+	llmAudits = sinks
+}
+
+// AuditStore keeps records in Store under prefix:id keys.
+func AuditStore(prefix string, ttl ...time.Duration) AuditSink {
+	return func(ctx context.Context, a LLMAudit) error {
+		if Set(WithCache(ctx), prefix+":"+a.ID, a, ttl...) < 0 {
+			return ErrAudit.New("%s record not stored", a.ID)
+		}
+		return nil
+	}
+}
+
+// AuditSQL inserts records with given query, which variables are LLMAudit fields.
+func AuditSQL(s SQL[LLMAudit]) AuditSink {
+	return func(ctx context.Context, a LLMAudit) error {
+		return s.Write(ctx, a)
+	}
+}
+
+// AuditTopic publishes records on the topic.
+func AuditTopic(t *Topic[LLMAudit]) AuditSink {
+	return func(_ context.Context, a LLMAudit) error {
+		return t.Write(a)
+	}
+}
+
+// audit writes a record of the completion to registered sinks, out holds
+// messages returned by the vendor, the request ones included.
+func (c *LLM) audit(ctx context.Context, vendor string, in, out []Message, since time.Time, err error) {
+	llmAuditMu.RLock()
+	sinks := llmAudits
+	llmAuditMu.RUnlock()
+	if len(sinks) == 0 {
+		return
+	}
+	a := LLMAudit{
+		ID:      UUID(),
+		Time:    since,
+		Name:    c.Name,
+		Vendor:  vendor,
+		Model:   c.Model,
+		Request: llmRedact(in),
+		Usage:   Usage{Model: c.Model},
+		Latency: time.Since(since),
+	}
+	if err != nil {
+		a.Error = Redaction().Text(err.Error())
+	} else {
+		a.Response = llmRedact(out[min(len(in), len(out)):])
+	}
+	for _, m := range a.Response {
+		if m.Usage != nil {
+			a.Usage = a.Usage.Add(*m.Usage)
+		}
+	}
+	for _, s := range sinks {
+		if er := s(ctx, a); er != nil {
+			log_.Errorf("LLM %s audit %s failed: %s", vendor, a.ID, er)
+		}
+	}
+}
+
+// llmRedact returns copies of messages with content and meta redacted.
+func llmRedact(mm []Message) []Message {
+	r, o := Redaction(), make([]Message, len(mm))
+	for i, m := range mm {
+		m.Content = string(Text(r.Text(m.Content)).RedactPII())
+		if len(m.Meta) > 0 {
+			var x Meta
+			if json.Unmarshal(r.JSON(m.Meta.JSON()), &x) == nil {
+				m.Meta = x
+			}
+		}
+		o[i] = m
+	}
+	return o
+}
+
+var (
+	llmAuditMu sync.RWMutex
+	llmAudits  []AuditSink
+	ErrAudit   = ErrAI.New("audit")
+)
@@ -5,6 +5,7 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"slices"
 	"strings"
 )
 
@@ -18,6 +19,21 @@ type LLMChat struct {
 	Muted       bool
 	Persistent  bool
 	Meta        Meta
+	// Budget of history tokens, when exceeded older messages are summarized
+	// into a system message and archived, zero disables it.
+	Budget int
+	// Summarizer writes summaries of compacted history, a cheap model is
+	// enough, Completion is used when nil.
+	Summarizer *LLM `json:"-"`
+	// History trims messages sent with each completion, all are sent when nil.
+	History HistoryPolicy `json:"-"`
+	// Examples are user and assistant turns put after system messages of
+	// a new chat, showing the model expected replies.
+	Examples []Message `json:",omitempty"`
+
+	topic   *Topic[Message]
+	vectors VectorStore
+	k       int
 }
 
 func ReadLLMChat(id, name string) (*LLMChat, error) {
@@ -52,11 +68,11 @@ func (c *LLMChat) Complete(ctx context.Context, m ...Message) ([]Message, error)
 			return nil, ErrChat.Wrap(err)
 		}
 		if len(c.Messages) == 0 {
-			c.Uncommitted = append([]Message{
+			c.Uncommitted = append(append([]Message{
 				{Role: "system", Content: "chatID: " + c.ID},
 				{Role: "system", Content: "chat initial data" + c.Meta.String()},
 				{Role: "system", Content: c.Prompt},
-			}, c.Uncommitted...)
+			}, c.Examples...), c.Uncommitted...)
 		}
 	}
 	if len(c.Messages) > 0 {
@@ -65,22 +81,68 @@ func (c *LLMChat) Complete(ctx context.Context, m ...Message) ([]Message, error)
 	if len(m) > 0 {
 		c.Uncommitted = append(c.Uncommitted, m...)
 	}
+	c.publish(c.Uncommitted[len(c.Messages):])
 
 	if c.Muted {
 		c.Messages = c.Uncommitted
 		return nil, c.store()
 	}
-	o, err := c.Completion.Response(ctx, c.Uncommitted...)
+	if err := c.compact(ctx); err != nil {
+		return nil, err
+	}
+	in := c.Uncommitted
+	if c.History != nil {
+		in = c.History(slices.Clone(in), c.Completion.Model)
+	}
+	in, err := c.retrieve(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	o, err := c.Completion.Response(ctx, in...)
 	if err != nil {
 		return nil, err
 	}
-	c.Messages = o
+	o = o[min(len(in), len(o)):]
+	for _, m := range o {
+		if m.Usage != nil {
+			Metrics.Count("llm_chat_tokens_total{chat=%q}", m.Usage.Input+m.Usage.Output, c.Name)
+		}
+	}
+	c.Messages = append(c.Uncommitted, o...)
 	if err := c.store(); err != nil {
 		return nil, ErrChat.Wrap(err)
 	}
+	c.publish(o)
 	return c.Messages, nil
 }
 
+// Publish writes every new message of the chat to the topic, user ones
+// before the completion and replies, tool calls and results included, after
+// it, so websocket frontends and background listeners can follow the chat
+// without polling the Store. Nil topic stops publishing.
+//
+// Example:
+//
+//	c := ion.NewLLMChat(prompt).Publish(ion.MustTopic[ion.Message](ctx, "chat/%s", id))
+func (c *LLMChat) Publish(t *Topic[Message]) *LLMChat {
+	c.topic = t
+	return c
+}
+
+// publish writes messages to the chat topic, failures are logged only so
+// listeners never break the chat.
+func (c *LLMChat) publish(mm []Message) {
+	if c.topic == nil {
+		return
+	}
+	for _, m := range mm {
+		if err := c.topic.Write(m); err != nil {
+			log_.Debugf("chat %s publish to %s failed: %s", c.ID, c.topic.Name, err)
+			return
+		}
+	}
+}
+
 func (c *LLMChat) Read(message string) (string, error) {
 	var m []Message
 	if message != "" {
@@ -0,0 +1,85 @@
+package ion
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// compact summarizes older messages when history exceeds Budget tokens.
+// Leading system messages stay, except a summary of previous compaction, as
+// do the last user message and all after it, even over the budget, and
+// earlier ones which fit into half of the budget, starting at a user
+// message, so tool calls are never separated from their results. Messages in between are replaced by a
+// single system message with their summary and archived in the Store under
+// the chat key with :archive suffix.
+func (c *LLMChat) compact(ctx context.Context) error {
+	mm := c.Uncommitted
+	if c.Budget <= 0 || llmTokens(mm, c.Completion.Model) <= c.Budget {
+		return nil
+	}
+	from := 0
+	for from < len(mm) && mm[from].Role == "system" && !strings.HasPrefix(mm[from].Content, llmSummary) {
+		from++
+	}
+	to := len(mm) - 1
+	for to >= from && mm[to].Role != "user" {
+		to--
+	}
+	if to < from {
+		return nil
+	}
+	n := llmTokens(mm[to:], c.Completion.Model)
+	for i := to - 1; i >= from; i-- {
+		if n += llmTokens(mm[i:i+1], c.Completion.Model); n > c.Budget/2 {
+			break
+		}
+		if mm[i].Role == "user" {
+			to = i
+		}
+	}
+	if to-from < 2 {
+		return nil
+	}
+	s, err := c.summarize(ctx, mm[from:to])
+	if err != nil {
+		return ErrChat.New("compaction failed %w", err)
+	}
+	if c.Persistent {
+		var a []Message
+		cx := WithCache(ctx)
+		if Get(cx, c.key()+":archive", &a) < 0 || Set(cx, c.key()+":archive", append(a, mm[from:to]...)) < 0 {
+			return ErrChat.New("compaction archive failed")
+		}
+	}
+	Metrics.Count("llm_chat_compactions_total{chat=%q}", 1, c.Name)
+	c.Uncommitted = append(append(mm[:from:from], Message{Role: "system", Content: llmSummary + s}), mm[to:]...)
+	return nil
+}
+
+// summarize asks Summarizer, or Completion without tools when not set, for
+// a summary of messages.
+func (c *LLMChat) summarize(ctx context.Context, mm []Message) (string, error) {
+	l := c.Completion
+	if c.Summarizer != nil {
+		l = *c.Summarizer
+	}
+	l.Tool, l.Instruction = nil, "Summarize the conversation below for its continuation. "+
+		"Keep facts, decisions, names, numbers and open questions, skip small talk. Reply with the summary only."
+	var b strings.Builder
+	for _, m := range mm {
+		if m.Content != "" {
+			fmt.Fprintf(&b, "%s: %s\n", m.Role, m.Content)
+		}
+	}
+	o, err := l.Response(ctx, Message{Role: "user", Content: b.String()})
+	if err != nil {
+		return "", err
+	}
+	if len(o) == 0 || o[len(o)-1].Role != "assistant" {
+		return "", ErrChat.New("no summary")
+	}
+	return o[len(o)-1].Content, nil
+}
+
+const llmSummary = "Summary of earlier conversation:\n"
@@ -0,0 +1,101 @@
+package ion
+
+import "slices"
+
+// HistoryPolicy trims chat messages sent with each completion to fit the
+// context window of the model. Leading system messages are always kept and
+// tool results are never separated from their calls. The chat keeps its
+// full history, only the request is trimmed.
+//
+// Example:
+//
+//	c := ion.NewLLMChat(prompt)
+//	c.History = ion.DropToolResults(50_000)
+type HistoryPolicy func(mm []Message, model string) []Message
+
+// KeepLast keeps system messages and the last n other ones.
+func KeepLast(n int) HistoryPolicy {
+	return func(mm []Message, _ string) []Message {
+		s := llmSystem(mm)
+		return llmWindow(mm, s, max(s, len(mm)-n))
+	}
+}
+
+// KeepTokens keeps system messages and as many of the most recent ones as
+// fit into budget tokens, at least the last one.
+func KeepTokens(budget int) HistoryPolicy {
+	return func(mm []Message, model string) []Message {
+		s := llmSystem(mm)
+		n, from := llmTokens(mm[:s], model), len(mm)
+		for from > s {
+			if n += llmTokens(mm[from-1:from], model); n > budget && from < len(mm) {
+				break
+			}
+			from--
+		}
+		return llmWindow(mm, s, from)
+	}
+}
+
+// DropToolResults replaces contents of tool results, oldest first, with a
+// short note until messages fit into budget tokens, as results tend to be
+// the largest and least needed later. When it is not enough, KeepTokens
+// trims the rest.
+func DropToolResults(budget int) HistoryPolicy {
+	return func(mm []Message, model string) []Message {
+		mm = slices.Clone(mm)
+		n := llmTokens(mm, model)
+		for i := 0; i < len(mm)-1 && n > budget; i++ {
+			if mm[i].Role != "function" || mm[i].Content == llmDropped {
+				continue
+			}
+			n -= llmTokens(mm[i:i+1], model)
+			mm[i].Content = llmDropped
+			n += llmTokens(mm[i:i+1], model)
+		}
+		if n <= budget {
+			return mm
+		}
+		return KeepTokens(budget)(mm, model)
+	}
+}
+
+// llmSystem returns number of leading system messages.
+func llmSystem(mm []Message) int {
+	n := 0
+	for n < len(mm) && mm[n].Role == "system" {
+		n++
+	}
+	return n
+}
+
+// llmWindow returns leading s system messages followed by messages since
+// from, moved forward past tool results which calls were cut off, or back to
+// their call when nothing but them is left.
+func llmWindow(mm []Message, s, from int) []Message {
+	i := from
+	for i < len(mm) && mm[i].Role == "function" {
+		i++
+	}
+	switch {
+	case i < len(mm) || from == len(mm):
+		from = i
+	case from > s:
+		for from > s && mm[from-1].Role == "function" {
+			from--
+		}
+		from = max(from-1, s)
+	}
+	return append(mm[:s:s], mm[from:]...)
+}
+
+// llmTokens estimates tokens of messages, with 4 tokens of role and separators each.
+func llmTokens(mm []Message, model string) int {
+	var n int
+	for _, m := range mm {
+		n += Text(m.Content).Tokens(model) + 4
+	}
+	return n
+}
+
+const llmDropped = "[result removed to save context]"
@@ -0,0 +1,63 @@
+package ion_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/sokool/ion"
+)
+
+func TestHistoryPolicy(t *testing.T) {
+	long := strings.Repeat("order A1 shipped to Warsaw ", 200)
+	mm := []ion.Message{
+		{Role: "system", Content: "sys"},
+		{Role: "user", Content: "u1"},
+		{Role: "assistant", Content: "a1", ID: "t1"},
+		{Role: "function", Content: long, ID: "t1"},
+		{Role: "assistant", Content: "a2"},
+		{Role: "user", Content: "u2"},
+	}
+	called := mm[:4] // ends with tool result
+	var budget int
+	for _, m := range mm {
+		if m.Content != long {
+			budget += ion.Text(m.Content).Tokens("") + 4
+		}
+	}
+	budget += 50 // fits note of dropped result
+	type test struct {
+		name   string
+		policy ion.HistoryPolicy
+		mm     []ion.Message
+		want   string
+	}
+	for _, c := range []test{
+		{name: "keep all", policy: ion.KeepLast(10), mm: mm, want: "sys u1 a1 * a2 u2"},
+		{name: "keep last orphan result", policy: ion.KeepLast(3), mm: mm, want: "sys a2 u2"},
+		{name: "keep last one", policy: ion.KeepLast(1), mm: mm, want: "sys u2"},
+		{name: "keep last result with call", policy: ion.KeepLast(1), mm: called, want: "sys a1 *"},
+		{name: "tokens of last one", policy: ion.KeepTokens(1), mm: mm, want: "sys u2"},
+		{name: "tokens of all", policy: ion.KeepTokens(100_000), mm: mm, want: "sys u1 a1 * a2 u2"},
+		{name: "tokens orphan result", policy: ion.KeepTokens(budget - 50), mm: mm, want: "sys a2 u2"},
+		{name: "drop results", policy: ion.DropToolResults(budget), mm: mm, want: "sys u1 a1 - a2 u2"},
+		{name: "drop results and trim", policy: ion.DropToolResults(1), mm: mm, want: "sys u2"},
+	} {
+		var got []string
+		for _, m := range c.policy(c.mm, "") {
+			switch {
+			case m.Content == long:
+				got = append(got, "*")
+			case m.Role == "function":
+				got = append(got, "-")
+			default:
+				got = append(got, m.Content)
+			}
+		}
+		if s := strings.Join(got, " "); s != c.want {
+			t.Fatalf("%s: expected %q, got %q", c.name, c.want, s)
+		}
+	}
+	if mm[3].Content != long {
+		t.Fatal("expected chat history unchanged")
+	}
+}
@@ -0,0 +1,94 @@
+package ion_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/sokool/ion"
+)
+
+func TestLLMChat_Publish(t *testing.T) {
+	var ps recorder
+	ion.UsePubSub("record", &ps)
+	c := ion.NewLLMChat("be brief").Publish(ion.MustTopic[ion.Message](context.Background(), "record://chat"))
+	c.Muted = true
+	if _, err := c.Complete(context.Background(), ion.Message{Role: "user", Content: "hi"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Complete(context.Background(), ion.Message{Role: "user", Content: "bye"}); err != nil {
+		t.Fatal(err)
+	}
+	var rr []string
+	for _, b := range ps {
+		var m ion.Message
+		if err := json.Unmarshal(b, &m); err != nil {
+			t.Fatal(err)
+		}
+		rr = append(rr, m.Role+":"+m.Content)
+	}
+	if len(rr) != 5 || rr[2] != "system:be brief" || rr[3] != "user:hi" || rr[4] != "user:bye" {
+		t.Fatalf("unexpected messages %v", rr)
+	}
+}
+
+type recorder [][]byte
+
+func (r *recorder) Publish(_ context.Context, _ ion.URL, msg []byte) error {
+	*r = append(*r, msg)
+	return nil
+}
+
+func (r *recorder) Subscribe(context.Context, ion.URL) (<-chan []byte, error) {
+	return nil, nil
+}
+
+func TestLLMChat_Compact(t *testing.T) {
+	var last ion.JSON
+	var summaries int
+	ion.Endpoints.Handler(func(r *http.Request, w *httptest.ResponseRecorder) {
+		b, _ := io.ReadAll(r.Body)
+		reply := "ok"
+		if strings.Contains(string(b), "Summarize the conversation") {
+			summaries, reply = summaries+1, "they talked"
+		} else {
+			last = ion.JSON(b)
+		}
+		_ = json.NewEncoder(w).Encode(ion.Meta{"content": []ion.Meta{{"type": "text", "text": reply}}})
+	}, "ANTHROPIC_URL")
+	long := strings.Repeat("lorem ipsum dolor sit amet ", 40)
+	type test struct {
+		name     string
+		question string
+	}
+	for _, c := range []test{
+		{name: "short question", question: "what now?"},
+		{name: "question over budget", question: "what now? " + strings.Repeat("more details ", 400)},
+	} {
+		last, summaries = nil, 0
+		ch := ion.NewLLMChat("be brief")
+		ch.Completion.Model, ch.Budget = "claude-compact", 300
+		ch.Messages = []ion.Message{{Role: "system", Content: "be brief"}}
+		for range 6 {
+			ch.Messages = append(ch.Messages, ion.Message{Role: "user", Content: long}, ion.Message{Role: "assistant", Content: long})
+		}
+		if _, err := ch.Complete(context.Background(), ion.Message{Role: "user", Content: c.question}); err != nil {
+			t.Fatal(err)
+		}
+		var mm []ion.JSON
+		for m := range last.Each("messages") {
+			mm = append(mm, m)
+		}
+		// history is summarized, the question is always sent
+		if summaries != 1 || len(mm) == 0 || mm[len(mm)-1].Text("role") != "user" || !strings.HasPrefix(mm[len(mm)-1].Text("content.0.text"), "what now?") {
+			t.Fatalf("%s: expected question sent after %d summaries, got %s", c.name, summaries, last)
+		}
+		if !strings.Contains(string(last), "they talked") || strings.Count(string(last), "lorem") >= 40*12 {
+			t.Fatalf("%s: expected compacted history, got %.300s", c.name, last)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package ion
+
+import (
+	"context"
+	"math"
+	"slices"
+)
+
+// Semantic returns meaning-based similarity between t and to in [0..1],
+// as cosine of their LLM embeddings, so "Main St. 5, NYC" and
+// "5 Main Street, New York" score high where lexical metrics fail.
+// Embeddings of l, default LLM when nil, are kept in the Store, comparing
+// the same texts again costs no API call.
+//
+// Example:
+//
+//	s, err := ion.Text("Main St. 5, NYC").Semantic(ctx, nil, "5 Main Street, New York")
+func (t Text) Semantic(ctx context.Context, l *LLM, to string) (float64, error) {
+	if l == nil {
+		l = &LLM{}
+	}
+	vv, err := l.Embed(ctx, string(t), to)
+	if err != nil {
+		return 0, err
+	}
+	return max(0, textCosine(vv[0], vv[1])), nil
+}
+
+// Embed returns embeddings of texts in order, as the foundation of semantic
+// search and RAG. Texts embedded before are read from the Store, others are
+// sent in batches the vendor accepts, 2048 for ChatGPT and 100 for Gemini.
+// Model is taken from the embedding option, the embedding query parameter
+// of vendor URL, or text-embedding-3-small and text-embedding-004 by default.
+// Claude has no embedding API, its models return ErrEmbedding.
+//
+// Example:
+//
+//	vv, err := (&ion.LLM{}).Option("embedding", "text-embedding-3-large").Embed(ctx, docs...)
+func (c *LLM) Embed(ctx context.Context, texts ...string) ([][]float32, error) {
+	api, vendor, err := c.api()
+	if err != nil {
+		return nil, ErrEmbedding.Wrap(err)
+	}
+	if vendor == "Claude" {
+		return nil, ErrEmbedding.New("%s has no embedding API, use ChatGPT or Gemini model", vendor)
+	}
+	model, _ := c.Options["embedding"].(string)
+	if model == "" {
+		model = api.URL.Query("embedding")
+	}
+	if model == "" {
+		model = "text-embedding-3-small"
+		if vendor == "Gemini" {
+			model = "text-embedding-004"
+		}
+	}
+	vv, miss := make([][]float32, len(texts)), []int{}
+	for i := range texts {
+		if Get(ctx, Text(texts[i]).Hash("embedding", model), &vv[i]) <= 0 {
+			miss = append(miss, i)
+		}
+	}
+	size := 2048
+	if vendor == "Gemini" {
+		size = 100
+	}
+	for b := range slices.Chunk(miss, size) {
+		var in []string
+		for _, i := range b {
+			in = append(in, texts[i])
+		}
+		out, err := c.embed(ctx, api, vendor, model, in)
+		if err != nil {
+			return nil, err
+		}
+		for n, i := range b {
+			vv[i] = out[n]
+			Set(ctx, Text(texts[i]).Hash("embedding", model), vv[i], c.Cache)
+		}
+	}
+	return vv, nil
+}
+
+// embed sends a single batch of texts to the vendor embedding endpoint.
+func (c *LLM) embed(ctx context.Context, api *API, vendor, model string, texts []string) ([][]float32, error) {
+	var res JSON
+	var err error
+	path := "data.#.embedding"
+	switch vendor {
+	case "Gemini":
+		var rr []Meta
+		for _, s := range texts {
+			rr = append(rr, Meta{"model": "models/" + model, "content": Meta{"parts": []Meta{{"text": s}}}})
+		}
+		path = "embeddings.#.values"
+		res, err = c.post(ctx, api.Endpoint("/v1beta/models/%s:batchEmbedContents", model).Context(ctx), Meta{"requests": rr})
+	default:
+		res, err = c.post(ctx, api.Endpoint("/v1/embeddings").Context(ctx), Meta{"model": model, "input": texts})
+	}
+	if err != nil {
+		return nil, ErrEmbedding.Wrap(err)
+	}
+	var out [][]float32
+	if err = res.Select(path).To(&out); err != nil {
+		return nil, ErrEmbedding.Wrap(err)
+	}
+	if len(out) != len(texts) {
+		return nil, ErrEmbedding.New("%s returned %d embeddings for %d texts", vendor, len(out), len(texts))
+	}
+	return out, nil
+}
+
+// textCosine returns cosine similarity of two vectors, 0 when any is empty
+// or their lengths differ.
+func textCosine(a, b []float32) float64 {
+	if len(a) == 0 || len(a) != len(b) {
+		return 0
+	}
+	var dot, na, nb float64
+	for i := range a {
+		x, y := float64(a[i]), float64(b[i])
+		dot, na, nb = dot+x*y, na+x*x, nb+y*y
+	}
+	if na == 0 || nb == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(na) * math.Sqrt(nb))
+}
+
+var ErrEmbedding = ErrAI.New("embedding")
@@ -0,0 +1,57 @@
+package ion_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/sokool/ion"
+)
+
+func TestText_Semantic(t *testing.T) {
+	vectors := map[string][]float32{
+		"Main St. 5, NYC":         {1, 0.1},
+		"5 Main Street, New York": {0.9, 0.2},
+		"semantic test unrelated": {-1, 0},
+	}
+	var calls int
+	ion.Endpoints.Handler(func(r *http.Request, w *httptest.ResponseRecorder) {
+		calls++
+		b, _ := io.ReadAll(r.Body)
+		req := ion.JSON(b)
+		var data []ion.Meta
+		for _, s := range req.Strings("input") {
+			data = append(data, ion.Meta{"embedding": vectors[s]})
+		}
+		if r.URL.Path != "/v1/embeddings" || req.Text("model") != "semantic-test" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(ion.Meta{"data": data})
+	}, "CHATGPT_URL")
+	ctx := context.Background()
+	l := (&ion.LLM{Model: "gpt-test"}).Option("embedding", "semantic-test")
+	s, err := ion.Text("Main St. 5, NYC").Semantic(ctx, l, "5 Main Street, New York")
+	if err != nil || s < 0.9 {
+		t.Fatalf("expected similar addresses, got %f %v", s, err)
+	}
+	if s, err = ion.Text("Main St. 5, NYC").Semantic(ctx, l, "semantic test unrelated"); err != nil || s != 0 {
+		t.Fatalf("expected opposite texts not similar, got %f %v", s, err)
+	}
+	if calls != 2 {
+		t.Fatalf("expected embeddings of known text read from Store, got %d calls", calls)
+	}
+}
+
+func TestLLM_EmbedClaude(t *testing.T) {
+	var calls int
+	ion.Endpoints.Handler(func(r *http.Request, w *httptest.ResponseRecorder) { calls++ }, "ANTHROPIC_URL")
+	_, err := (&ion.LLM{Model: "claude-test"}).Embed(context.Background(), "hi")
+	if !errors.Is(err, ion.ErrEmbedding) || calls != 0 {
+		t.Fatalf("expected embedding error without call, got %d calls %v", calls, err)
+	}
+}
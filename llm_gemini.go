@@ -0,0 +1,107 @@
+package ion
+
+import (
+	"maps"
+	"slices"
+)
+
+// GeminiSettings are options specific to Gemini models, sent as
+// safetySettings, generationConfig and tools of the request.
+//
+// Example:
+//
+//	budget := 1024
+//	l := &ion.LLM{Model: "gemini-2.5-flash", Gemini: &ion.GeminiSettings{
+//		Safety:         map[string]string{"HARM_CATEGORY_HARASSMENT": "BLOCK_ONLY_HIGH"},
+//		ThinkingBudget: &budget,
+//		GoogleSearch:   true,
+//	}}
+type GeminiSettings struct {
+	// Safety thresholds by harm category, ie. HARM_CATEGORY_HATE_SPEECH: BLOCK_ONLY_HIGH.
+	Safety map[string]string `json:",omitempty"`
+	// ThinkingBudget in tokens, -1 lets the model decide and 0 disables
+	// thinking, model default when nil.
+	ThinkingBudget *int `json:",omitempty"`
+	// IncludeThoughts keeps summaries of model thoughts in reply Meta.
+	IncludeThoughts bool `json:",omitempty"`
+	// ResponseMIME of the reply, ie. text/plain, application/json or text/x.enum.
+	ResponseMIME string `json:",omitempty"`
+	// GoogleSearch grounds replies in Google Search results.
+	GoogleSearch bool `json:",omitempty"`
+	// URLContext lets the model read URLs given in the prompt.
+	URLContext bool `json:",omitempty"`
+	// CodeExecution lets the model run Python code it writes.
+	CodeExecution bool `json:",omitempty"`
+	// Config is merged into generationConfig as is, for options without
+	// their own field.
+	Config Meta `json:",omitempty"`
+}
+
+// safety returns safetySettings of the request, sorted by category.
+func (s *GeminiSettings) safety() []Meta {
+	if s == nil {
+		return nil
+	}
+	var mm []Meta
+	for _, c := range slices.Sorted(maps.Keys(s.Safety)) {
+		mm = append(mm, Meta{"category": c, "threshold": s.Safety[c]})
+	}
+	return mm
+}
+
+// config sets options of generationConfig.
+func (s *GeminiSettings) config(gen Meta) {
+	if s == nil {
+		return
+	}
+	if s.ThinkingBudget != nil || s.IncludeThoughts {
+		t := Meta{}
+		if s.ThinkingBudget != nil {
+			t["thinkingBudget"] = *s.ThinkingBudget
+		}
+		if s.IncludeThoughts {
+			t["includeThoughts"] = true
+		}
+		gen["thinkingConfig"] = t
+	}
+	if s.ResponseMIME != "" {
+		gen["responseMimeType"] = s.ResponseMIME
+	}
+	maps.Copy(gen, s.Config)
+}
+
+// tools returns grounding and code execution tools.
+func (s *GeminiSettings) tools() []Meta {
+	if s == nil {
+		return nil
+	}
+	var tt []Meta
+	if s.GoogleSearch {
+		tt = append(tt, Meta{"google_search": Meta{}})
+	}
+	if s.URLContext {
+		tt = append(tt, Meta{"url_context": Meta{}})
+	}
+	if s.CodeExecution {
+		tt = append(tt, Meta{"code_execution": Meta{}})
+	}
+	return tt
+}
+
+// validate rejects unknown safety thresholds and out of range thinking budget.
+func (s *GeminiSettings) validate() error {
+	if s == nil {
+		return nil
+	}
+	for c, t := range s.Safety {
+		if !slices.Contains(geminiThresholds, t) {
+			return ErrLLMOption.New("%s threshold of %s must be one of %v", t, c, geminiThresholds)
+		}
+	}
+	if s.ThinkingBudget != nil && *s.ThinkingBudget < -1 {
+		return ErrLLMOption.New("thinking budget must be -1 or more, %d given", *s.ThinkingBudget)
+	}
+	return nil
+}
+
+var geminiThresholds = []string{"BLOCK_NONE", "BLOCK_ONLY_HIGH", "BLOCK_MEDIUM_AND_ABOVE", "BLOCK_LOW_AND_ABOVE", "OFF"}
@@ -0,0 +1,21 @@
+package ion_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sokool/ion"
+)
+
+func TestGeminiSettings_Validate(t *testing.T) {
+	budget := -2
+	for _, g := range []*ion.GeminiSettings{
+		{Safety: map[string]string{"HARM_CATEGORY_HARASSMENT": "BLOCK_SOME"}},
+		{ThinkingBudget: &budget},
+	} {
+		l := &ion.LLM{Model: "gemini-2.5-flash", Gemini: g}
+		if _, err := l.Response(context.Background(), ion.Message{Role: "user", Content: "hi"}); !ion.ErrLLMOption.In(err) {
+			t.Fatalf("expected ErrLLMOption, got %v", err)
+		}
+	}
+}
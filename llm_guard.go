@@ -0,0 +1,96 @@
+package ion
+
+import (
+	"slices"
+)
+
+// Guard directions, content of user messages sent to the model is checked
+// as GuardInput, content of its replies as GuardOutput.
+const (
+	GuardInput  = "input"
+	GuardOutput = "output"
+)
+
+// Guard adds fn checking content exchanged with the model, run on user
+// messages before they are sent and on replies before they are returned.
+// Error of any guard stops the completion with ErrModeration.
+//
+// Example:
+//
+//	l := (&ion.LLM{}).Guard(ion.Moderation).Guard(func(dir, s string) error {
+//		if dir == ion.GuardOutput && strings.Contains(s, secret) {
+//			return ion.Errorf("secret leaked")
+//		}
+//		return nil
+//	})
+func (c *LLM) Guard(fn func(direction, content string) error) *LLM {
+	c.guards = append(c.guards, fn)
+	return c
+}
+
+// Moderation is a guard flagging content with OpenAI moderation API, using
+// CHATGPT_URL and omni-moderation-latest model unless the URL has moderation
+// query parameter.
+func Moderation(direction, content string) error {
+	api, err := NewAPI("CHATGPT_URL")
+	if err != nil {
+		return ErrModeration.Wrap(err)
+	}
+	api.Name = "ChatGPT"
+	model := api.URL.Query("moderation")
+	if model == "" {
+		model = "omni-moderation-latest"
+	}
+	res, err := (&LLM{}).post(ctx, api.Endpoint("/v1/moderations"), Meta{"model": model, "input": content})
+	if err != nil {
+		return ErrModeration.Wrap(err)
+	}
+	var cc []string
+	for r := range res.Each("results") {
+		if !r.Bool("flagged") {
+			continue
+		}
+		for v, n := range r.Each("categories") {
+			if v.String() == "true" {
+				cc = append(cc, n)
+			}
+		}
+	}
+	if len(cc) > 0 {
+		slices.Sort(cc)
+		return ErrModeration.New("%s flagged as %s", direction, slices.Compact(cc))
+	}
+	return nil
+}
+
+// guard runs guards on trailing user messages for input and on assistant
+// replies for output.
+func (c *LLM) guard(direction string, mm []Message) error {
+	if len(c.guards) == 0 {
+		return nil
+	}
+	for i := len(mm) - 1; i >= 0; i-- {
+		m := mm[i]
+		if direction == GuardInput && m.Role != "user" {
+			break
+		}
+		if m.Content == "" || direction == GuardOutput && m.Role != "assistant" {
+			continue
+		}
+		for _, fn := range c.guards {
+			if err := fn(direction, m.Content); err != nil {
+				if ErrModeration.In(err) {
+					return err
+				}
+				return ErrModeration.Wrap(err)
+			}
+		}
+	}
+	return nil
+}
+
+// llmGuarded keeps LLM in context of its completions run again by tools,
+// which are checked once by the outermost one.
+type llmGuarded struct{}
+
+var ErrModeration = ErrAI.New("moderation")
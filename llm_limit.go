@@ -0,0 +1,114 @@
+package ion
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// LLMLimit is a rate limit of a model in requests and tokens per minute,
+// zero is unlimited. Tokens are estimated from the request before the call
+// and settled with usage reported by the vendor after it.
+type LLMLimit struct {
+	RPM int
+	TPM int
+}
+
+// UseLLMLimits sets rate limits of models, matched by name like prices of
+// UseLLMPrices. Limits can also be given in vendor URL query as rpm and tpm
+// for all models, or rpm.{model} and tpm.{model} for one of them, ie.
+// CHATGPT_URL=https://...?tpm=30000&tpm.gpt-4o-mini=200000. Limits apply to
+// each process, budgets are saved in the Store to survive its restart only.
+//
+// Example:
+//
+//	ion.UseLLMLimits(map[string]ion.LLMLimit{
+//		"gpt-4o":           {RPM: 500, TPM: 30_000},
+//		"claude-sonnet-4":  {RPM: 50, TPM: 40_000},
+//	})
+func UseLLMLimits(l map[string]LLMLimit) {
+	llmLimitsMu.Lock()
+	defer llmLimitsMu.Unlock()
+	llmLimits = l
+}
+
+// limit waits for request and token budget of the model before a vendor
+// call and returns function settling tokens used by the call with its response.
+func (c *LLM) limit(ctx context.Context, api *API, req Meta) (func(JSON), error) {
+	model := req.Text("model")
+	if model == "" {
+		model = c.Model
+	}
+	l, n := llmLimitOf(api, model), 0
+	if l.RPM > 0 {
+		if err := llmLimiter(l.RPM).CheckN(ctx, "llm:rpm:"+model, 1); err != nil {
+			return nil, err
+		}
+	}
+	if l.TPM > 0 {
+		n = Text(req.JSON()).Tokens(model)
+		if err := llmLimiter(l.TPM).CheckN(ctx, "llm:tpm:"+model, n); err != nil {
+			return nil, err
+		}
+	}
+	return func(res JSON) {
+		if l.TPM <= 0 {
+			return
+		}
+		in, out := llmUsed(api.Name, res)
+		if in+out > 0 {
+			llmLimiter(l.TPM).take(ctx, "llm:tpm:"+model, in+out-n)
+		}
+	}, nil
+}
+
+// llmLimitOf returns limit of the model set by UseLLMLimits, or in api URL.
+func llmLimitOf(api *API, model string) LLMLimit {
+	var l LLMLimit
+	var n int
+	llmLimitsMu.RLock()
+	for m, x := range llmLimits {
+		if strings.HasPrefix(model, m) && len(m) > n {
+			l, n = x, len(m)
+		}
+	}
+	llmLimitsMu.RUnlock()
+	if n > 0 {
+		return l
+	}
+	rn, tn := -1, -1
+	for k, v := range api.URL.URL.Query() {
+		name, m, _ := strings.Cut(k, ".")
+		x, err := strconv.Atoi(v[0])
+		if err != nil || !strings.HasPrefix(model, m) {
+			continue
+		}
+		switch {
+		case name == "rpm" && len(m) > rn:
+			l.RPM, rn = x, len(m)
+		case name == "tpm" && len(m) > tn:
+			l.TPM, tn = x, len(m)
+		}
+	}
+	return l
+}
+
+// llmLimiter returns limiter refilled with n tokens per minute, shared by
+// models of the same limit, as they are kept under separate keys.
+func llmLimiter(n int) *limiter {
+	llmLimitsMu.Lock()
+	defer llmLimitsMu.Unlock()
+	l, ok := llmLimiters[n]
+	if !ok {
+		l = newLimiter(float64(n)/60, n)
+		llmLimiters[n] = l
+	}
+	return l
+}
+
+var (
+	llmLimitsMu sync.RWMutex
+	llmLimits   map[string]LLMLimit
+	llmLimiters = map[int]*limiter{}
+)
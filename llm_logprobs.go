@@ -0,0 +1,62 @@
+package ion
+
+import "math"
+
+// Logprob is a log probability of a generated token with the most likely
+// candidates at its position, when TopLogprobs was requested.
+type Logprob struct {
+	Token   string    `json:"token"`
+	Logprob float64   `json:"logprob"`
+	Top     []Logprob `json:"top,omitempty"`
+}
+
+// Logprobs returns log probabilities of message tokens, given when LLM had
+// Logprobs enabled.
+func (m Message) Logprobs() []Logprob {
+	var lp []Logprob
+	if m.Meta.Has("logprobs") {
+		_ = m.Meta.Select("logprobs").To(&lp)
+	}
+	return lp
+}
+
+// Confidence returns probability of the whole message content in [0..1],
+// for classification-style replies it is the probability of the label. It
+// is 0 when logprobs were not requested.
+func (m Message) Confidence() float64 {
+	lp := m.Logprobs()
+	if len(lp) == 0 {
+		return 0
+	}
+	var s float64
+	for _, p := range lp {
+		s += p.Logprob
+	}
+	return math.Exp(s)
+}
+
+// logprobs reads token log probabilities of a ChatGPT choice or a Gemini
+// candidate in vendor-neutral form.
+func (c *LLM) logprobs(vendor string, r JSON) []Logprob {
+	var lp []Logprob
+	switch vendor {
+	case "Gemini":
+		top := r.Select("logprobsResult.topCandidates")
+		for t, i := range r.Each("logprobsResult.chosenCandidates") {
+			p := Logprob{Token: t.Text("token"), Logprob: t.Number("logProbability")}
+			for x := range top.Each(i + ".candidates") {
+				p.Top = append(p.Top, Logprob{Token: x.Text("token"), Logprob: x.Number("logProbability")})
+			}
+			lp = append(lp, p)
+		}
+	default:
+		for t := range r.Each("logprobs.content") {
+			p := Logprob{Token: t.Text("token"), Logprob: t.Number("logprob")}
+			for x := range t.Each("top_logprobs") {
+				p.Top = append(p.Top, Logprob{Token: x.Text("token"), Logprob: x.Number("logprob")})
+			}
+			lp = append(lp, p)
+		}
+	}
+	return lp
+}
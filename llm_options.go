@@ -0,0 +1,81 @@
+package ion
+
+import (
+	"regexp"
+)
+
+// params returns typed generation options named as vendor expects them,
+// Gemini ones belong to generationConfig. Unset options are left out.
+func (c *LLM) params(vendor string) Meta {
+	m := Meta{}
+	set := func(name string, v any, ok bool) {
+		if ok {
+			m[name] = v
+		}
+	}
+	switch vendor {
+	case "Gemini":
+		set("temperature", c.Temperature, c.Temperature != 0)
+		set("maxOutputTokens", c.MaxTokens, c.MaxTokens > 0)
+		set("topP", c.TopP, c.TopP > 0)
+		set("stopSequences", c.Stop, len(c.Stop) > 0)
+		set("seed", c.Seed, c.Seed != 0)
+		set("responseLogprobs", true, c.Logprobs)
+		set("logprobs", c.TopLogprobs, c.Logprobs && c.TopLogprobs > 0)
+	case "Responses":
+		set("max_output_tokens", c.MaxTokens, c.MaxTokens > 0)
+		set("top_p", c.TopP, c.TopP > 0)
+	case "Claude":
+		set("max_tokens", c.MaxTokens, c.MaxTokens > 0)
+		set("top_p", c.TopP, c.TopP > 0)
+		set("stop_sequences", c.Stop, len(c.Stop) > 0)
+	default:
+		// reasoning models accept max_completion_tokens only
+		n := "max_tokens"
+		if llmReasoning.MatchString(c.Model) {
+			n = "max_completion_tokens"
+		}
+		set(n, c.MaxTokens, c.MaxTokens > 0)
+		set("top_p", c.TopP, c.TopP > 0)
+		set("stop", c.Stop, len(c.Stop) > 0)
+		set("seed", c.Seed, c.Seed != 0)
+		set("logprobs", true, c.Logprobs)
+		set("top_logprobs", c.TopLogprobs, c.Logprobs && c.TopLogprobs > 0)
+	}
+	return m
+}
+
+// validate rejects options vendor does not support, instead of letting the
+// API fail or silently ignore them.
+func (c *LLM) validate(vendor string) error {
+	stops := 4
+	if vendor == "Gemini" {
+		stops = 5
+	}
+	switch {
+	case c.MaxTokens < 0:
+		return ErrLLMOption.New("max tokens must not be negative, %d given", c.MaxTokens)
+	case c.TopP < 0 || c.TopP > 1:
+		return ErrLLMOption.New("top p must be in (0..1], %v given", c.TopP)
+	case len(c.Stop) > stops:
+		return ErrLLMOption.New("%s accepts up to %d stop sequences, %d given", vendor, stops, len(c.Stop))
+	case c.TopLogprobs < 0 || c.TopLogprobs > 20:
+		return ErrLLMOption.New("top logprobs must be in [0..20], %d given", c.TopLogprobs)
+	case c.Logprobs && (vendor == "Claude" || vendor == "Responses"):
+		return ErrLLMOption.New("%s does not support logprobs", vendor)
+	case c.Seed != 0 && (vendor == "Claude" || vendor == "Responses"):
+		return ErrLLMOption.New("%s does not support seed", vendor)
+	case len(c.Stop) > 0 && vendor == "Responses":
+		return ErrLLMOption.New("%s does not support stop sequences", vendor)
+	case c.Temperature != 0 && c.TopP != 0 && vendor == "Claude":
+		return ErrLLMOption.New("%s accepts temperature or top p, not both", vendor)
+	case vendor == "Gemini":
+		return c.Gemini.validate()
+	}
+	return nil
+}
+
+var (
+	llmReasoning = regexp.MustCompile(`^(o\d|gpt-5)`)
+	ErrLLMOption = ErrCompletion.New("option")
+)
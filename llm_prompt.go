@@ -60,6 +60,21 @@ func (p Prompt) Param(key string, value any) Prompt {
 	return r.Convert()
 }
 
+// Example adds a few-shot example, user message and expected assistant
+// reply, put after system messages, so classification or extraction
+// prompts carry curated examples outside of the template.
+//
+// Example:
+//
+//	p := ion.Prompt("Classify sentiment as positive, negative or neutral").
+//		Example("I love it", "positive").
+//		Example("It broke after a day", "negative")
+func (p Prompt) Example(user, assistant string) Prompt {
+	r := ParseLLMChat(p)
+	r.Examples = append(r.Examples, Message{Role: "user", Content: user}, Message{Role: "assistant", Content: assistant})
+	return r.Convert()
+}
+
 func (p Prompt) parse(s string, j Meta) (string, error) {
 	t, err := template.
 		New("parser").
@@ -88,7 +103,11 @@ func (p Prompt) Ask(text ...string) (Prompt, error) {
 	for i := range text {
 		r.Put(text[i])
 	}
-	r.ID, r.Prompt = UUID(s), s
+	x := s
+	for _, m := range r.Examples {
+		x += "\n" + m.Role + ": " + m.Content
+	}
+	r.ID, r.Prompt = UUID(x), s
 	if _, err := r.Complete(ctx); err != nil {
 		return "", err
 	}
@@ -124,10 +143,17 @@ type prompt struct {
 }
 
 func ParseLLMChat(p Prompt) *prompt {
+	s, ref, found := promptLookup(string(p))
+	if found {
+		p = Prompt(s)
+	}
 	r := prompt{&LLMChat{Prompt: string(p), Meta: Meta{}}}
 	if err := json.Unmarshal([]byte(p), &r); err != nil {
 		//
 	}
+	if found && r.Name == "" {
+		r.Name = ref
+	}
 	return &r
 }
 
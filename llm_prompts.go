@@ -0,0 +1,108 @@
+package ion
+
+import (
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// LoadPrompts registers prompt templates of .md, .txt and .json files found
+// in fsys, usually an embed.FS, named by their path without extension. A
+// version follows the name after @, so prompts/invoice@v2.md is referenced
+// as Prompt("prompts/invoice@v2"), or as Prompt("prompts/invoice") for its
+// latest version. JSON files hold a whole prompt with its model, options and
+// params, as built by Prompt methods like Model. Chats of a registered prompt are named
+// by its name@vN reference, so their storage keys and metrics are kept per
+// version, which makes prompt changes trackable and A/B testable.
+//
+// Example:
+//
+//	//go:embed prompts
+//	var files embed.FS
+//
+//	ion.LoadPrompts(files)
+//	s, err := ion.Prompt("prompts/invoice@v2").Param("number", n).Message(text)
+func LoadPrompts(fsys fs.FS) error {
+	return fs.WalkDir(fsys, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		ext := path.Ext(p)
+		if ext != ".md" && ext != ".txt" && ext != ".json" {
+			return nil
+		}
+		b, err := fs.ReadFile(fsys, p)
+		if err != nil {
+			return ErrPrompt.Wrap(err)
+		}
+		return UsePrompt(strings.TrimSuffix(p, ext), string(b))
+	})
+}
+
+// UsePrompt registers prompt template text under name@vN reference, the
+// version is 0 when not given. Registering the same version replaces it.
+func UsePrompt(ref, text string) error {
+	m := promptRef.FindStringSubmatch(ref)
+	if m == nil {
+		return ErrPrompt.New("invalid %q reference, name@v1 expected", ref)
+	}
+	v, _ := strconv.Atoi(m[2])
+	promptsMu.Lock()
+	defer promptsMu.Unlock()
+	if prompts[m[1]] == nil {
+		prompts[m[1]] = map[int]string{}
+	}
+	prompts[m[1]][v] = text
+	return nil
+}
+
+// Prompts returns references of registered prompts, name@vN sorted.
+func Prompts() []string {
+	promptsMu.RLock()
+	defer promptsMu.RUnlock()
+	var rr []string
+	for n, vv := range prompts {
+		for v := range vv {
+			rr = append(rr, fmt.Sprintf("%s@v%d", n, v))
+		}
+	}
+	slices.Sort(rr)
+	return rr
+}
+
+// promptLookup returns text of registered prompt and its full name@vN
+// reference, the latest version when ref has none.
+func promptLookup(ref string) (string, string, bool) {
+	m := promptRef.FindStringSubmatch(ref)
+	if m == nil {
+		return "", "", false
+	}
+	promptsMu.RLock()
+	defer promptsMu.RUnlock()
+	vv, ok := prompts[m[1]]
+	if !ok {
+		return "", "", false
+	}
+	v := -1
+	if m[2] != "" {
+		v, _ = strconv.Atoi(m[2])
+	} else {
+		for n := range vv {
+			v = max(v, n)
+		}
+	}
+	s, ok := vv[v]
+	return s, fmt.Sprintf("%s@v%d", m[1], v), ok
+}
+
+var (
+	promptsMu sync.RWMutex
+	prompts   = map[string]map[int]string{}
+	promptRef = regexp.MustCompile(`^([\w\-./]+?)(?:@v(\d+))?$`)
+	ErrPrompt = ErrAI.New("prompt")
+)
@@ -0,0 +1,41 @@
+package ion_test
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/sokool/ion"
+)
+
+func TestLoadPrompts(t *testing.T) {
+	fs := fstest.MapFS{
+		"prompts/invoice.md":      {Data: []byte("Read invoice {.number}")},
+		"prompts/invoice@v2.md":   {Data: []byte("Extract invoice {.number}")},
+		"prompts/summary@v1.json": {Data: []byte(`{"Prompt":"Summarize","Completion":{"Model":"gpt-4o-mini"}}`)},
+		"prompts/readme.rst":      {Data: []byte("skipped")},
+	}
+	if err := ion.LoadPrompts(fs); err != nil {
+		t.Fatal(err)
+	}
+	if p := ion.Prompts(); len(p) != 3 || p[0] != "prompts/invoice@v0" || p[2] != "prompts/summary@v1" {
+		t.Fatalf("unexpected prompts %v", p)
+	}
+	cases := map[ion.Prompt][2]string{
+		"prompts/invoice":    {"Extract invoice {.number}", "prompts/invoice@v2"},
+		"prompts/invoice@v0": {"Read invoice {.number}", "prompts/invoice@v0"},
+		"prompts/summary":    {"Summarize", "prompts/summary@v1"},
+		"prompts/invoice@v7": {"prompts/invoice@v7", ""},
+		"plain prompt text":  {"plain prompt text", ""},
+	}
+	for p, x := range cases {
+		if c := p.Chat(); c.Prompt != x[0] || c.Name != x[1] {
+			t.Fatalf("%s expected %q named %q, got %q named %q", p, x[0], x[1], c.Prompt, c.Name)
+		}
+	}
+	if m := ion.Prompt("prompts/summary").Completion().Model; m != "gpt-4o-mini" {
+		t.Fatalf("expected model of JSON prompt, got %q", m)
+	}
+	if err := ion.UsePrompt("bad ref", ""); err == nil {
+		t.Fatal("expected invalid reference error")
+	}
+}
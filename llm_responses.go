@@ -0,0 +1,117 @@
+package ion
+
+import (
+	"context"
+	"maps"
+	"regexp"
+)
+
+// responses tells whether c is completed with OpenAI Responses API instead
+// of Chat Completions, for models available there only, with Builtin tools,
+// or when api option or api query parameter of CHATGPT_URL is "responses".
+func (c *LLM) responses(api *API) bool {
+	a, _ := c.Options["api"].(string)
+	if a == "" {
+		a = api.URL.Query("api")
+	}
+	return a == "responses" || len(c.Builtin) > 0 || llmResponsesOnly.MatchString(c.Model)
+}
+
+// openAI calls OpenAI Responses API. Messages are sent as typed input items,
+// tool calls are exchanged as function_call and function_call_output items
+// and Builtin tools like web_search run on the vendor side, their results
+// are part of the reply.
+func (c *LLM) openAI(ctx context.Context, api *API, msg ...Message) ([]Message, error) {
+	var tools []Meta
+	for _, t := range c.Tool {
+		for _, s := range t.Schemas {
+			f := s.JSON("function")
+			tools = append(tools, Meta{
+				"type":        "function",
+				"name":        f.Text("name"),
+				"description": f.Text("description"),
+				"parameters":  f.Select("parameters"),
+			})
+		}
+	}
+	tools = append(tools, c.Builtin...)
+
+	var in []Meta
+	for _, m := range msg {
+		switch {
+		case m.Role == "function":
+			in = append(in, Meta{"type": "function_call_output", "call_id": m.ID, "output": m.Content})
+		case m.Meta.Has("function_call"):
+			in = append(in, m.Meta.JSON("function_call").Meta())
+		case len(m.Files) > 0:
+			cc := []Meta{{"type": "input_text", "text": m.Content}}
+			for _, f := range m.Files {
+				cc = append(cc, Meta{"type": "input_file", "file_id": f.ID})
+			}
+			in = append(in, Meta{"role": m.Role, "content": cc})
+		case m.Content != "":
+			in = append(in, Meta{"role": m.Role, "content": m.Content})
+		}
+	}
+	req := Meta{
+		"model": c.Model,
+		"input": in,
+		"store": false,
+	}
+	if c.Instruction != "" {
+		req["instructions"] = c.Instruction
+	}
+	if c.Temperature != 0 {
+		req["temperature"] = c.Temperature
+	}
+	if len(tools) > 0 {
+		req["tools"] = tools
+	}
+	maps.Copy(req, c.params("Responses"))
+	if c.schema != nil {
+		req["text"] = Meta{"format": Meta{
+			"type":   "json_schema",
+			"name":   "reply",
+			"schema": c.schema,
+			"strict": llmStrict(c.schema),
+		}}
+	}
+	if b := BuildInfo(); b.Version != "" {
+		req["metadata"] = b.Meta()
+	}
+	res, cached, err := c.completion(ctx, api.Endpoint("/v1/responses").Context(ctx).Cache(c.Cache, c.Name), req)
+	if err != nil {
+		return nil, ErrCompletion.Wrap(err)
+	}
+	if s := res.Text("status"); s == "failed" || s == "incomplete" && !res.Has("output") {
+		return nil, ErrCompletion.New("%s response %s %.256s", api.Name, s, res.Select("error", "incomplete_details"))
+	}
+	u := c.usage("Responses", res, cached)
+	for o := range res.Each("output") {
+		switch o.Text("type") {
+		case "message":
+			for p := range o.Each("content") {
+				s := p.Text("text")
+				if p.Text("type") != "output_text" || s == "" {
+					continue
+				}
+				x := Message{Role: "assistant", Content: s, Usage: u}
+				if p.Has("annotations.0") {
+					x.Meta = Meta{"annotations": p.Select("annotations")}
+				}
+				msg, u = append(msg, x), nil
+			}
+		case "function_call":
+			fid, fnn := o.Text("call_id"), o.Text("name")
+			call := Meta{"type": "function_call", "call_id": fid, "name": fnn, "arguments": o.Text("arguments")}
+			msg, u = append(msg, Message{Role: "assistant", Meta: Meta{"function_call": call}, Usage: u}), nil
+			if msg, err = c.tool(ctx, msg, fid, fnn, JSON(o.Text("arguments"))); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return msg, nil
+}
+
+// llmResponsesOnly matches OpenAI models served by Responses API only.
+var llmResponsesOnly = regexp.MustCompile(`^(o\d+-pro|o\d+-deep-research|codex-|computer-use|gpt-5(\.\d+)?-pro)`)
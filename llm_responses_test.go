@@ -0,0 +1,73 @@
+package ion_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/sokool/ion"
+)
+
+func TestLLM_Responses(t *testing.T) {
+	var calls int
+	ion.Endpoints.Handler(func(r *http.Request, w *httptest.ResponseRecorder) {
+		b, _ := io.ReadAll(r.Body)
+		req := ion.JSON(b)
+		calls++
+		if r.URL.Path != "/v1/responses" || req.Text("instructions") != "be brief" || req.Text("tools.1.type") != "web_search" {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write(b)
+			return
+		}
+		if calls == 1 {
+			_ = json.NewEncoder(w).Encode(ion.Meta{"output": []ion.Meta{
+				{"type": "function_call", "call_id": "c1", "name": "order", "arguments": `{"number":"A1"}`},
+			}})
+			return
+		}
+		// the call and its output are sent back as input items
+		var call, out ion.JSON
+		for i := range req.Each("input") {
+			switch i.Text("type") {
+			case "function_call":
+				call = i
+			case "function_call_output":
+				out = i
+			}
+		}
+		if call.Text("call_id") != "c1" || out.Text("call_id") != "c1" || out.Text("output") != "A1" {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write(b)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(ion.Meta{
+			"status": "completed",
+			"output": []ion.Meta{{"type": "message", "content": []ion.Meta{
+				{"type": "output_text", "text": "A1 shipped", "annotations": []ion.Meta{{"type": "url_citation", "url": "https://example.com"}}},
+			}}},
+			"usage": ion.Meta{"input_tokens": 10, "output_tokens": 5},
+		})
+	}, "CHATGPT_URL")
+	tl, err := ion.NewTool("order", "finds order", func(q OrderQuery) (string, bool) { return q.Number, true })
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := ion.LLM{Model: "gpt-test", Instruction: "be brief", Tool: []ion.Tool{tl}, Builtin: []ion.Meta{{"type": "web_search"}}}
+	mm, err := c.Response(context.Background(), ion.Message{Role: "user", Content: "where is A1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := mm[len(mm)-1]
+	if calls != 2 || m.Content != "A1 shipped" || !m.Meta.Has("annotations") || m.Usage == nil || m.Usage.Output != 5 {
+		t.Fatalf("unexpected reply after %d calls %+v", calls, m)
+	}
+	ion.Endpoints.Handler(func(r *http.Request, w *httptest.ResponseRecorder) {
+		_ = json.NewEncoder(w).Encode(ion.Meta{"status": "failed", "error": ion.Meta{"message": "boom"}})
+	}, "CHATGPT_URL")
+	if _, err = c.Response(context.Background(), ion.Message{Role: "user", Content: "again"}); err == nil {
+		t.Fatal("expected error of failed response")
+	}
+}
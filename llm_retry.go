@@ -0,0 +1,92 @@
+package ion
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// completion posts completion request, reporting whether its response was
+// read from cache, so its usage is not counted again.
+func (c *LLM) completion(ctx context.Context, e Endpoint[Meta, JSON], req Meta) (JSON, bool, error) {
+	var cached bool
+	res, err := c.post(ctx, e.Context(context.WithValue(ctx, endpointCachedKey{}, &cached)), req)
+	return res, cached, err
+}
+
+// post sends request to the vendor endpoint, retrying rate limited (429),
+// overloaded (529) and failed (5xx) calls up to Retries times. The delay
+// is taken from retry-after or retry-after-ms headers, Gemini retryDelay of
+// the error body, or grows exponentially from a second up to 30s. Each
+// attempt waits for the model budget of UseLLMLimits first.
+func (c *LLM) post(ctx context.Context, e Endpoint[Meta, JSON], req Meta) (JSON, error) {
+	n := c.Retries
+	if n == 0 {
+		n = 3
+	}
+	for i := 0; ; i++ {
+		settle, err := c.limit(ctx, e.domain, req)
+		if err != nil {
+			return nil, err
+		}
+		res, err := e.Post(req)
+		settle(res)
+		var s *llmStatus
+		if err == nil || i >= n || !errors.As(err, &s) || !s.transient() {
+			return res, err
+		}
+		d := s.after
+		if d <= 0 {
+			// shift capped, so many retries do not overflow
+			d = min(time.Second<<min(i, 5), 30*time.Second)
+			d += rand.N(d/4 + 1)
+		}
+		log_.Infof("LLM %s %s, retry %d/%d in %s", e.domain.Name, s.status, i+1, n, d.Round(time.Millisecond))
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(d):
+		}
+	}
+}
+
+// llmStatus is an error of a failed vendor call keeping what retry needs.
+type llmStatus struct {
+	code   int
+	status string
+	after  time.Duration
+	body   string
+}
+
+func (s *llmStatus) Error() string {
+	return fmt.Sprintf("%s: %s", s.status, s.body)
+}
+
+func (s *llmStatus) transient() bool {
+	return s.code == http.StatusTooManyRequests || s.code >= 500
+}
+
+func llmErrors(_ *http.Request, r *http.Response, _ any) error {
+	b, _ := io.ReadAll(r.Body)
+	s := llmStatus{code: r.StatusCode, status: r.Status, body: string(b)}
+	if n, err := strconv.Atoi(r.Header.Get("retry-after-ms")); err == nil {
+		s.after = time.Duration(n) * time.Millisecond
+	} else if n, err := strconv.Atoi(r.Header.Get("retry-after")); err == nil {
+		s.after = time.Duration(n) * time.Second
+	} else if t, err := http.ParseTime(r.Header.Get("retry-after")); err == nil {
+		s.after = time.Until(t)
+	} else {
+		for d := range JSON(b).Each("error.details") {
+			if v, err := time.ParseDuration(d.Text("retryDelay")); err == nil {
+				s.after = v
+			}
+		}
+	}
+	s.after = min(s.after, 2*time.Minute)
+	return &s
+}
@@ -0,0 +1,83 @@
+package ion_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/sokool/ion"
+)
+
+func TestLLM_Retry(t *testing.T) {
+	type test struct {
+		name    string
+		model   string
+		host    string
+		status  int
+		header  string
+		value   string
+		body    string
+		retries int
+		fails   int  // failed calls before success
+		err     bool // expected error
+		calls   int
+	}
+	for _, c := range []test{
+		{name: "429 retry-after-ms", model: "claude-test", host: "ANTHROPIC_URL", status: 429, header: "retry-after-ms", value: "10", fails: 2, calls: 3},
+		{name: "529 overloaded", model: "claude-test", host: "ANTHROPIC_URL", status: 529, header: "retry-after-ms", value: "10", fails: 1, calls: 2},
+		{name: "503 failed", model: "claude-test", host: "ANTHROPIC_URL", status: 503, header: "retry-after-ms", value: "10", fails: 1, calls: 2},
+		{name: "gemini retryDelay", model: "gemini-test", host: "GEMINI_URL", status: 429, body: `{"error":{"details":[{"retryDelay":"0.01s"}]}}`, fails: 1, calls: 2},
+		{name: "400 not retried", model: "claude-test", host: "ANTHROPIC_URL", status: 400, fails: 1, err: true, calls: 1},
+		{name: "retries disabled", model: "claude-test", host: "ANTHROPIC_URL", status: 429, header: "retry-after-ms", value: "10", retries: -1, fails: 1, err: true, calls: 1},
+		{name: "retries exhausted", model: "claude-test", host: "ANTHROPIC_URL", status: 429, header: "retry-after-ms", value: "10", retries: 2, fails: 5, err: true, calls: 3},
+	} {
+		var calls int
+		ion.Endpoints.Handler(func(r *http.Request, w *httptest.ResponseRecorder) {
+			if calls++; calls <= c.fails {
+				if c.header != "" {
+					w.Header().Set(c.header, c.value)
+				}
+				w.WriteHeader(c.status)
+				_, _ = w.Write([]byte(c.body))
+				return
+			}
+			if c.host == "GEMINI_URL" {
+				_, _ = w.Write([]byte(`{"candidates":[{"content":{"role":"model","parts":[{"text":"ok"}]}}]}`))
+				return
+			}
+			_, _ = w.Write([]byte(`{"content":[{"type":"text","text":"ok"}]}`))
+		}, c.host)
+		l := ion.LLM{Model: c.model, Retries: c.retries}
+		now := time.Now()
+		mm, err := l.Response(context.Background(), ion.Message{Role: "user", Content: "hi"})
+		if c.err != (err != nil) || calls != c.calls {
+			t.Fatalf("%s: expected %d calls and error %t, got %d calls %v", c.name, c.calls, c.err, calls, err)
+		}
+		if !c.err && mm[len(mm)-1].Content != "ok" {
+			t.Fatalf("%s: expected reply after retries, got %v", c.name, mm)
+		}
+		if d := time.Since(now); d > time.Second {
+			t.Fatalf("%s: expected vendor delay instead of backoff, took %s", c.name, d)
+		}
+	}
+}
+
+func TestLLM_RetryCanceled(t *testing.T) {
+	var calls int
+	ion.Endpoints.Handler(func(r *http.Request, w *httptest.ResponseRecorder) {
+		calls++
+		w.Header().Set("retry-after", "60")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}, "ANTHROPIC_URL")
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	l := ion.LLM{Model: "claude-test"}
+	now := time.Now()
+	_, err := l.Response(ctx, ion.Message{Role: "user", Content: "hi"})
+	if !errors.Is(err, context.DeadlineExceeded) || calls != 1 || time.Since(now) > time.Second {
+		t.Fatalf("expected retry stopped by context, got %d calls in %s %v", calls, time.Since(now), err)
+	}
+}
@@ -0,0 +1,90 @@
+package ion
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"strings"
+
+	"github.com/alecthomas/jsonschema"
+)
+
+// Structured sends messages with JSON Schema of T as the expected reply
+// format and decodes the reply into T, instead of asking for JSON in a
+// prompt. ChatGPT gets the schema as response_format, strict when T has no
+// omitempty fields, Gemini as responseJsonSchema and Claude, which has no
+// such setting, in the system prompt. The reply is validated against
+// validate tags of T, so T is expected to be a struct.
+//
+// Example:
+//
+//	type Invoice struct {
+//		Number string  `json:"number" validate:"required"`
+//		Total  float64 `json:"total"`
+//	}
+//	inv, err := ion.Structured[Invoice](ctx, &ion.LLM{}, ion.Message{Role: "user", Content: text})
+func Structured[T any](ctx context.Context, c *LLM, m ...Message) (T, error) {
+	var t T
+	s, err := llmSchema(t)
+	if err != nil {
+		return t, err
+	}
+	l := *c
+	l.schema = s
+	if m, err = l.Response(ctx, m...); err != nil {
+		return t, err
+	}
+	if len(m) == 0 || m[len(m)-1].Role != "assistant" {
+		return t, ErrStructured.New("no reply")
+	}
+	r := strings.TrimSpace(m[len(m)-1].Content)
+	r = strings.TrimSuffix(strings.TrimPrefix(strings.TrimPrefix(r, "```json"), "```"), "```")
+	if !json.Valid([]byte(r)) {
+		return t, ErrStructured.New("reply is not a JSON %.256q", r)
+	}
+	if err = json.Unmarshal([]byte(r), &t); err != nil {
+		return t, ErrStructured.Wrap(err)
+	}
+	if err = Validate(t); err != nil {
+		return t, ErrStructured.Wrap(err)
+	}
+	return t, nil
+}
+
+// llmSchema returns JSON Schema of v with nested types inlined, as vendors
+// do not resolve references in response formats.
+func llmSchema(v any) (JSON, error) {
+	s := (&jsonschema.Reflector{ExpandedStruct: true, DoNotReference: true}).Reflect(v)
+	b, err := json.Marshal(s)
+	if err != nil {
+		return nil, ErrStructured.Wrap(err)
+	}
+	m := JSON(b).Meta()
+	m.Delete("$schema")
+	m.Delete("definitions")
+	if m.Text("type") == "" {
+		return nil, ErrStructured.New("invalid %s schema", reflect.TypeOf(v))
+	}
+	return m.JSON(), nil
+}
+
+// llmStrict tells if schema meets strict mode of response formats, which
+// requires all object properties, omitempty fields make them optional.
+func llmStrict(s JSON) bool {
+	if p := s.Select("properties"); p.IsObject() {
+		if len(p.Keys()) != len(s.Strings("required")) {
+			return false
+		}
+		for v := range p.Each() {
+			if !llmStrict(v) {
+				return false
+			}
+		}
+	}
+	if i := s.Select("items"); i.IsObject() {
+		return llmStrict(i)
+	}
+	return true
+}
+
+var ErrStructured = ErrCompletion.New("structured")
@@ -0,0 +1,51 @@
+package ion_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/sokool/ion"
+)
+
+func TestLLM_ClaudeTools(t *testing.T) {
+	var calls int
+	ion.Endpoints.Handler(func(r *http.Request, w *httptest.ResponseRecorder) {
+		b, _ := io.ReadAll(r.Body)
+		req := ion.JSON(b)
+		calls++
+		if calls == 1 {
+			_ = json.NewEncoder(w).Encode(ion.Meta{"content": []ion.Meta{
+				{"type": "tool_use", "id": "t1", "name": "order", "input": ion.Meta{"number": "A1"}},
+				{"type": "tool_use", "id": "t2", "name": "order", "input": ion.Meta{"number": "A2"}},
+			}})
+			return
+		}
+		// both results answer the tool_use blocks in a single user turn
+		var last ion.JSON
+		for m := range req.Each("messages") {
+			last = m
+		}
+		if last.Text("role") != "user" || last.Text("content.0.tool_use_id") != "t1" || last.Text("content.1.tool_use_id") != "t2" {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write(b)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(ion.Meta{"content": []ion.Meta{{"type": "text", "text": "done"}}})
+	}, "ANTHROPIC_URL")
+	tl, err := ion.NewTool("order", "finds order", func(q OrderQuery) (string, bool) { return q.Number, true })
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := ion.LLM{Model: "claude-test", Tool: []ion.Tool{tl}}
+	mm, err := c.Response(context.Background(), ion.Message{Role: "user", Content: "orders A1 and A2"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if calls != 2 || mm[len(mm)-1].Content != "done" {
+		t.Fatalf("expected single follow-up call, got %d calls %v", calls, mm)
+	}
+}
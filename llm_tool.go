@@ -2,12 +2,14 @@ package ion
 
 import (
 	"bufio"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"reflect"
 	"regexp"
 	"strings"
+	"time"
 
 	"github.com/alecthomas/jsonschema"
 )
@@ -15,8 +17,11 @@ import (
 type Tool struct {
 	Name string
 	// Execute is called when LLM decides to call it in LLM API
-	// bool = true make llm call again with returned string
-	Execute func(JSON) (string, bool)
+	// bool = true make llm call again with returned string, returned error
+	// is sent back to the model as the tool result and it is called again.
+	Execute FunctionCtx
+	// Timeout of a single Execute call, none when zero.
+	Timeout time.Duration
 	// Schemas represent function input json schema objects
 	Schemas []Meta
 }
@@ -27,6 +32,15 @@ type Tool struct {
 //   - markdown: string containing markdown documentation with function definitions
 //   - fn: Function handler that will be called when this tool is invoked
 func NewToolMD(markdown string, fn Function) (Tool, error) {
+	return NewToolMDCtx(markdown, func(_ context.Context, j JSON) (string, bool, error) {
+		s, ok := fn(j)
+		return s, ok, nil
+	})
+}
+
+// NewToolMDCtx is NewToolMD with a handler receiving context of the completion
+// request, so it can call Endpoints or SQL with its cancellation and tracing.
+func NewToolMDCtx(markdown string, fn FunctionCtx) (Tool, error) {
 	var err error
 	t := Tool{Execute: fn}
 	if t.Schemas, err = t.parseMD(markdown); err != nil {
@@ -36,6 +50,23 @@ func NewToolMD(markdown string, fn Function) (Tool, error) {
 }
 
 func NewTool[T any](name, desc string, fn func(T) (string, bool)) (Tool, error) {
+	return NewToolCtx(name, desc, func(_ context.Context, t T) (string, bool, error) {
+		s, ok := fn(t)
+		return s, ok, nil
+	})
+}
+
+// NewToolCtx is NewTool with a handler receiving context of the completion
+// request and returning an error, which is reported back to the model.
+//
+// Example:
+//
+//	t, err := ion.NewToolCtx("order", "finds order by its number",
+//		func(ctx context.Context, q OrderQuery) (string, bool, error) {
+//			o, err := orders.Context(ctx).Query("number", q.Number).Get()
+//			return o.String(), true, err
+//		})
+func NewToolCtx[T any](name, desc string, fn func(context.Context, T) (string, bool, error)) (Tool, error) {
 	var v T
 	var n string
 
@@ -53,13 +84,15 @@ func NewTool[T any](name, desc string, fn func(T) (string, bool)) (Tool, error)
 
 	return Tool{
 		Name: name,
-		Execute: func(j JSON) (string, bool) {
+		Execute: func(ctx context.Context, j JSON) (string, bool, error) {
 			var t T
 			if err := j.To(&t); err != nil {
-				log_.Errorf("Tool %s could not decode data to a %T type", name, t)
-				return "", false
+				return "", false, ErrTool.New("could not decode arguments to %T %w", t, err)
+			}
+			if err := Validate(t); err != nil {
+				return "", false, ErrTool.New("invalid arguments, fix them and call again %w", err)
 			}
-			return fn(t)
+			return fn(ctx, t)
 		},
 		Schemas: []Meta{
 			{
@@ -74,6 +107,49 @@ func NewTool[T any](name, desc string, fn func(T) (string, bool)) (Tool, error)
 	}, nil
 }
 
+// NewToolResult is NewTool with a handler returning a structured value,
+// sent to the model as JSON. Callers decode it back from messages of the
+// completion with ToolResult.
+//
+// Example:
+//
+//	t, err := ion.NewToolResult("order", "finds order by its number",
+//		func(q OrderQuery) (Order, bool, error) { return orders.Find(q.Number) })
+//	mm, err := l.Response(ctx, msg)
+//	o, ok, err := ion.ToolResult[Order](mm, "order")
+func NewToolResult[T, R any](name, desc string, fn func(T) (R, bool, error)) (Tool, error) {
+	return NewToolCtx(name, desc, func(_ context.Context, t T) (string, bool, error) {
+		r, ok, err := fn(t)
+		if err != nil {
+			return "", ok, err
+		}
+		b, err := json.Marshal(r)
+		if err != nil {
+			return "", false, ErrTool.New("could not encode %T result %w", r, err)
+		}
+		return string(b), ok, nil
+	})
+}
+
+// ToolResult decodes the last result of the named tool found in messages,
+// false when the tool was not called or it failed.
+func ToolResult[R any](mm []Message, name string) (R, bool, error) {
+	var r R
+	for i := len(mm) - 1; i >= 0; i-- {
+		if mm[i].Role != "function" || mm[i].Name != name {
+			continue
+		}
+		if mm[i].Meta.Bool("error") {
+			return r, false, nil
+		}
+		if err := json.Unmarshal([]byte(mm[i].Content), &r); err != nil {
+			return r, false, ErrTool.New("could not decode %s result to %T %w", name, r, err)
+		}
+		return r, true, nil
+	}
+	return r, false, nil
+}
+
 func MustLLMTool[T any](name, desc string, fn func(T) (string, bool)) Tool {
 	t, err := NewTool(name, desc, fn)
 	if err != nil {
@@ -94,6 +170,31 @@ func (t Tool) HasName(n string) bool {
 	return false
 }
 
+// run executes the tool within its Timeout.
+func (t Tool) run(ctx context.Context, data JSON) (string, bool, error) {
+	if t.Timeout <= 0 {
+		return t.Execute(ctx, data)
+	}
+	ctx, cancel := context.WithTimeout(ctx, t.Timeout)
+	defer cancel()
+	type result struct {
+		s   string
+		ok  bool
+		err error
+	}
+	r := make(chan result, 1)
+	go func() {
+		s, ok, err := t.Execute(ctx, data)
+		r <- result{s, ok, err}
+	}()
+	select {
+	case x := <-r:
+		return x.s, x.ok, x.err
+	case <-ctx.Done():
+		return "", false, ErrTool.New("timed out after %s", t.Timeout)
+	}
+}
+
 func (t Tool) MarshalJSON() ([]byte, error) {
 	return []byte(fmt.Sprintf(`{"Function":"%s", "Schemas": "%s"}`, reflect.TypeOf(t.Execute), t.Schemas)), nil
 }
@@ -195,3 +296,6 @@ func (t Tool) toJSONSchema(s string) (Meta, error) {
 var ErrTool = ErrCompletion.New("tool")
 
 type Function func(JSON) (string, bool)
+
+// FunctionCtx is a tool handler receiving context of the completion request.
+type FunctionCtx func(context.Context, JSON) (string, bool, error)
@@ -0,0 +1,42 @@
+package ion_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sokool/ion"
+)
+
+type OrderQuery struct {
+	Number string `json:"number"`
+}
+
+type Order struct {
+	Number string  `json:"number"`
+	Total  float64 `json:"total"`
+}
+
+func TestToolResult(t *testing.T) {
+	tl, err := ion.NewToolResult("order", "finds order by its number", func(q OrderQuery) (Order, bool, error) {
+		return Order{Number: q.Number, Total: 12.5}, true, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, ok, err := tl.Execute(context.Background(), ion.JSON(`{"number":"A1"}`))
+	if err != nil || !ok {
+		t.Fatalf("unexpected %v %v", ok, err)
+	}
+	mm := []ion.Message{
+		{Role: "user", Content: "where is A1"},
+		{Role: "function", Name: "order", Content: s},
+		{Role: "assistant", Content: "it costs 12.5"},
+	}
+	o, ok, err := ion.ToolResult[Order](mm, "order")
+	if err != nil || !ok || o.Number != "A1" || o.Total != 12.5 {
+		t.Fatalf("unexpected %+v %v %v", o, ok, err)
+	}
+	if _, ok, _ = ion.ToolResult[Order](mm, "invoice"); ok {
+		t.Fatal("expected no result of not called tool")
+	}
+}
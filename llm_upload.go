@@ -0,0 +1,133 @@
+package ion
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"path"
+	"strings"
+	"time"
+)
+
+// LLMFile is a file uploaded to vendor files API. Attached to a user
+// Message it is sent by reference, so large documents do not have to be
+// inlined into prompts. ID is file id for ChatGPT and Claude, file uri for
+// Gemini.
+type LLMFile struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	MIME string `json:"mime"`
+	Size int64  `json:"size"`
+}
+
+// Upload sends content of r to the vendor files API under the given name,
+// which extension tells its MIME type.
+//
+// Example:
+//
+//	f, err := l.Upload(ctx, "contract.pdf", file)
+//	m, err := l.Response(ctx, ion.Message{Role: "user", Content: "Summarize it", Files: []ion.LLMFile{f}})
+func (c *LLM) Upload(ctx context.Context, name string, r io.Reader) (LLMFile, error) {
+	f := LLMFile{Name: name, MIME: mime.TypeByExtension(path.Ext(name))}
+	if f.MIME == "" {
+		f.MIME = "application/octet-stream"
+	}
+	if i := strings.Index(f.MIME, ";"); i > 0 {
+		f.MIME = f.MIME[:i]
+	}
+	api, vendor, err := c.api()
+	if err != nil {
+		return f, ErrUpload.Wrap(err)
+	}
+
+	pr, pw := io.Pipe()
+	w := multipart.NewWriter(pw)
+	url, ctype := api.URL.Format("scheme://host:port")+"/v1/files", w.FormDataContentType()
+	if vendor == "Gemini" {
+		url, ctype = api.URL.Format("scheme://host:port")+"/upload/v1beta/files", "multipart/related; boundary="+w.Boundary()
+	}
+	go func() {
+		var p io.Writer
+		var err error
+		file := textproto.MIMEHeader{
+			"Content-Disposition": {fmt.Sprintf(`form-data; name="file"; filename=%q`, name)},
+			"Content-Type":        {f.MIME},
+		}
+		switch vendor {
+		case "Gemini":
+			if p, err = w.CreatePart(textproto.MIMEHeader{"Content-Type": {"application/json"}}); err == nil {
+				err = json.NewEncoder(p).Encode(Meta{"file": Meta{"display_name": name}})
+			}
+			if err == nil {
+				p, err = w.CreatePart(textproto.MIMEHeader{"Content-Type": {f.MIME}})
+			}
+		case "Claude":
+			p, err = w.CreatePart(file)
+		default:
+			if err = w.WriteField("purpose", "user_data"); err == nil {
+				p, err = w.CreatePart(file)
+			}
+		}
+		if err == nil {
+			_, err = io.Copy(p, r)
+		}
+		if err == nil {
+			err = w.Close()
+		}
+		pw.CloseWithError(err)
+	}()
+
+	req, err := http.NewRequestWithContext(ctx, "POST", url, pr)
+	if err != nil {
+		pr.CloseWithError(err)
+		return f, ErrUpload.Wrap(err)
+	}
+	for n, v := range api.Headers {
+		req.Header.Set(n, v)
+	}
+	req.Header.Set("Content-Type", ctype)
+	switch vendor {
+	case "Gemini":
+		req.Header.Set("X-Goog-Upload-Protocol", "multipart")
+	case "Claude":
+		req.Header.Set("anthropic-version", "2023-06-01")
+		req.Header.Set("anthropic-beta", "files-api-2025-04-14")
+	}
+
+	now := time.Now()
+	res, err := api.run(req)
+	if err != nil {
+		pr.CloseWithError(err)
+		return f, ErrUpload.Wrap(err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode >= 400 {
+		return f, ErrUpload.Wrap(llmErrors(req, res, nil))
+	}
+	b, err := io.ReadAll(res.Body)
+	if err != nil {
+		return f, ErrUpload.Wrap(err)
+	}
+	j := JSON(b)
+	switch vendor {
+	case "Gemini":
+		f.ID, f.Size = j.Text("file.uri"), j.Int64("file.sizeBytes")
+	default:
+		f.ID, f.Size = j.Text("id"), j.Int64("bytes")
+		if vendor == "Claude" {
+			f.Size = j.Int64("size_bytes")
+		}
+	}
+	if f.ID == "" {
+		return f, ErrUpload.New("%s returned no file id %.256s", vendor, b)
+	}
+	log_.Debugf("LLM %s uploaded %s as %s (%.2fkB) in %s", vendor, name, f.ID, float64(f.Size)/1024, time.Since(now))
+	return f, nil
+}
+
+var ErrUpload = ErrAI.New("upload")
@@ -0,0 +1,111 @@
+package ion
+
+import (
+	"strings"
+	"sync"
+)
+
+// Usage is a number of tokens consumed by a single completion call, attached
+// to the first message it returned. Cost is given in currency of LLMPrice,
+// zero when the model has no price, see UseLLMPrices.
+type Usage struct {
+	Model  string  `json:"model"`
+	Input  int     `json:"input"`
+	Output int     `json:"output"`
+	Cost   float64 `json:"cost,omitempty"`
+}
+
+// Add returns sum of both usages, Model is kept when both are equal.
+func (u Usage) Add(o Usage) Usage {
+	if u.Model != o.Model {
+		u.Model = ""
+		if u.Input+u.Output == 0 {
+			u.Model = o.Model
+		}
+	}
+	u.Input, u.Output, u.Cost = u.Input+o.Input, u.Output+o.Output, u.Cost+o.Cost
+	return u
+}
+
+// LLMPrice is a price of million input and output tokens.
+type LLMPrice struct {
+	Input  float64
+	Output float64
+}
+
+// UseLLMPrices sets prices used to compute Usage.Cost by model names. A name
+// matches the model exactly or as its longest prefix, so "gpt-4o" prices
+// "gpt-4o-2024-08-06" unless it is given on its own.
+//
+// Example:
+//
+//	ion.UseLLMPrices(map[string]ion.LLMPrice{
+//		"gpt-4o":      {Input: 2.5, Output: 10},
+//		"gpt-4o-mini": {Input: 0.15, Output: 0.6},
+//	})
+func UseLLMPrices(p map[string]LLMPrice) {
+	llmPricesMu.Lock()
+	defer llmPricesMu.Unlock()
+	llmPrices = p
+}
+
+// Usage returns total token usage of chat messages.
+func (c *LLMChat) Usage() Usage {
+	var u Usage
+	for _, m := range c.Messages {
+		if m.Usage != nil {
+			u = u.Add(*m.Usage)
+		}
+	}
+	return u
+}
+
+// usage reads token counts from vendor response, prices them and counts
+// them in llm_tokens_total and llm_cost_total metrics, unless the response
+// was cached, nil when the response has none.
+func (c *LLM) usage(vendor string, res JSON, cached bool) *Usage {
+	u := Usage{Model: c.Model}
+	u.Input, u.Output = llmUsed(vendor, res)
+	if u.Input+u.Output == 0 {
+		return nil
+	}
+	llmPricesMu.RLock()
+	var p LLMPrice
+	var n int
+	for m, x := range llmPrices {
+		if strings.HasPrefix(u.Model, m) && len(m) > n {
+			p, n = x, len(m)
+		}
+	}
+	llmPricesMu.RUnlock()
+	u.Cost = (float64(u.Input)*p.Input + float64(u.Output)*p.Output) / 1e6
+	if cached {
+		return &u
+	}
+	Metrics.Count("llm_tokens_total{model=%q,name=%q,type=\"input\"}", u.Input, u.Model, c.Name)
+	Metrics.Count("llm_tokens_total{model=%q,name=%q,type=\"output\"}", u.Output, u.Model, c.Name)
+	if u.Cost > 0 {
+		Metrics.Sum("llm_cost_total{model=%q,name=%q}", u.Cost, u.Model, c.Name)
+	}
+	return &u
+}
+
+// llmUsed returns input and output token counts of vendor response.
+func llmUsed(vendor string, res JSON) (int, int) {
+	switch vendor {
+	case "Gemini":
+		return res.Int("usageMetadata.promptTokenCount"), res.Int("usageMetadata.candidatesTokenCount") + res.Int("usageMetadata.thoughtsTokenCount")
+	case "Claude", "Responses":
+		return res.Int("usage.input_tokens"), res.Int("usage.output_tokens")
+	default:
+		if res.Has("usage.input_tokens") { // Responses API
+			return res.Int("usage.input_tokens"), res.Int("usage.output_tokens")
+		}
+		return res.Int("usage.prompt_tokens"), res.Int("usage.completion_tokens")
+	}
+}
+
+var (
+	llmPricesMu sync.RWMutex
+	llmPrices   map[string]LLMPrice
+)
@@ -0,0 +1,37 @@
+package ion_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sokool/ion"
+)
+
+func TestLLM_UsageCached(t *testing.T) {
+	var calls int
+	ion.Endpoints.Handler(func(r *http.Request, w *httptest.ResponseRecorder) {
+		calls++
+		_ = json.NewEncoder(w).Encode(ion.Meta{
+			"content": []ion.Meta{{"type": "text", "text": "ok"}},
+			"usage":   ion.Meta{"input_tokens": 100, "output_tokens": 50},
+		})
+	}, "ANTHROPIC_URL")
+	name := ion.UUID()[:8]
+	l := ion.LLM{Model: "claude-usage", Name: name, Cache: time.Minute}
+	for range 2 {
+		mm, err := l.Response(context.Background(), ion.Message{Role: "user", Content: "hi " + name})
+		if err != nil || mm[len(mm)-1].Usage == nil {
+			t.Fatalf("expected reply with usage, got %v %v", mm, err)
+		}
+	}
+	m := fmt.Sprintf(`llm_tokens_total{model="claude_usage",name=%q,type="input"} 100`, name)
+	if s := ion.Metrics.String(); calls != 1 || !strings.Contains(s, m) {
+		t.Fatalf("expected usage of %d calls counted once as %s in\n%s", calls, m, s)
+	}
+}
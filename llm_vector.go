@@ -0,0 +1,251 @@
+package ion
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Vector is a chunk of text with its embedding kept in a VectorStore. Score
+// is set by Query only, as cosine similarity to the queried embedding.
+type Vector struct {
+	ID        string    `json:"id"`
+	Text      string    `json:"text"`
+	Embedding []float32 `json:"embedding,omitempty"`
+	Meta      Meta      `json:"meta,omitempty"`
+	Score     float64   `json:"score,omitempty"`
+}
+
+// VectorStore keeps embedded chunks of documents for semantic search, see
+// LLMChat.Retrieve.
+type VectorStore interface {
+	// Upsert inserts vectors or replaces ones of the same ID.
+	Upsert(ctx context.Context, vv ...Vector) error
+	// Query returns up to k vectors most similar to the embedding, best first.
+	Query(ctx context.Context, embedding []float32, k int) ([]Vector, error)
+	// Delete removes vectors of given IDs, missing ones are ignored.
+	Delete(ctx context.Context, ids ...string) error
+}
+
+// NewVectorMemory creates VectorStore kept in process memory, searched by
+// comparing the query with every vector. Good for tests and small corpora.
+func NewVectorMemory() VectorStore {
+	return &vectorMemory{vectors: map[string]Vector{}}
+}
+
+// NewVectorSQL creates VectorStore kept in postgres table with pgvector
+// extension, using SQLConnection. The table is expected to exist:
+//
+//	CREATE TABLE chunks (id text PRIMARY KEY, text text, meta jsonb, embedding vector(1536));
+func NewVectorSQL(table string) (VectorStore, error) {
+	if !vectorTable.MatchString(table) {
+		return nil, ErrVector.New("invalid %q table name", table)
+	}
+	return &vectorSQL{table: table}, nil
+}
+
+// Retrieve makes chat search store for k chunks most similar to the last
+// user message before each completion and send them as a system message
+// right before it. Retrieved chunks are not kept in the chat history. Chunks
+// are embedded with Completion, ChatGPT or Gemini model, see LLM.Embed, and
+// checked by its InjectionDetector, see LLM.Detect.
+//
+// Example:
+//
+//	vv, err := l.Embed(ctx, chunks...)
+//	err = store.Upsert(ctx, ion.Vector{ID: "doc-1#1", Text: chunks[0], Embedding: vv[0]})
+//	c := ion.NewLLMChat(prompt).Retrieve(store, 5)
+func (c *LLMChat) Retrieve(store VectorStore, k int) *LLMChat {
+	c.vectors, c.k = store, k
+	return c
+}
+
+// retrieve returns mm with chunks relevant to the last user message put
+// before it, mm when retrieval is disabled or nothing was found.
+func (c *LLMChat) retrieve(ctx context.Context, mm []Message) ([]Message, error) {
+	i := len(mm) - 1
+	for i >= 0 && mm[i].Role != "user" {
+		i--
+	}
+	if c.vectors == nil || c.k <= 0 || i < 0 || strings.TrimSpace(mm[i].Content) == "" {
+		return mm, nil
+	}
+	ee, err := c.Completion.Embed(ctx, mm[i].Content)
+	if err != nil {
+		return nil, ErrVector.Wrap(err)
+	}
+	vv, err := c.vectors.Query(ctx, ee[0], c.k)
+	if err != nil {
+		return nil, ErrVector.Wrap(err)
+	}
+	if len(vv) == 0 {
+		return mm, nil
+	}
+	var s strings.Builder
+	var ids []string
+	s.WriteString(llmRetrieved)
+	for _, v := range vv {
+		if d := c.Completion.injection; d != nil {
+			if v.Text, err = d.check(ctx, "retrieval", v.Text); err != nil {
+				log_.Infof("chat %s left %s document out: %s", c.ID, v.ID, err)
+				continue
+			}
+		}
+		ids = append(ids, v.ID)
+		fmt.Fprintf(&s, "\n\n[%d] %s", len(ids), v.Text)
+	}
+	if len(ids) == 0 {
+		return mm, nil
+	}
+	Metrics.Count("llm_chat_retrieved_total{chat=%q}", len(ids), c.Name)
+	m := Message{Role: "system", Content: s.String(), Meta: Meta{"retrieved": ids}}
+	return slices.Insert(slices.Clone(mm), i, m), nil
+}
+
+type vectorMemory struct {
+	mu      sync.RWMutex
+	vectors map[string]Vector
+}
+
+func (m *vectorMemory) Upsert(_ context.Context, vv ...Vector) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, v := range vv {
+		if v.ID == "" {
+			return ErrVector.New("vector id is empty")
+		}
+		v.Score = 0
+		m.vectors[v.ID] = v
+	}
+	return nil
+}
+
+func (m *vectorMemory) Query(_ context.Context, embedding []float32, k int) ([]Vector, error) {
+	if k <= 0 {
+		return nil, nil
+	}
+	m.mu.RLock()
+	vv := make([]Vector, 0, len(m.vectors))
+	for _, v := range m.vectors {
+		v.Score = textCosine(embedding, v.Embedding)
+		vv = append(vv, v)
+	}
+	m.mu.RUnlock()
+	slices.SortFunc(vv, func(a, b Vector) int {
+		if a.Score != b.Score {
+			if a.Score > b.Score {
+				return -1
+			}
+			return 1
+		}
+		return strings.Compare(a.ID, b.ID)
+	})
+	return vv[:min(k, len(vv))], nil
+}
+
+func (m *vectorMemory) Delete(_ context.Context, ids ...string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, id := range ids {
+		delete(m.vectors, id)
+	}
+	return nil
+}
+
+type vectorSQL struct {
+	table string
+}
+
+func (s *vectorSQL) Upsert(ctx context.Context, vv ...Vector) error {
+	db, err := SQLConnection(ctx, "postgres")
+	if err != nil {
+		return ErrVector.Wrap(err)
+	}
+	q := fmt.Sprintf(`INSERT INTO %s (id, text, meta, embedding) VALUES ($1, $2, $3, $4::vector)
+		ON CONFLICT (id) DO UPDATE SET text = excluded.text, meta = excluded.meta, embedding = excluded.embedding`, s.table)
+	for _, v := range vv {
+		b, err := json.Marshal(v.Meta)
+		if err != nil {
+			return ErrVector.Wrap(err)
+		}
+		if _, err = db.ExecContext(ctx, q, v.ID, v.Text, string(b), vectorLiteral(v.Embedding)); err != nil {
+			return ErrVector.Wrap(err)
+		}
+	}
+	return nil
+}
+
+func (s *vectorSQL) Query(ctx context.Context, embedding []float32, k int) ([]Vector, error) {
+	if k <= 0 {
+		return nil, nil
+	}
+	db, err := SQLConnection(ctx, "postgres")
+	if err != nil {
+		return nil, ErrVector.Wrap(err)
+	}
+	q := fmt.Sprintf(`SELECT id, text, meta, 1 - (embedding <=> $1::vector) FROM %s ORDER BY embedding <=> $1::vector LIMIT $2`, s.table)
+	rows, err := db.QueryContext(ctx, q, vectorLiteral(embedding), k)
+	if err != nil {
+		return nil, ErrVector.Wrap(err)
+	}
+	defer rows.Close()
+	var vv []Vector
+	for rows.Next() {
+		var v Vector
+		var meta []byte
+		if err = rows.Scan(&v.ID, &v.Text, &meta, &v.Score); err != nil {
+			return nil, ErrVector.Wrap(err)
+		}
+		if len(meta) > 0 {
+			if err = json.Unmarshal(meta, &v.Meta); err != nil {
+				return nil, ErrVector.Wrap(err)
+			}
+		}
+		vv = append(vv, v)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, ErrVector.Wrap(err)
+	}
+	return vv, nil
+}
+
+func (s *vectorSQL) Delete(ctx context.Context, ids ...string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	db, err := SQLConnection(ctx, "postgres")
+	if err != nil {
+		return ErrVector.Wrap(err)
+	}
+	args := make([]any, len(ids))
+	pp := make([]string, len(ids))
+	for i := range ids {
+		args[i], pp[i] = ids[i], "$"+strconv.Itoa(i+1)
+	}
+	q := fmt.Sprintf(`DELETE FROM %s WHERE id IN (%s)`, s.table, strings.Join(pp, ", "))
+	if _, err = db.ExecContext(ctx, q, args...); err != nil {
+		return ErrVector.Wrap(err)
+	}
+	return nil
+}
+
+// vectorLiteral formats embedding as pgvector text input, ie. [0.1,0.2].
+func vectorLiteral(e []float32) string {
+	ss := make([]string, len(e))
+	for i, f := range e {
+		ss[i] = strconv.FormatFloat(float64(f), 'g', -1, 32)
+	}
+	return "[" + strings.Join(ss, ",") + "]"
+}
+
+const llmRetrieved = "Relevant context retrieved for the next message, use it when it helps to answer:"
+
+var (
+	vectorTable = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)?$`)
+	ErrVector   = ErrAI.New("vector")
+)
@@ -0,0 +1,40 @@
+package ion_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sokool/ion"
+)
+
+func TestVectorMemory(t *testing.T) {
+	ctx := context.Background()
+	s := ion.NewVectorMemory()
+	err := s.Upsert(ctx,
+		ion.Vector{ID: "a", Text: "cats", Embedding: []float32{1, 0}},
+		ion.Vector{ID: "b", Text: "dogs", Embedding: []float32{0, 1}},
+		ion.Vector{ID: "c", Text: "pets", Embedding: []float32{1, 1}},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	vv, err := s.Query(ctx, []float32{1, 0.1}, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(vv) != 2 || vv[0].ID != "a" || vv[1].ID != "c" || vv[0].Score <= vv[1].Score {
+		t.Fatalf("unexpected result %+v", vv)
+	}
+	if err = s.Delete(ctx, "a", "x"); err != nil {
+		t.Fatal(err)
+	}
+	if vv, _ = s.Query(ctx, []float32{1, 0}, 5); len(vv) != 2 || vv[0].ID != "c" {
+		t.Fatalf("unexpected result after delete %+v", vv)
+	}
+	if vv, err = s.Query(ctx, []float32{1, 0}, -1); err != nil || len(vv) != 0 {
+		t.Fatalf("expected nothing for negative k, got %+v %v", vv, err)
+	}
+	if err = s.Upsert(ctx, ion.Vector{Text: "no id"}); err == nil {
+		t.Fatal("expected error for vector without id")
+	}
+}
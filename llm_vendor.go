@@ -0,0 +1,76 @@
+package ion
+
+import (
+	"context"
+	"strings"
+	"sync"
+)
+
+// LLMVendor is a completion backend of LLM, chosen by the longest of its
+// Models prefixes matching LLM.Model, the one with no prefixes serves other
+// models. Its API is created from Env variable, see NewAPI. ChatGPT, Gemini
+// and Claude are built in, registering a vendor of the same name replaces it.
+//
+// Example:
+//
+//	ion.UseLLMVendor(ion.LLMVendor{
+//		Name:   "Mistral",
+//		Env:    "MISTRAL_URL",
+//		Models: []string{"mistral-", "codestral-"},
+//		Complete: func(c *ion.LLM, ctx context.Context, api *ion.API, m ...ion.Message) ([]ion.Message, error) {
+//			...
+//		},
+//	})
+type LLMVendor struct {
+	Name     string
+	Env      string
+	Models   []string
+	Complete func(c *LLM, ctx context.Context, api *API, m ...Message) ([]Message, error)
+}
+
+// UseLLMVendor registers completion backend.
+func UseLLMVendor(v LLMVendor) {
+	llmVendorsMu.Lock()
+	defer llmVendorsMu.Unlock()
+	for i := range llmVendors {
+		if llmVendors[i].Name == v.Name {
+			llmVendors[i] = v
+			return
+		}
+	}
+	llmVendors = append(llmVendors, v)
+}
+
+// llmVendorOf returns vendor serving the model.
+func llmVendorOf(model string) (LLMVendor, bool) {
+	llmVendorsMu.RLock()
+	defer llmVendorsMu.RUnlock()
+	var v, d LLMVendor
+	n, ok := 0, false
+	for _, x := range llmVendors {
+		if len(x.Models) == 0 && d.Name == "" {
+			d = x
+		}
+		for _, p := range x.Models {
+			if strings.HasPrefix(model, p) && len(p) > n {
+				v, n, ok = x, len(p), true
+			}
+		}
+	}
+	if !ok {
+		return d, d.Name != ""
+	}
+	return v, true
+}
+
+func init() {
+	// registered here, as vendors refer back to the registry when calling tools
+	UseLLMVendor(LLMVendor{Name: "ChatGPT", Env: "CHATGPT_URL", Complete: (*LLM).chatGPT})
+	UseLLMVendor(LLMVendor{Name: "Gemini", Env: "GEMINI_URL", Models: []string{"gemini"}, Complete: (*LLM).gemini})
+	UseLLMVendor(LLMVendor{Name: "Claude", Env: "ANTHROPIC_URL", Models: []string{"claude-"}, Complete: (*LLM).claude})
+}
+
+var (
+	llmVendorsMu sync.RWMutex
+	llmVendors   []LLMVendor
+)
@@ -17,10 +17,55 @@ func UseLocker(l Locker) {
 }
 
 // NewLocker creates and returns a sync.Locker based on the provided optional name.
-// If no name is provided or locker is nil, it defaults to using a sync.Mutex instance.
+// If no name is provided it defaults to a new sync.Mutex instance. Without
+// registered Locker, lockers of the same name share a process-local mutex.
 func NewLocker(ctx context.Context, name string) sync.Locker {
-	if locker == nil || len(name) == 0 {
+	if len(name) == 0 {
 		return &sync.Mutex{}
 	}
+	if locker == nil {
+		return &keyedLocker{name: name}
+	}
 	return locker(ctx, name)
 }
+
+// keyedLocker is process-local lock of the name, its mutex is kept while it
+// is held or awaited only.
+type keyedLocker struct {
+	name string
+	m    *keyedMutex
+}
+
+type keyedMutex struct {
+	sync.Mutex
+	refs int
+}
+
+func (l *keyedLocker) Lock() {
+	keyed.mu.Lock()
+	m, ok := keyed.locks[l.name]
+	if !ok {
+		m = &keyedMutex{}
+		keyed.locks[l.name] = m
+	}
+	m.refs++
+	keyed.mu.Unlock()
+	m.Lock()
+	l.m = m
+}
+
+func (l *keyedLocker) Unlock() {
+	m := l.m
+	l.m = nil
+	m.Unlock()
+	keyed.mu.Lock()
+	if m.refs--; m.refs == 0 {
+		delete(keyed.locks, l.name)
+	}
+	keyed.mu.Unlock()
+}
+
+var keyed = struct {
+	mu    sync.Mutex
+	locks map[string]*keyedMutex
+}{locks: make(map[string]*keyedMutex)}
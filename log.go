@@ -8,8 +8,12 @@ import (
 
 type Logger = log.Logger
 
+// NewLogger creates Logger writing to stdout, masked by the package Redactor
+// active at the time of writing, so secrets and personal data logged by
+// mistake do not leave the process, see UseRedactor. Lines without them are
+// written unchanged, UseRedactor(nil) turns masking off.
 func NewLogger(name string, traceDepth ...int) *Logger {
-	l := log.New(os.Stdout, log.All).Tag(name)
+	l := log.New(redactWriter{w: os.Stdout}, log.All).Tag(name)
 	if len(traceDepth) > 0 {
 		return l.Trace(traceDepth[0])
 	}
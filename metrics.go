@@ -25,6 +25,18 @@ func (m *metrics) Count(name string, value int, args ...any) *metrics {
 	return m
 }
 
+// Sum adds value to a float counter, for totals which are not whole numbers
+// like costs.
+func (m *metrics) Sum(name string, value float64, args ...any) *metrics {
+	m.set.GetOrCreateFloatCounter(m.toSnakeCase(name, args...)).Add(value)
+	return m
+}
+
+func (m *metrics) Gauge(name string, value float64, args ...any) *metrics {
+	m.set.GetOrCreateGauge(m.toSnakeCase(name, args...), nil).Set(value)
+	return m
+}
+
 func (m *metrics) Histogram(name string, value float64, args ...any) *metrics {
 	m.set.GetOrCreateHistogram(m.toSnakeCase(name, args...)).Update(value)
 	return m
@@ -0,0 +1,84 @@
+package ion
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/url"
+	"strconv"
+)
+
+// Page is a common pagination envelope of list endpoints.
+type Page[T any] struct {
+	// Items of the current page, encoded as [] when empty.
+	Items []T `json:"items"`
+	// Total number of items across all pages.
+	Total int `json:"total"`
+	// Next is an opaque cursor of the following page, empty on the last one.
+	Next string `json:"next,omitempty"`
+}
+
+// NewPage creates Page of items read with q, computing cursor of the next page.
+func NewPage[T any](items []T, total int, q PageQuery) Page[T] {
+	p := Page[T]{Items: items, Total: total}
+	if n := q.Offset + len(items); len(items) > 0 && n < total {
+		p.Next = PageQuery{Limit: q.Limit, Offset: n}.Cursor()
+	}
+	return p
+}
+
+// MarshalJSON ...
+func (p Page[T]) MarshalJSON() ([]byte, error) {
+	type page Page[T]
+	if p.Items == nil {
+		p.Items = []T{}
+	}
+	return json.Marshal(page(p))
+}
+
+// PageQuery describes requested page.
+type PageQuery struct {
+	Limit  int
+	Offset int
+}
+
+// PageLimits are default and maximal page sizes used by ParsePageQuery.
+var PageLimits = [2]int{20, 100}
+
+// ParsePageQuery reads `limit` and `cursor` query parameters. Missing limit
+// defaults to PageLimits[0], limit above PageLimits[1] is reduced to it.
+//
+// Example:
+//
+//	q, err := ion.ParsePageQuery(r.URL.Query())
+//	if err != nil {
+//		http.Error(w, err.Error(), http.StatusBadRequest)
+//		return
+//	}
+//	p, err := listOrders.Page(r.Context(), q, filter)
+func ParsePageQuery(v url.Values) (PageQuery, error) {
+	q := PageQuery{Limit: PageLimits[0]}
+	if s := v.Get("limit"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n <= 0 {
+			return q, ErrPage.New("limit must be a positive number, %q given", s)
+		}
+		q.Limit = min(n, PageLimits[1])
+	}
+	if s := v.Get("cursor"); s != "" {
+		b, err := base64.RawURLEncoding.DecodeString(s)
+		if err != nil {
+			return q, ErrPage.New("invalid cursor %q", s)
+		}
+		if q.Offset, err = strconv.Atoi(string(b)); err != nil || q.Offset < 0 {
+			return q, ErrPage.New("invalid cursor %q", s)
+		}
+	}
+	return q, nil
+}
+
+// Cursor returns opaque cursor pointing at the query offset.
+func (q PageQuery) Cursor() string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.Itoa(q.Offset)))
+}
+
+var ErrPage = Errorf("page")
@@ -0,0 +1,35 @@
+package ion_test
+
+import (
+	"encoding/json"
+	"net/url"
+	"testing"
+
+	"github.com/sokool/ion"
+)
+
+func TestPage(t *testing.T) {
+	q, err := ion.ParsePageQuery(url.Values{"limit": {"500"}})
+	if err != nil || q.Limit != ion.PageLimits[1] || q.Offset != 0 {
+		t.Fatalf("unexpected query %+v %v", q, err)
+	}
+	q.Limit = 2
+	p := ion.NewPage([]string{"a", "b"}, 5, q)
+	if p.Next == "" {
+		t.Fatal("expected next cursor")
+	}
+	if q, err = ion.ParsePageQuery(url.Values{"limit": {"2"}, "cursor": {p.Next}}); err != nil || q.Offset != 2 {
+		t.Fatalf("unexpected next query %+v %v", q, err)
+	}
+	if p = ion.NewPage([]string{"e"}, 5, ion.PageQuery{Limit: 2, Offset: 4}); p.Next != "" {
+		t.Fatalf("expected last page, got %s", p.Next)
+	}
+	if b, _ := json.Marshal(ion.Page[int]{}); string(b) != `{"items":[],"total":0}` {
+		t.Fatalf("unexpected json %s", b)
+	}
+	for _, v := range []url.Values{{"limit": {"-1"}}, {"limit": {"x"}}, {"cursor": {"!!"}}} {
+		if _, err = ion.ParsePageQuery(v); !ion.ErrPage.In(err) {
+			t.Fatalf("expected page error for %v, got %v", v, err)
+		}
+	}
+}
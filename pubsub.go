@@ -3,7 +3,13 @@ package ion
 import (
 	"context"
 	"encoding/json"
+	"hash/fnv"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
 	"sync"
+	"time"
 )
 
 // PubSub defines a simple interface for publish/subscribe messaging.
@@ -30,8 +36,12 @@ func UsePubSub(name string, ps PubSub) {
 // Topic provides typed publish/subscribe messaging using JSON encoding.
 // The underlying PubSub implementation is resolved via the global registry.
 type Topic[V any] struct {
-	Context context.Context
-	Name    *URL
+	Context  context.Context
+	Name     *URL
+	dead     *Topic[DeadLetter]
+	attempts int
+	drain    time.Duration
+	err      error // of MustTopic
 }
 
 // NewTopic initializes and returns a new Topic with the given context and name.
@@ -45,18 +55,81 @@ func NewTopic[V any](ctx context.Context, name string, args ...any) (*Topic[V],
 	return &Topic[V]{Context: ctx, Name: u}, nil
 }
 
-// MustTopic creates a new Topic or exits the program on failure.
+// MustTopic creates a new Topic. When name is invalid the error is passed to
+// the OnFatal handler and a Topic failing on every call is returned, use
+// NewTopic to handle the error yourself.
 func MustTopic[V any](ctx context.Context, name string, args ...any) *Topic[V] {
 	t, err := NewTopic[V](ctx, name, args...)
 	if err != nil {
-		Exit("%s", err)
+		fatal(err)
+		return &Topic[V]{Context: ctx, Name: &URL{URL: &url.URL{}}, err: err}
 	}
 	return t
 }
 
 // Write marshals v to JSON and publishes it on the topic.
 // Returns an error if marshaling fails or the message cannot be delivered.
+// Published messages, failures and publish time are counted in
+// pubsub_published_total, pubsub_publish_errors_total and
+// pubsub_publish_seconds metrics.
 func (t *Topic[V]) Write(v V) error {
+	return t.write(*t.Name, v)
+}
+
+// WriteKeyed publishes v as Write does, messages of the same key are
+// delivered in order to the same reader. Key is passed to PubSub in key
+// query parameter of the topic, and when topic has partitions query
+// parameter, ie. ?partitions=8, key hash picks one of them, passed in
+// partition parameter. Readers of topic with partition parameter receive
+// messages of that partition only, see PubSub implementations.
+//
+// Example:
+//
+//	t := ion.MustTopic[Event](ctx, "nats://orders/events?partitions=4")
+//	err := t.WriteKeyed(order.ID, Event{Order: order.ID, Type: "paid"})
+//	// reader of the second partition
+//	r := ion.MustTopic[Event](ctx, "nats://orders/events?partition=1")
+func (t *Topic[V]) WriteKeyed(key string, v V) error {
+	u := *t.Name.URL
+	q := u.Query()
+	q.Set("key", key)
+	if s := q.Get("partitions"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 1 {
+			return ErrTopic.New("partitions of %s must be a number greater than 0, %s given", t.Name.String(), s)
+		}
+		h := fnv.New32a()
+		h.Write([]byte(key))
+		q.Set("partition", strconv.Itoa(int(h.Sum32()%uint32(n))))
+	}
+	u.RawQuery = q.Encode()
+	return t.write(URL{URL: &u}, v)
+}
+
+// Group makes readers of the topic members of the named group, each message
+// is delivered to one member only, so many readers, in one or many
+// processes, share work. Group is passed to PubSub in group query parameter
+// of the topic. In-memory implementation shares messages between members
+// round robin, keeping keyed messages with one member, NATS uses queue
+// groups and Kafka consumer groups, Redis pub/sub does not support them.
+//
+// Example:
+//
+//	t := ion.MustTopic[Order](ctx, "nats://orders/created").Group("billing")
+//	for o := range t.Read(&err) {
+//		...
+//	}
+func (t *Topic[V]) Group(name string) *Topic[V] {
+	u := *t.Name.URL
+	q := u.Query()
+	q.Set("group", name)
+	u.RawQuery = q.Encode()
+	t.Name = &URL{URL: &u}
+	return t
+}
+
+// write publishes v to the topic of the given name.
+func (t *Topic[V]) write(name URL, v V) error {
 	ps, err := t.pubSub()
 	if err != nil {
 		return err
@@ -69,32 +142,47 @@ func (t *Topic[V]) Write(v V) error {
 	if cx == nil {
 		cx = ctx
 	}
-	return ps.Publish(cx, *t.Name, b)
+	now, n := time.Now(), pubsubName(*t.Name)
+	if err = ps.Publish(cx, name, b); err != nil {
+		Metrics.Count("pubsub_publish_errors_total{topic=%q}", 1, n)
+		return err
+	}
+	Metrics.
+		Count("pubsub_published_total{topic=%q}", 1, n).
+		Percentile("pubsub_publish_seconds{topic=%q}", time.Since(now).Seconds(), n)
+	return nil
 }
 
 // Read subscribes to the topic and returns a channel for receiving decoded messages.
 // If an error occurs, it is set in the provided error pointer and nil is returned.
+// Messages failing to decode are sent to dead letter topic, when set, otherwise
+// the error is set and the channel is closed. Read messages are counted in
+// pubsub_consumed_total metric and pubsub_lag gauge tells how many wait in
+// subscription buffer, when PubSub buffers them. Channel is closed when topic
+// Context or global Context is done, after drain period, see Topic.Drain.
 func (t *Topic[V]) Read(err *error) <-chan V {
 	ps, er := t.pubSub()
 	if er != nil {
 		*err = er
 		return nil
 	}
-	cx := t.Context
-	if cx == nil {
-		cx = ctx
-	}
+	cx := t.subscription()
 	bch, er := ps.Subscribe(cx, *t.Name)
 	if er != nil {
 		*err = er
 		return nil
 	}
-	vch := make(chan V)
+	vch, n, d := make(chan V), pubsubName(*t.Name), newPubSubDrainer(cx)
 	go func() {
+		defer d.close()
 		defer close(vch)
 		for {
 			select {
-			case <-ctx.Done():
+			case <-d.done:
+				if d.stop() {
+					return
+				}
+			case <-d.expired:
 				return
 			case b, ok := <-bch:
 				if !ok {
@@ -102,10 +190,27 @@ func (t *Topic[V]) Read(err *error) <-chan V {
 				}
 				var v V
 				if er := json.Unmarshal(b, &v); er != nil {
+					if t.deadLetter(b, er, 1) {
+						continue
+					}
 					*err = er
 					return
 				}
-				vch <- v
+				Metrics.
+					Count("pubsub_consumed_total{topic=%q}", 1, n).
+					Gauge("pubsub_lag{topic=%q}", float64(len(bch)), n)
+				for sent := false; !sent; {
+					select {
+					case vch <- v:
+						sent = true
+					case <-d.done:
+						if d.stop() {
+							return
+						}
+					case <-d.expired:
+						return
+					}
+				}
 			}
 		}
 	}()
@@ -114,9 +219,16 @@ func (t *Topic[V]) Read(err *error) <-chan V {
 
 // pubSub returns the PubSub implementation matching vendor.
 // If only one is registered, it is used. Returns an error if none are found or multiple exist and vendor is empty.
+// The memory vendor is in-memory implementation, unless registered under that name.
 func (t *Topic[V]) pubSub() (PubSub, error) {
+	if t.err != nil {
+		return nil, t.err
+	}
 	pubsubsMu.RLock()
 	defer pubsubsMu.RUnlock()
+	if _, ok := pubsubs["memory"]; !ok && t.Name.Scheme == "memory" {
+		return pubsubMem, nil
+	}
 	if t.Name.Scheme == "" {
 		switch len(pubsubs) {
 		case 0:
@@ -137,51 +249,283 @@ func (t *Topic[V]) pubSub() (PubSub, error) {
 // pubsub in-memory implementation
 type pubSub struct {
 	mu     sync.RWMutex
-	topics map[string][]chan []byte
+	gmu    sync.Mutex
+	topics map[string][]*pubsubSub
+	queues map[string][]*pubsubQueue
+	next   map[string]uint32 // round robin of groups
+}
+
+// pubsubSub is in-memory subscription with buffer of messages and policy of
+// its overflow.
+type pubsubSub struct {
+	mu        sync.RWMutex
+	closed    bool
+	ctx       context.Context
+	ch        chan []byte
+	overflow  string
+	partition string
+	group     string
+	// replaying subscription keeps messages published meanwhile in pending,
+	// those numbered up to last replayed one are skipped as duplicates
+	replaying bool
+	pmu       sync.Mutex
+	pending   []pubsubLive
+	last      int64
+}
+
+// pubsubLive is a message published to the topic with its number in the
+// topic history, 0 when it is not kept.
+type pubsubLive struct {
+	seq int64
+	msg []byte
 }
 
 func (m *pubSub) Publish(ctx context.Context, topic URL, msg []byte) error {
+	name := pubsubName(topic)
+	// recorded before subscriptions are taken, so new subscriber either
+	// replays msg or receives it, see pubsubSub.replayed
+	seq, kept, err := m.record(ctx, topic, msg)
+	if err != nil {
+		return err
+	}
 	m.mu.RLock()
-	subs := m.topics[topic.Path]
-	m.mu.RUnlock()
-	if len(subs) == 0 {
+	subs, queues := m.topics[name], m.queues[name]
+	if len(subs) == 0 && len(queues) == 0 && !kept {
+		m.mu.RUnlock()
 		return Errorf("no subscribers for %s topic", topic)
 	}
-	for _, ch := range subs {
+	// sent after unlock, so subscriber blocking Publish does not block others
+	rr := m.receivers(topic, subs, queues)
+	m.mu.RUnlock()
+	for _, r := range rr {
+		if r.queue != nil {
+			r.queue.push(msg, seq)
+			continue
+		}
+		dropped, e := r.sub.send(ctx, topic, msg, seq)
+		switch {
+		case e != nil && ctx.Err() != nil:
+			return e
+		case e != nil:
+			err = e
+		case dropped:
+			Metrics.Count("pubsub_dropped_total{topic=%q,overflow=%q}", 1, name, r.sub.overflow)
+		}
+	}
+	return err
+}
+
+// send delivers msg to the subscription, following its overflow policy when
+// the buffer is full, reports whether msg was dropped.
+func (s *pubsubSub) send(ctx context.Context, topic URL, msg []byte, seq int64) (bool, error) {
+	// held while sending, so the subscription is not closed meanwhile
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.closed || seq > 0 && seq <= s.last {
+		return false, nil
+	}
+	if s.replaying {
+		s.pmu.Lock()
+		s.pending = append(s.pending, pubsubLive{seq: seq, msg: msg})
+		s.pmu.Unlock()
+		return false, nil
+	}
+	select {
+	case s.ch <- msg:
+		return false, nil
+	default:
+	}
+	switch s.overflow {
+	case "block":
 		select {
-		case ch <- msg:
+		case s.ch <- msg:
+		case <-s.ctx.Done():
+		case <-ctx.Done():
+			return false, ErrTopic.Wrap(ctx.Err())
+		}
+		return false, nil
+	case "drop-oldest":
+		select {
+		case <-s.ch:
 		default:
-			// Drop if subscriber is slow; do not block.
 		}
+		select {
+		case s.ch <- msg:
+		default:
+		}
+	case "error":
+		return false, ErrTopic.New("subscriber buffer of %s topic is full", topic.String())
 	}
-	return nil
+	return true, nil
+}
+
+// replayed gives the subscription past messages, up to the last numbered
+// one, followed by those published meanwhile.
+func (s *pubsubSub) replayed(bb [][]byte, last int64, buffer int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	pp := slices.DeleteFunc(s.pending, func(p pubsubLive) bool { return p.seq > 0 && p.seq <= last })
+	s.ch = make(chan []byte, max(buffer, len(bb)+len(pp)))
+	for _, b := range bb {
+		s.ch <- b
+	}
+	for _, p := range pp {
+		s.ch <- p.msg
+	}
+	s.replaying, s.pending, s.last = false, nil, last
 }
 
+// close closes channel of the subscription, once its senders are done.
+func (s *pubsubSub) close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	close(s.ch)
+}
+
+// Subscribe returns channel buffering up to buffer query parameter of the
+// topic messages, 64 by default. Overflow query parameter tells what happens
+// when the buffer is full:
+//
+//   - drop-new (default), the message is dropped
+//   - drop-oldest, the oldest buffered message is dropped for the new one
+//   - block, Publish waits until subscriber takes a message
+//   - error, Publish fails, other subscribers get the message
+//
+// Dropped messages are counted in pubsub_dropped_total metric. Messages read
+// with Topic.ReadAck are never dropped.
+//
+// Subscriber of topic with partition query parameter receives messages of
+// that partition, written with Topic.WriteKeyed, and those without one.
+// Subscribers of topic with the same group query parameter share messages,
+// each is delivered to one of them, see Topic.Group.
+//
+// Messages published to topic with history query parameter are kept in
+// Store, up to its number, and given first to subscribers of topic with
+// replay or since query parameter, ie. ?replay=10 or ?since=1h, buffer grows
+// to fit them.
 func (m *pubSub) Subscribe(ctx context.Context, topic URL) (<-chan []byte, error) {
-	ch := make(chan []byte)
+	n := 64
+	if q := topic.Query("buffer"); q != "" {
+		var err error
+		if n, err = strconv.Atoi(q); err != nil || n < 0 {
+			return nil, ErrTopic.New("buffer of %s must be a number not less than 0, %s given", topic.String(), q)
+		}
+	}
+	o := topic.Query("overflow")
+	if o == "" {
+		o = "drop-new"
+	}
+	if !slices.Contains([]string{"drop-new", "drop-oldest", "block", "error"}, o) {
+		return nil, ErrTopic.New("overflow of %s must be drop-new, drop-oldest, block or error, %s given", topic.String(), o)
+	}
+	r, since, err := pubsubReplay(topic)
+	if err != nil {
+		return nil, err
+	}
+	sub := &pubsubSub{ctx: ctx, overflow: o, partition: topic.Query("partition"), group: topic.Query("group"), replaying: r > 0 || !since.IsZero()}
+	if !sub.replaying {
+		sub.ch = make(chan []byte, n)
+	}
+	// subscribed before replay, so no message published meanwhile is missed,
+	// history is read without lock, so slow Store does not block Publish
+	name := pubsubName(topic)
 	m.mu.Lock()
-	m.topics[topic.Path] = append(m.topics[topic.Path], ch)
+	if m.topics == nil {
+		m.topics = make(map[string][]*pubsubSub)
+	}
+	m.topics[name] = append(m.topics[name], sub)
 	m.mu.Unlock()
+	if sub.replaying {
+		bb, last, err := m.replay(ctx, topic, r, since)
+		if err != nil {
+			m.unsubscribe(name, sub)
+			return nil, err
+		}
+		sub.replayed(bb, last, n)
+	}
 	go func() {
 		<-ctx.Done()
-		m.mu.Lock()
-		// Remove ch from m.topics[topic]
-		subs := m.topics[topic.Path]
-		for i, c := range subs {
-			if c == ch {
-				m.topics[topic.Path] = append(subs[:i], subs[i+1:]...)
-				break
+		m.unsubscribe(name, sub)
+		sub.close()
+	}()
+	return sub.ch, nil
+}
+
+func (m *pubSub) unsubscribe(name string, sub *pubsubSub) {
+	m.mu.Lock()
+	m.topics[name] = slices.DeleteFunc(m.topics[name], func(s *pubsubSub) bool { return s == sub })
+	m.mu.Unlock()
+}
+
+// pubsubReceiver is in-memory subscription, plain or with acknowledgements.
+type pubsubReceiver struct {
+	sub   *pubsubSub
+	queue *pubsubQueue
+}
+
+// receivers returns subscriptions message of the topic is delivered to, those
+// of its partition, and one of each group, chosen by key hash of keyed
+// messages, so they keep order, or round robin.
+func (m *pubSub) receivers(topic URL, subs []*pubsubSub, queues []*pubsubQueue) []pubsubReceiver {
+	p := topic.Query("partition")
+	var rr []pubsubReceiver
+	groups := map[string][]pubsubReceiver{}
+	for _, s := range subs {
+		if !pubsubPartition(p, s.partition) {
+			continue
+		}
+		if s.group == "" {
+			rr = append(rr, pubsubReceiver{sub: s})
+			continue
+		}
+		groups[s.group] = append(groups[s.group], pubsubReceiver{sub: s})
+	}
+	for _, q := range queues {
+		if !pubsubPartition(p, q.partition) {
+			continue
+		}
+		if q.group == "" {
+			rr = append(rr, pubsubReceiver{queue: q})
+			continue
+		}
+		groups[q.group] = append(groups[q.group], pubsubReceiver{queue: q})
+	}
+	for g, gg := range groups {
+		var i uint32
+		if k := topic.Query("key"); k != "" {
+			h := fnv.New32a()
+			h.Write([]byte(k))
+			i = h.Sum32()
+		} else {
+			m.gmu.Lock()
+			if m.next == nil {
+				m.next = make(map[string]uint32)
 			}
+			k := pubsubName(topic) + "#" + g
+			i = m.next[k]
+			m.next[k]++
+			m.gmu.Unlock()
 		}
-		m.mu.Unlock()
-		close(ch)
-	}()
-	return ch, nil
+		rr = append(rr, gg[i%uint32(len(gg))])
+	}
+	return rr
+}
+
+// pubsubPartition tells whether message of the partition is delivered to
+// subscriber of the subscribed one, all are when either is not given.
+func pubsubPartition(partition, subscribed string) bool {
+	return partition == "" || subscribed == "" || partition == subscribed
+}
+
+// pubsubName returns name of the topic in metrics, its host and path.
+func pubsubName(topic URL) string {
+	return strings.Trim(topic.Host+topic.Path, "/")
 }
 
 var (
 	ErrTopic  = Errorf("pubsub:topic")
-	pubsubMem = &pubSub{topics: make(map[string][]chan []byte)}
+	pubsubMem = &pubSub{}
 	// pubsubsMu guards access to the global pubsubs registry.
 	pubsubsMu sync.RWMutex
 	pubsubs   = make(map[string]PubSub)
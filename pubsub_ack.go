@@ -0,0 +1,335 @@
+package ion
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Envelope carries a message read with acknowledgement. Ack confirms it was
+// handled, Nack, Fail or no answer within ack timeout of the topic makes
+// PubSub redeliver it, when it supports redelivery, see AckPubSub. Attempt
+// counts deliveries of the message, starting from 1.
+type Envelope[V any] struct {
+	Value   V
+	Attempt int
+	done    func(ok bool, err error)
+}
+
+// Ack confirms the message was handled, it is not delivered again.
+func (e Envelope[V]) Ack() { e.settle(true, nil) }
+
+// Nack rejects the message, so it is delivered again.
+func (e Envelope[V]) Nack() { e.settle(false, nil) }
+
+// Fail rejects the message as Nack does, err is the reason kept in
+// DeadLetter when the message is given up, see Topic.DeadLetter.
+func (e Envelope[V]) Fail(err error) { e.settle(false, err) }
+
+func (e Envelope[V]) settle(ok bool, err error) {
+	if e.done != nil {
+		e.done(ok, err)
+	}
+}
+
+// AckPubSub is PubSub delivering messages at least once, next message of the
+// subscription is delivered after the previous is acknowledged, the one
+// rejected or not acknowledged within ack timeout is delivered again.
+type AckPubSub interface {
+	PubSub
+
+	// SubscribeAck registers a new subscriber for the topic, receiving
+	// messages in envelopes to acknowledge. Channel is closed when ctx is
+	// canceled.
+	SubscribeAck(ctx context.Context, topic URL) (<-chan Envelope[[]byte], error)
+}
+
+// ReadAck subscribes to the topic and returns a channel of decoded messages
+// to acknowledge. Messages are delivered at least once when PubSub of the
+// topic is AckPubSub, otherwise Ack and Nack do nothing. Ack query parameter
+// of topic name sets ack timeout, ie. ?ack=1m, 30s by default. Messages are
+// given up to dead letter topic, when set, see Topic.DeadLetter. Otherwise,
+// on decoding failure the message is rejected, the error is set in the
+// provided error pointer and the channel is closed. Read messages, rejections
+// and time from reading to answer are counted in pubsub_consumed_total,
+// pubsub_rejected_total and pubsub_handle_seconds metrics.
+//
+// Example:
+//
+//	for e := range t.ReadAck(&err) {
+//		if err := ship(e.Value); err != nil {
+//			e.Nack()
+//			continue
+//		}
+//		e.Ack()
+//	}
+func (t *Topic[V]) ReadAck(err *error) <-chan Envelope[V] {
+	ps, er := t.pubSub()
+	if er != nil {
+		*err = er
+		return nil
+	}
+	cx := t.subscription()
+	var bch <-chan Envelope[[]byte]
+	a, redelivers := ps.(AckPubSub)
+	if redelivers {
+		bch, er = a.SubscribeAck(cx, *t.Name)
+	} else {
+		bch, er = pubsubEnvelopes(cx, ps, *t.Name)
+	}
+	if er != nil {
+		*err = er
+		return nil
+	}
+	vch, n, d := make(chan Envelope[V]), pubsubName(*t.Name), newPubSubDrainer(cx)
+	go func() {
+		defer d.close()
+		defer close(vch)
+		for {
+			select {
+			case <-d.done:
+				if d.stop() {
+					return
+				}
+			case <-d.expired:
+				return
+			case e, ok := <-bch:
+				if !ok {
+					return
+				}
+				if t.dead != nil && e.Attempt > t.attempts && t.deadLetter(e.Value, ErrTopic.New("not acknowledged"), e.Attempt-1) {
+					e.Ack()
+					continue
+				}
+				var v V
+				if er := json.Unmarshal(e.Value, &v); er != nil {
+					if t.deadLetter(e.Value, er, e.Attempt) {
+						e.Ack()
+						continue
+					}
+					e.Nack()
+					*err = er
+					return
+				}
+				now := time.Now()
+				x := Envelope[V]{Value: v, Attempt: e.Attempt, done: func(ok bool, er error) {
+					Metrics.Percentile("pubsub_handle_seconds{topic=%q}", time.Since(now).Seconds(), n)
+					if !ok {
+						Metrics.Count("pubsub_rejected_total{topic=%q}", 1, n)
+					}
+					if !ok && (e.Attempt >= t.attempts || !redelivers) && t.deadLetter(e.Value, er, e.Attempt) {
+						ok = true
+					}
+					e.settle(ok, er)
+				}}
+				Metrics.Count("pubsub_consumed_total{topic=%q}", 1, n)
+				for sent := false; !sent; {
+					select {
+					case vch <- x:
+						sent = true
+					case <-d.done:
+						if d.stop() {
+							e.Nack()
+							return
+						}
+					case <-d.expired:
+						e.Nack()
+						return
+					}
+				}
+			}
+		}
+	}()
+	return vch
+}
+
+// pubsubEnvelopes subscribes to PubSub without acknowledgements, wrapping
+// its messages in envelopes with no effect of Ack and Nack.
+func pubsubEnvelopes(ctx context.Context, ps PubSub, topic URL) (<-chan Envelope[[]byte], error) {
+	bch, err := ps.Subscribe(ctx, topic)
+	if err != nil {
+		return nil, err
+	}
+	ch, dctx := make(chan Envelope[[]byte]), pubsubDrained(ctx)
+	go func() {
+		defer close(ch)
+		for b := range bch {
+			select {
+			case ch <- Envelope[[]byte]{Value: b, Attempt: 1}:
+			case <-dctx.Done():
+				return
+			}
+		}
+	}()
+	return ch, nil
+}
+
+// pubsubDeliver sends message to the subscriber and waits for its answer,
+// true when acknowledged. PubSub passes context of pubsubDrained, so answers
+// are awaited during drain period of the topic.
+func pubsubDeliver(ctx context.Context, ch chan<- Envelope[[]byte], topic URL, msg []byte, attempt int, timeout time.Duration) bool {
+	ack := make(chan bool, 1)
+	e := Envelope[[]byte]{Value: msg, Attempt: attempt, done: func(ok bool, _ error) {
+		// only the first answer counts
+		select {
+		case ack <- ok:
+		default:
+		}
+	}}
+	select {
+	case ch <- e:
+	case <-ctx.Done():
+		return false
+	}
+	t := time.NewTimer(timeout)
+	defer t.Stop()
+	select {
+	case ok := <-ack:
+		return ok
+	case <-t.C:
+		log_.Debugf("%s message not acknowledged in %s, redelivering", topic.String(), timeout)
+		return false
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// pubsubAckTimeout returns ack timeout of the topic from its ack query
+// parameter, 30s by default.
+func pubsubAckTimeout(topic URL) (time.Duration, error) {
+	s := topic.Query("ack")
+	if s == "" {
+		return 30 * time.Second, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d <= 0 {
+		return 0, ErrTopic.New("ack of %s must be a positive duration, %s given", topic.String(), s)
+	}
+	return d, nil
+}
+
+// SubscribeAck queues messages of the subscriber, none is dropped when it is
+// slow, and delivers them one by one until acknowledged. Past messages are
+// replayed first as in Subscribe.
+func (m *pubSub) SubscribeAck(ctx context.Context, topic URL) (<-chan Envelope[[]byte], error) {
+	timeout, err := pubsubAckTimeout(topic)
+	if err != nil {
+		return nil, err
+	}
+	r, since, err := pubsubReplay(topic)
+	if err != nil {
+		return nil, err
+	}
+	q := &pubsubQueue{wake: make(chan struct{}, 1), partition: topic.Query("partition"), group: topic.Query("group"), replaying: r > 0 || !since.IsZero()}
+	// queued before replay, as in Subscribe
+	name := pubsubName(topic)
+	m.mu.Lock()
+	if m.queues == nil {
+		m.queues = make(map[string][]*pubsubQueue)
+	}
+	m.queues[name] = append(m.queues[name], q)
+	m.mu.Unlock()
+	unqueue := func() {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		m.queues[name] = slices.DeleteFunc(m.queues[name], func(x *pubsubQueue) bool { return x == q })
+	}
+	if q.replaying {
+		bb, last, err := m.replay(ctx, topic, r, since)
+		if err != nil {
+			unqueue()
+			return nil, err
+		}
+		q.replayed(bb, last)
+	}
+	ch := make(chan Envelope[[]byte])
+	go func() {
+		defer close(ch)
+		defer unqueue()
+		dctx := pubsubDrained(ctx)
+		for n := 1; ; n++ {
+			b, ok := q.peek(dctx)
+			if !ok {
+				return
+			}
+			if pubsubDeliver(dctx, ch, topic, b, n, timeout) {
+				q.pop()
+				n = 0
+			}
+		}
+	}()
+	return ch, nil
+}
+
+// pubsubQueue is unbounded message queue of in-memory subscriber.
+type pubsubQueue struct {
+	mu        sync.Mutex
+	msgs      [][]byte
+	wake      chan struct{}
+	partition string
+	group     string
+	// as in pubsubSub
+	replaying bool
+	pending   []pubsubLive
+	last      int64
+}
+
+func (q *pubsubQueue) push(b []byte, seq int64) {
+	q.mu.Lock()
+	switch {
+	case seq > 0 && seq <= q.last:
+		q.mu.Unlock()
+		return
+	case q.replaying:
+		q.pending = append(q.pending, pubsubLive{seq: seq, msg: b})
+		q.mu.Unlock()
+		return
+	}
+	q.msgs = append(q.msgs, b)
+	q.mu.Unlock()
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+}
+
+// replayed queues past messages, up to the last numbered one, followed by
+// those published meanwhile.
+func (q *pubsubQueue) replayed(bb [][]byte, last int64) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.msgs = bb
+	for _, p := range q.pending {
+		if p.seq == 0 || p.seq > last {
+			q.msgs = append(q.msgs, p.msg)
+		}
+	}
+	q.replaying, q.pending, q.last = false, nil, last
+}
+
+// peek waits for the first message, false when ctx is done.
+func (q *pubsubQueue) peek(ctx context.Context) ([]byte, bool) {
+	for {
+		q.mu.Lock()
+		if len(q.msgs) > 0 {
+			b := q.msgs[0]
+			q.mu.Unlock()
+			return b, true
+		}
+		q.mu.Unlock()
+		select {
+		case <-q.wake:
+		case <-ctx.Done():
+			return nil, false
+		}
+	}
+}
+
+func (q *pubsubQueue) pop() {
+	q.mu.Lock()
+	q.msgs[0] = nil
+	q.msgs = q.msgs[1:]
+	q.mu.Unlock()
+}
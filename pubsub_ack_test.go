@@ -0,0 +1,56 @@
+package ion_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/sokool/ion"
+)
+
+func TestTopic_ReadAck(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	topic := ion.MustTopic[int](ctx, "memory://orders/ack?ack=50ms")
+	var err error
+	ch := topic.ReadAck(&err)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// nothing is dropped, while the subscriber is busy
+	for i := range 3 {
+		if err = topic.Write(i); err != nil {
+			t.Fatal(err)
+		}
+	}
+	next := func() ion.Envelope[int] {
+		select {
+		case e := <-ch:
+			return e
+		case <-time.After(time.Second):
+			t.Fatal("message not received")
+		}
+		return ion.Envelope[int]{}
+	}
+	if e := next(); e.Value != 0 || e.Attempt != 1 {
+		t.Fatalf("unexpected %+v", e)
+	} else {
+		e.Nack()
+	}
+	if e := next(); e.Value != 0 || e.Attempt != 2 {
+		t.Fatalf("expected redelivery of rejected, got %+v", e)
+	}
+	// not acknowledged in time
+	if e := next(); e.Value != 0 || e.Attempt != 3 {
+		t.Fatalf("expected redelivery after ack timeout, got %+v", e)
+	} else {
+		e.Ack()
+	}
+	for i := 1; i < 3; i++ {
+		if e := next(); e.Value != i || e.Attempt != 1 {
+			t.Fatalf("expected %d, got %+v", i, e)
+		} else {
+			e.Ack()
+		}
+	}
+}
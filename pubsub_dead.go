@@ -0,0 +1,49 @@
+package ion
+
+import (
+	"time"
+)
+
+// DeadLetter is a message given up by subscriber of the topic, as it failed
+// to decode or its handling failed the number of attempts.
+type DeadLetter struct {
+	Topic    string    `json:"topic"`
+	Message  string    `json:"message"`
+	Error    string    `json:"error"`
+	Attempts int       `json:"attempts"`
+	Time     time.Time `json:"time"`
+}
+
+// DeadLetter sets topic receiving messages of t given up by its readers,
+// after they failed to decode, or were rejected with Nack or Fail of
+// Topic.ReadAck the number of attempts, 5 by default. Messages of PubSub not
+// redelivering them, see AckPubSub, are given up when rejected once.
+//
+// Example:
+//
+//	dlq := ion.MustTopic[ion.DeadLetter](ctx, "nats://orders/dead")
+//	t := ion.MustTopic[Order](ctx, "nats://orders/created").DeadLetter(dlq, 3)
+func (t *Topic[V]) DeadLetter(d *Topic[DeadLetter], attempts ...int) *Topic[V] {
+	t.dead, t.attempts = d, 5
+	if len(attempts) > 0 && attempts[0] > 0 {
+		t.attempts = attempts[0]
+	}
+	return t
+}
+
+// deadLetter sends msg to dead letter topic, true when sent.
+func (t *Topic[V]) deadLetter(msg []byte, err error, attempts int) bool {
+	if t.dead == nil {
+		return false
+	}
+	d := DeadLetter{Topic: t.Name.String(), Message: string(msg), Attempts: attempts, Time: time.Now()}
+	if d.Error = "rejected"; err != nil {
+		d.Error = err.Error()
+	}
+	if err := t.dead.Write(d); err != nil {
+		log_.Errorf("dead letter of %s topic not sent: %s", t.Name.String(), err)
+		return false
+	}
+	Metrics.Count("pubsub_dead_letters_total{topic=%q}", 1, pubsubName(*t.Name))
+	return true
+}
@@ -0,0 +1,59 @@
+package ion_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sokool/ion"
+)
+
+func TestTopic_DeadLetter(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	dlq := ion.MustTopic[ion.DeadLetter](ctx, "memory://orders/dead")
+	var err error
+	dead := dlq.Read(&err)
+	topic := ion.MustTopic[int](ctx, "memory://orders/placed").DeadLetter(dlq, 2)
+	ch := topic.ReadAck(&err)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = ion.MustTopic[string](ctx, "memory://orders/placed").Write("x"); err != nil {
+		t.Fatal(err)
+	}
+	if err = topic.Write(7); err != nil {
+		t.Fatal(err)
+	}
+	for i := range 2 {
+		select {
+		case e := <-ch:
+			if e.Value != 7 || e.Attempt != i+1 {
+				t.Fatalf("unexpected %+v", e)
+			}
+			e.Fail(errors.New("out of stock"))
+		case <-time.After(time.Second):
+			t.Fatal("message not received")
+		}
+	}
+	for _, want := range []ion.DeadLetter{
+		{Message: `"x"`, Attempts: 1},
+		{Message: "7", Attempts: 2, Error: "out of stock"},
+	} {
+		select {
+		case d := <-dead:
+			if d.Topic != "memory://orders/placed" || d.Message != want.Message || d.Attempts != want.Attempts {
+				t.Fatalf("unexpected %+v", d)
+			}
+			if want.Error != "" && d.Error != want.Error {
+				t.Fatalf("expected %s error, got %s", want.Error, d.Error)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("dead letter not received")
+		}
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+}
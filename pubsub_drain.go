@@ -0,0 +1,111 @@
+package ion
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Drain sets period readers of the topic are given on cancellation of topic
+// Context, or global Context on shutdown, to finish messages in flight.
+// Meanwhile Read and ReadAck keep delivering messages buffered by PubSub and
+// acknowledgements of messages being handled are awaited, then channels are
+// closed. Exit waits for draining readers.
+//
+// Example:
+//
+//	t := ion.MustTopic[Order](ctx, "nats://orders").Drain(5 * time.Second)
+func (t *Topic[V]) Drain(d time.Duration) *Topic[V] {
+	t.drain = d
+	return t
+}
+
+// subscription returns context of topic readers, done with topic Context or
+// global one, carrying drain period for PubSub.
+func (t *Topic[V]) subscription() context.Context {
+	cx := t.Context
+	if cx == nil {
+		cx = ctx
+	}
+	if cx != ctx {
+		var cancel context.CancelFunc
+		cx, cancel = context.WithCancel(cx)
+		context.AfterFunc(ctx, cancel)
+	}
+	return context.WithValue(cx, pubsubDrainKey{}, t.drain)
+}
+
+// pubsubDrained returns context done drain period after ctx, which PubSub
+// uses to deliver messages it holds and wait for their acknowledgements.
+func pubsubDrained(ctx context.Context) context.Context {
+	d, _ := ctx.Value(pubsubDrainKey{}).(time.Duration)
+	if d <= 0 {
+		return ctx
+	}
+	cx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	context.AfterFunc(ctx, func() { time.AfterFunc(d, cancel) })
+	return cx
+}
+
+// pubsubDrainer tells reader of the topic when to stop, at once when done
+// without drain period, otherwise when the period expires.
+type pubsubDrainer struct {
+	done    <-chan struct{}
+	expired <-chan time.Time
+	period  time.Duration
+}
+
+func newPubSubDrainer(ctx context.Context) *pubsubDrainer {
+	d, _ := ctx.Value(pubsubDrainKey{}).(time.Duration)
+	if d > 0 && !pubsubDrains.add() {
+		d = 0 // Exit started, not waiting for the reader
+	}
+	return &pubsubDrainer{done: ctx.Done(), period: d}
+}
+
+// stop is called when done, true when reader stops at once.
+func (d *pubsubDrainer) stop() bool {
+	if d.period <= 0 {
+		return true
+	}
+	d.done, d.expired = nil, time.After(d.period)
+	return false
+}
+
+// close is called when reader stops.
+func (d *pubsubDrainer) close() {
+	if d.period > 0 {
+		pubsubDrains.wg.Done()
+	}
+}
+
+type pubsubDrainKey struct{}
+
+// pubsubDrainGroup tracks readers with drain period, awaited by Exit, no
+// new ones are tracked once it waits, so Add does not race with Wait.
+type pubsubDrainGroup struct {
+	mu      sync.Mutex
+	wg      sync.WaitGroup
+	waiting bool
+}
+
+// add tracks a reader, false when Exit already waits.
+func (g *pubsubDrainGroup) add() bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.waiting {
+		return false
+	}
+	g.wg.Add(1)
+	return true
+}
+
+// wait stops tracking new readers and waits for tracked ones.
+func (g *pubsubDrainGroup) wait() {
+	g.mu.Lock()
+	g.waiting = true
+	g.mu.Unlock()
+	g.wg.Wait()
+}
+
+var pubsubDrains pubsubDrainGroup
@@ -0,0 +1,42 @@
+package ion_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/sokool/ion"
+)
+
+func TestTopic_Drain(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	topic := ion.MustTopic[int](ctx, "memory://drain/orders").Drain(time.Second)
+	var err error
+	ch := topic.Read(&err)
+	for i := range 3 {
+		if err = topic.Write(i); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cancel()
+	time.Sleep(10 * time.Millisecond)
+	// buffered messages are delivered after cancellation
+	for i := range 3 {
+		select {
+		case v, ok := <-ch:
+			if !ok || v != i {
+				t.Fatalf("expected %d, got %d %v", i, v, ok)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("message %d not delivered", i)
+		}
+	}
+	select {
+	case _, ok := <-ch:
+		if ok {
+			t.Fatal("expected channel closed")
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("channel not closed after drain")
+	}
+}
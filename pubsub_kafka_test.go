@@ -0,0 +1,191 @@
+package ion_test
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sokool/ion"
+)
+
+func TestKafkaPubSub(t *testing.T) {
+	k := fakeKafka(t)
+	os.Setenv("TEST_KAFKA_URL", k.URL)
+	ps, err := ion.NewKafkaPubSub("TEST_KAFKA_URL")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	topic := *ion.MustURL("kafka://orders/created?group=billing&offset=earliest")
+	for _, s := range []string{"a", "b"} {
+		if err = ps.Publish(ctx, topic, []byte(s)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ch, err := ps.Subscribe(ctx, topic)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for len(got) < 2 {
+		select {
+		case m := <-ch:
+			got = append(got, string(m))
+		case <-time.After(time.Second):
+			t.Fatalf("messages not received, got %v", got)
+		}
+	}
+	if strings.Join(got, ",") != "a,b" {
+		t.Fatalf("unexpected messages %v", got)
+	}
+	cancel()
+	for range ch {
+	}
+	if c := k.committed("billing", "orders.created"); c != 1 {
+		t.Fatalf("expected offset 1 committed, got %d", c)
+	}
+	if _, err = ps.Subscribe(context.Background(), *ion.MustURL("kafka://orders?commit=never")); err == nil {
+		t.Fatal("expected error of unknown commit policy")
+	}
+}
+
+func TestKafkaPubSub_SubscribeAck(t *testing.T) {
+	k := fakeKafka(t)
+	os.Setenv("TEST_KAFKA_URL", k.URL)
+	ps, err := ion.NewKafkaPubSub("TEST_KAFKA_URL")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	topic := *ion.MustURL("kafka://orders?group=shipping&offset=earliest")
+	for _, s := range []string{"a", "b"} {
+		if err = ps.Publish(ctx, topic, []byte(s)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ch, err := ps.(ion.AckPubSub).SubscribeAck(ctx, topic)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for len(got) < 3 {
+		select {
+		case e := <-ch:
+			got = append(got, fmt.Sprintf("%s%d", e.Value, e.Attempt))
+			if len(got) == 1 {
+				e.Nack()
+			} else {
+				e.Ack()
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("messages not received, got %v", got)
+		}
+	}
+	if strings.Join(got, ",") != "a1,a2,b1" {
+		t.Fatalf("unexpected deliveries %v", got)
+	}
+	cancel()
+	for range ch {
+	}
+	if c := k.committed("shipping", "orders"); c != 1 {
+		t.Fatalf("expected offset 1 committed, got %d", c)
+	}
+}
+
+// fakeKafkaProxy serves produce, consumer, subscription, records, positions
+// and offsets endpoints of Kafka REST Proxy, with a single partition per topic.
+type fakeKafkaProxy struct {
+	*httptest.Server
+	mu      sync.Mutex
+	records map[string][]string       // topic records
+	topics  map[string]string         // consumer topic
+	read    map[string]int            // consumer position
+	commits map[string]map[string]int // group topic offsets
+}
+
+func (k *fakeKafkaProxy) committed(group, topic string) int {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	return k.offset(group, topic)
+}
+
+func (k *fakeKafkaProxy) offset(group, topic string) int {
+	if c, ok := k.commits[group][topic]; ok {
+		return c
+	}
+	return -1
+}
+
+func fakeKafka(t *testing.T) *fakeKafkaProxy {
+	k := &fakeKafkaProxy{
+		records: map[string][]string{},
+		topics:  map[string]string{},
+		read:    map[string]int{},
+		commits: map[string]map[string]int{},
+	}
+	k.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		k.mu.Lock()
+		defer k.mu.Unlock()
+		if !strings.HasPrefix(r.Header.Get("Accept"), "application/vnd.kafka.binary.v2+json") {
+			http.Error(w, "unsupported accept", http.StatusNotAcceptable)
+			return
+		}
+		b, _ := io.ReadAll(r.Body)
+		in := ion.JSON(b)
+		p := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+		switch {
+		case r.Method == "POST" && p[0] == "topics":
+			for v := range in.Each("records") {
+				s, _ := base64.StdEncoding.DecodeString(v.Text("value"))
+				k.records[p[1]] = append(k.records[p[1]], string(s))
+			}
+			json.NewEncoder(w).Encode(ion.Meta{"offsets": []ion.Meta{{"partition": 0, "offset": len(k.records[p[1]]) - 1}}})
+		case r.Method == "POST" && len(p) == 2:
+			json.NewEncoder(w).Encode(ion.Meta{"instance_id": in.Text("name"), "base_uri": k.URL + r.URL.Path + "/instances/" + in.Text("name")})
+		case len(p) < 4:
+			http.NotFound(w, r)
+		case r.Method == "DELETE":
+			delete(k.topics, p[3])
+			w.WriteHeader(http.StatusNoContent)
+		case p[4] == "subscription":
+			k.topics[p[3]] = in.Text("topics.0")
+			k.read[p[3]] = k.offset(p[1], k.topics[p[3]]) + 1
+			w.WriteHeader(http.StatusNoContent)
+		case p[4] == "records":
+			tp := k.topics[p[3]]
+			var rr []ion.Meta
+			for i, s := range k.records[tp][k.read[p[3]]:] {
+				o := k.read[p[3]] + i
+				rr = append(rr, ion.Meta{"topic": tp, "partition": 0, "offset": o, "value": base64.StdEncoding.EncodeToString([]byte(s))})
+			}
+			if k.read[p[3]] += len(rr); len(rr) == 0 {
+				time.Sleep(10 * time.Millisecond) // long poll
+			}
+			json.NewEncoder(w).Encode(rr)
+		case p[4] == "positions":
+			k.read[p[3]] = in.Int("offsets.0.offset")
+			w.WriteHeader(http.StatusNoContent)
+		case p[4] == "offsets":
+			if k.commits[p[1]] == nil {
+				k.commits[p[1]] = map[string]int{}
+			}
+			for o := range in.Each("offsets") {
+				k.commits[p[1]][o.Text("topic")] = o.Int("offset")
+			}
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(k.Close)
+	return k
+}
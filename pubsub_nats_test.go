@@ -0,0 +1,115 @@
+package ion_test
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sokool/ion"
+)
+
+func TestNATSPubSub(t *testing.T) {
+	os.Setenv("TEST_NATS_URL", "nats://token@"+fakeNATS(t))
+	ps, err := ion.NewNATSPubSub("TEST_NATS_URL")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	topic := *ion.MustURL("nats://orders/created?queue=billing")
+	a, err := ps.Subscribe(ctx, topic)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := ps.Subscribe(ctx, topic)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range 4 {
+		if err = ps.Publish(ctx, topic, []byte(strconv.Itoa(i))); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// queue group members share messages, each is delivered once
+	var got []string
+	for len(got) < 4 {
+		select {
+		case m := <-a:
+			got = append(got, "a"+string(m))
+		case m := <-b:
+			got = append(got, "b"+string(m))
+		case <-time.After(time.Second):
+			t.Fatalf("messages not received, got %v", got)
+		}
+	}
+	slices.Sort(got)
+	if strings.Join(got, ",") != "a0,a2,b1,b3" {
+		t.Fatalf("unexpected delivery %v", got)
+	}
+}
+
+// fakeNATS serves CONNECT, PING, SUB and PUB of NATS protocol, delivering
+// messages of a queue group round robin.
+func fakeNATS(t *testing.T) string {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { l.Close() })
+	type sub struct {
+		c   net.Conn
+		sid string
+	}
+	var mu sync.Mutex
+	subs, next := map[string][]sub{}, map[string]int{}
+	go func() {
+		for {
+			c, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer c.Close()
+				fmt.Fprint(c, "INFO {\"server_id\":\"fake\"}\r\n")
+				r := bufio.NewReader(c)
+				for {
+					line, err := r.ReadString('\n')
+					if err != nil {
+						return
+					}
+					f := strings.Fields(line)
+					mu.Lock()
+					switch f[0] {
+					case "CONNECT":
+						if !strings.Contains(line, `"auth_token":"token"`) {
+							fmt.Fprint(c, "-ERR 'Authorization Violation'\r\n")
+						}
+					case "PING":
+						fmt.Fprint(c, "PONG\r\n")
+					case "SUB":
+						subs[f[1]] = append(subs[f[1]], sub{c, f[len(f)-1]})
+					case "PUB":
+						n, _ := strconv.Atoi(f[2])
+						b := make([]byte, n+2)
+						r.Read(b)
+						if ss := subs[f[1]]; len(ss) > 0 {
+							s := ss[next[f[1]]%len(ss)]
+							next[f[1]]++
+							fmt.Fprintf(s.c, "MSG %s %s %d\r\n%s\r\n", f[1], s.sid, n, b[:n])
+						}
+					}
+					mu.Unlock()
+				}
+			}()
+		}
+	}()
+	return l.Addr().String()
+}
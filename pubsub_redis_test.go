@@ -0,0 +1,152 @@
+package ion_test
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sokool/ion"
+)
+
+func TestRedisPubSub(t *testing.T) {
+	addr := fakeRedis(t)
+	os.Setenv("TEST_REDIS_URL", "redis://:secret@"+addr)
+	ps, err := ion.NewRedisPubSub("TEST_REDIS_URL")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	topic := *ion.MustURL("redis://orders")
+	ch, err := ps.Subscribe(ctx, topic)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = ps.Publish(ctx, topic, []byte(`{"id":1}`)); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case b := <-ch:
+		if string(b) != `{"id":1}` {
+			t.Fatalf("unexpected message %s", b)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("message not received")
+	}
+	cancel()
+	if _, ok := <-ch; ok {
+		t.Fatal("expected channel closed after cancel")
+	}
+
+	os.Setenv("TEST_REDIS_URL", "http://"+addr)
+	if _, err = ion.NewRedisPubSub("TEST_REDIS_URL"); !ion.ErrRedis.In(err) {
+		t.Fatalf("expected ErrRedis, got %v", err)
+	}
+}
+
+func TestRedisPubSub_Replay(t *testing.T) {
+	os.Setenv("TEST_REDIS_URL", "redis://"+fakeRedis(t))
+	ps, err := ion.NewRedisPubSub("TEST_REDIS_URL")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	for i := range 4 {
+		if err = ps.Publish(ctx, *ion.MustURL("redis://orders?history=3"), []byte(strconv.Itoa(i))); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ch, err := ps.Subscribe(ctx, *ion.MustURL("redis://orders?replay=2"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for len(got) < 2 {
+		select {
+		case b := <-ch:
+			got = append(got, string(b))
+		case <-time.After(time.Second):
+			t.Fatalf("messages not replayed, got %v", got)
+		}
+	}
+	if strings.Join(got, ",") != "2,3" {
+		t.Fatalf("expected last 2 messages, got %v", got)
+	}
+}
+
+// fakeRedis serves AUTH, PUBLISH, SUBSCRIBE, XADD and XREVRANGE commands of
+// RESP protocol.
+func fakeRedis(t *testing.T) string {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { l.Close() })
+	var mu sync.Mutex
+	subs, streams := map[string][]net.Conn{}, map[string][]string{}
+	go func() {
+		for {
+			c, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer c.Close()
+				r := bufio.NewReader(c)
+				for {
+					h, err := r.ReadString('\n')
+					if err != nil {
+						return
+					}
+					n, _ := strconv.Atoi(strings.TrimSpace(h[1:]))
+					args := make([]string, n)
+					for i := range args {
+						r.ReadString('\n')
+						s, _ := r.ReadString('\n')
+						args[i] = strings.TrimSuffix(s, "\r\n")
+					}
+					mu.Lock()
+					switch strings.ToUpper(args[0]) {
+					case "AUTH":
+						if args[len(args)-1] == "secret" {
+							fmt.Fprint(c, "+OK\r\n")
+						} else {
+							fmt.Fprint(c, "-WRONGPASS\r\n")
+						}
+					case "SUBSCRIBE":
+						subs[args[1]] = append(subs[args[1]], c)
+						fmt.Fprintf(c, "*3\r\n$9\r\nsubscribe\r\n$%d\r\n%s\r\n:1\r\n", len(args[1]), args[1])
+					case "PUBLISH":
+						for _, s := range subs[args[1]] {
+							fmt.Fprintf(s, "*3\r\n$7\r\nmessage\r\n$%d\r\n%s\r\n$%d\r\n%s\r\n", len(args[1]), args[1], len(args[2]), args[2])
+						}
+						fmt.Fprintf(c, ":%d\r\n", len(subs[args[1]]))
+					case "XADD": // key MAXLEN ~ n * field value
+						n, _ := strconv.Atoi(args[4])
+						if streams[args[1]] = append(streams[args[1]], args[7]); len(streams[args[1]]) > n {
+							streams[args[1]] = streams[args[1]][1:]
+						}
+						fmt.Fprintf(c, "$3\r\n%d-0\r\n", len(streams[args[1]]))
+					case "XREVRANGE": // key + - COUNT n
+						n, _ := strconv.Atoi(args[5])
+						ss := streams[args[1]][max(len(streams[args[1]])-n, 0):]
+						fmt.Fprintf(c, "*%d\r\n", len(ss))
+						for i := len(ss) - 1; i >= 0; i-- {
+							fmt.Fprintf(c, "*2\r\n$3\r\n%d-0\r\n*2\r\n$1\r\nm\r\n$%d\r\n%s\r\n", i, len(ss[i]), ss[i])
+						}
+					}
+					mu.Unlock()
+				}
+			}()
+		}
+	}()
+	return l.Addr().String()
+}
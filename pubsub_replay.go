@@ -0,0 +1,106 @@
+package ion
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// pubsubReplay returns number of past messages, or time since which they
+// are delivered to a new subscriber of the topic, from its replay and since
+// query parameters. Since is RFC3339 time or duration before now, ie.
+// ?since=1h or ?replay=100.
+func pubsubReplay(topic URL) (n int, since time.Time, err error) {
+	if s := topic.Query("replay"); s != "" {
+		if n, err = strconv.Atoi(s); err != nil || n < 1 {
+			return 0, since, ErrTopic.New("replay of %s must be a number greater than 0, %s given", topic.String(), s)
+		}
+	}
+	if s := topic.Query("since"); s != "" {
+		if d, err := time.ParseDuration(s); err == nil {
+			return n, time.Now().Add(-d), nil
+		}
+		if since, err = time.Parse(time.RFC3339, s); err != nil {
+			return 0, since, ErrTopic.New("since of %s must be RFC3339 time or duration, %s given", topic.String(), s)
+		}
+	}
+	return n, since, nil
+}
+
+// pubsubHistory returns number of messages kept for replay, from history
+// query parameter of the topic messages are published to.
+func pubsubHistory(topic URL) (int, error) {
+	s := topic.Query("history")
+	if s == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 0 {
+		return 0, ErrTopic.New("history of %s must be a number not less than 0, %s given", topic.String(), s)
+	}
+	return n, nil
+}
+
+// pubsubRecord is a message kept in history of the topic.
+type pubsubRecord struct {
+	Time    time.Time `json:"time"`
+	Message []byte    `json:"message"`
+}
+
+// record keeps msg in Store history of the topic, when it has history query
+// parameter. History is a ring of messages under their own keys, numbered by
+// counter of the topic, the message falling out of it is deleted, so
+// recording does not depend on the history length. Returns number of the
+// recorded message.
+func (m *pubSub) record(ctx context.Context, topic URL, msg []byte) (int64, bool, error) {
+	n, err := pubsubHistory(topic)
+	if err != nil || n == 0 {
+		return 0, false, err
+	}
+	ctx, k := WithCache(ctx), "pubsub:history:"+pubsubName(topic)
+	i, err := Incr(ctx, k, 1)
+	if err != nil {
+		return 0, false, ErrTopic.Wrap(err)
+	}
+	if Set(ctx, fmt.Sprintf("%s:%d", k, i), pubsubRecord{Time: time.Now(), Message: msg}) < 0 {
+		return 0, false, ErrTopic.New("history of %s not stored", topic.String())
+	}
+	if i > int64(n) {
+		if err = Cache.Delete(ctx, fmt.Sprintf("%s:%d", k, i-int64(n))); err != nil {
+			return 0, false, ErrTopic.Wrap(err)
+		}
+	}
+	return i, true, nil
+}
+
+// replay returns up to n past messages of the topic, those since given time
+// when it is not zero, and number of the last one recorded. Messages are read
+// from the newest, in batches, until a missing or older one.
+func (m *pubSub) replay(ctx context.Context, topic URL, n int, since time.Time) ([][]byte, int64, error) {
+	ctx, k := WithCache(ctx), "pubsub:history:"+pubsubName(topic)
+	var i int64
+	if Get(ctx, "%s", &i, k) < 0 {
+		return nil, 0, ErrTopic.New("history of %s not loaded", topic.String())
+	}
+	last := i
+	var bb [][]byte
+	for i > 0 && (n == 0 || len(bb) < n) {
+		keys := make([]string, 0, 64)
+		for ; i > 0 && len(keys) < cap(keys); i-- {
+			keys = append(keys, fmt.Sprintf("%s:%d", k, i))
+		}
+		rr := GetMany[pubsubRecord](ctx, keys...)
+		for _, key := range keys {
+			r, ok := rr[key]
+			if !ok || r.Time.Before(since) || n > 0 && len(bb) == n {
+				slices.Reverse(bb)
+				return bb, last, nil
+			}
+			bb = append(bb, r.Message)
+		}
+	}
+	slices.Reverse(bb)
+	return bb, last, nil
+}
@@ -0,0 +1,96 @@
+package ion_test
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/sokool/ion"
+)
+
+func TestTopic_Replay(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	w := ion.MustTopic[int](ctx, "memory://replay/orders?history=3")
+	for i := range 5 {
+		if err := w.Write(i); err != nil {
+			t.Fatal(err)
+		}
+	}
+	type test struct {
+		name string
+		ack  bool
+		want []int
+	}
+	for _, c := range []test{
+		{name: "memory://replay/orders?replay=2", want: []int{3, 4}},
+		{name: "memory://replay/orders?since=1h", want: []int{2, 3, 4}},
+		{name: "memory://replay/orders?replay=2", ack: true, want: []int{3, 4}},
+	} {
+		var err error
+		var got []int
+		if r := ion.MustTopic[int](ctx, c.name); c.ack {
+			ch := r.ReadAck(&err)
+			for range c.want {
+				e := <-ch
+				e.Ack()
+				got = append(got, e.Value)
+			}
+		} else {
+			ch := r.Read(&err)
+			for range c.want {
+				select {
+				case v := <-ch:
+					got = append(got, v)
+				case <-time.After(time.Second):
+					t.Fatalf("%s: messages not replayed, got %v", c.name, got)
+				}
+			}
+		}
+		if err != nil || !slices.Equal(got, c.want) {
+			t.Fatalf("%s: expected %v, got %v %v", c.name, c.want, got, err)
+		}
+	}
+}
+
+// hung is a Store whose Get waits until it is closed.
+type hung struct {
+	ion.Store
+	wait chan struct{}
+}
+
+func (h hung) Get(ctx context.Context, key string) ([]byte, error) {
+	<-h.wait
+	return h.Store.Get(ctx, key)
+}
+
+func TestTopic_ReplayHungStore(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	h := hung{Store: ion.MemoryStore(), wait: make(chan struct{})}
+	replayed := make(chan struct{})
+	defer func(s ion.Store) {
+		close(h.wait)
+		<-replayed
+		ion.Cache = s
+	}(ion.Cache)
+	ion.Cache = h
+	var err, rerr error
+	ch := ion.MustTopic[int](ctx, "memory://replay/hung").Read(&err)
+	go func() {
+		defer close(replayed)
+		ion.MustTopic[int](ctx, "memory://replay/hung?replay=1").Read(&rerr)
+	}()
+	time.Sleep(10 * time.Millisecond) // replay waits for history
+	done := make(chan error, 1)
+	go func() { done <- ion.MustTopic[int](ctx, "memory://replay/hung").Write(1) }()
+	select {
+	case err = <-done:
+	case <-time.After(time.Second):
+		t.Fatal("publish blocked by replay of hung Store")
+	}
+	if v := <-ch; err != nil || v != 1 {
+		t.Fatalf("expected message published, got %d %v", v, err)
+	}
+}
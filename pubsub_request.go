@@ -0,0 +1,139 @@
+package ion
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+// Request publishes req on the topic and waits for the reply of a service
+// serving the topic with Reply. Replies are read from a topic of the request
+// only, named after topic with /replies/<id> path suffix. Without ctx
+// deadline the reply is awaited for 30s. Go methods can not have type
+// parameters, so it is a function instead of Topic method.
+//
+// Example:
+//
+//	t := ion.MustTopic[Quote](ctx, "nats://pricing/quote")
+//	price, err := ion.Request[Price](ctx, t, Quote{SKU: "A-1"})
+func Request[RES, REQ any](ctx context.Context, t *Topic[REQ], req REQ) (RES, error) {
+	var res RES
+	ps, err := t.pubSub()
+	if err != nil {
+		return res, err
+	}
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 30*time.Second)
+		defer cancel()
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	m := pubsubRequest{ID: UUID()}
+	if m.Body, err = json.Marshal(req); err != nil {
+		return res, ErrTopic.Wrap(err)
+	}
+	reply := *t.Name.URL
+	reply.Path, reply.RawQuery = reply.Path+"/replies/"+m.ID, ""
+	m.Reply = reply.String()
+	ch, err := ps.Subscribe(ctx, URL{URL: &reply})
+	if err != nil {
+		return res, ErrTopic.Wrap(err)
+	}
+	b, err := json.Marshal(m)
+	if err != nil {
+		return res, ErrTopic.Wrap(err)
+	}
+	if err = ps.Publish(ctx, *t.Name, b); err != nil {
+		return res, ErrTopic.Wrap(err)
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return res, ErrTopic.New("%s request %s not replied: %w", t.Name.String(), m.ID, ctx.Err())
+		case b, ok := <-ch:
+			if !ok {
+				return res, ErrTopic.New("%s request %s not replied: %w", t.Name.String(), m.ID, context.Canceled)
+			}
+			var r pubsubReply
+			if err = json.Unmarshal(b, &r); err != nil || r.ID != m.ID {
+				continue
+			}
+			if r.Error != "" {
+				return res, ErrTopic.New("%s", r.Error)
+			}
+			if err = json.Unmarshal(r.Body, &res); err != nil {
+				return res, ErrTopic.Wrap(err)
+			}
+			return res, nil
+		}
+	}
+}
+
+// Reply serves requests of the topic sent with Request, in background until
+// topic Context is done. Each request is handled by fn in its own goroutine,
+// its error is sent back to the requester as error message.
+//
+// Example:
+//
+//	err := ion.Reply(t, func(ctx context.Context, q Quote) (Price, error) {
+//		return prices.Of(ctx, q.SKU)
+//	})
+func Reply[REQ, RES any](t *Topic[REQ], fn func(context.Context, REQ) (RES, error)) error {
+	ps, err := t.pubSub()
+	if err != nil {
+		return err
+	}
+	cx := t.Context
+	if cx == nil {
+		cx = ctx
+	}
+	ch, err := ps.Subscribe(cx, *t.Name)
+	if err != nil {
+		return ErrTopic.Wrap(err)
+	}
+	go func() {
+		for b := range ch {
+			var m pubsubRequest
+			if err := json.Unmarshal(b, &m); err != nil || m.ID == "" || m.Reply == "" {
+				log_.Errorf("invalid request of %s topic skipped %.256s", t.Name.String(), b)
+				continue
+			}
+			go func() {
+				r := pubsubReply{ID: m.ID}
+				var req REQ
+				if err := json.Unmarshal(m.Body, &req); err != nil {
+					r.Error = err.Error()
+				} else if res, err := fn(cx, req); err != nil {
+					r.Error = err.Error()
+				} else if r.Body, err = json.Marshal(res); err != nil {
+					r.Error = err.Error()
+				}
+				u, err := NewURL(m.Reply)
+				if err == nil {
+					b, _ := json.Marshal(r)
+					err = ps.Publish(cx, *u, b)
+				}
+				if err != nil {
+					log_.Errorf("reply to %s request of %s topic failed: %s", m.ID, t.Name.String(), err)
+				}
+			}()
+		}
+	}()
+	return nil
+}
+
+// pubsubRequest is a message of Request, Reply is URL of the topic its
+// reply is expected on.
+type pubsubRequest struct {
+	ID    string          `json:"id"`
+	Reply string          `json:"reply"`
+	Body  json.RawMessage `json:"body"`
+}
+
+// pubsubReply is a message replying to pubsubRequest of the same ID.
+type pubsubReply struct {
+	ID    string          `json:"id"`
+	Body  json.RawMessage `json:"body,omitempty"`
+	Error string          `json:"error,omitempty"`
+}
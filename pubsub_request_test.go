@@ -0,0 +1,38 @@
+package ion_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sokool/ion"
+)
+
+func TestRequest(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	topic := ion.MustTopic[int](ctx, "memory://pricing/quote")
+	err := ion.Reply(topic, func(_ context.Context, n int) (string, error) {
+		if n < 0 {
+			return "", fmt.Errorf("negative %d", n)
+		}
+		return fmt.Sprintf("$%d", n*10), nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := ion.Request[string](ctx, topic, 4)
+	if err != nil || s != "$40" {
+		t.Fatalf("expected $40, got %q %v", s, err)
+	}
+	if _, err = ion.Request[string](ctx, topic, -1); err == nil || !strings.HasSuffix(err.Error(), "negative -1") {
+		t.Fatalf("expected error of the reply, got %v", err)
+	}
+	tx, done := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer done()
+	if _, err = ion.Request[string](tx, ion.MustTopic[int](ctx, "memory://pricing/nobody"), 1); err == nil {
+		t.Fatal("expected error of request without reply")
+	}
+}
@@ -0,0 +1,233 @@
+package ion_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sokool/ion"
+)
+
+func TestTopic_Overflow(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	type test struct {
+		overflow string
+		want     []int
+		fails    int
+	}
+	for _, c := range []test{
+		{overflow: "drop-new", want: []int{1, 2, 3}},
+		{overflow: "drop-oldest", want: []int{1, 4, 5}},
+		{overflow: "error", want: []int{1, 2, 3}, fails: 2},
+	} {
+		topic := ion.MustTopic[int](ctx, "memory://overflow/%s?buffer=2&overflow=%s", c.overflow, c.overflow)
+		var err error
+		ch := topic.Read(&err)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = topic.Write(0); err != nil {
+			t.Fatal(err)
+		}
+		<-ch
+		time.Sleep(10 * time.Millisecond) // Read waits for the next message
+		// 1 is taken by Read, 2 and 3 fill the buffer, 4 and 5 overflow it
+		var fails int
+		for i := 1; i <= 5; i++ {
+			if err = topic.Write(i); err != nil {
+				fails++
+			}
+		}
+		if fails != c.fails {
+			t.Fatalf("%s: expected %d failures, got %d", c.overflow, c.fails, fails)
+		}
+		var got []int
+		for range c.want {
+			got = append(got, <-ch)
+		}
+		if !slices.Equal(got, c.want) {
+			t.Fatalf("%s: expected %v, got %v", c.overflow, c.want, got)
+		}
+	}
+	if s := ion.Metrics.String(); !strings.Contains(s, `pubsub_dropped_total{topic="overflow/drop_new",overflow="drop_new"}`) {
+		t.Fatalf("dropped messages not counted\n%s", s)
+	}
+}
+
+func TestTopic_OverflowBlock(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	slow := ion.MustTopic[int](ctx, "memory://overflow/block?buffer=0&overflow=block")
+	var err error
+	_ = slow.Read(&err) // never read, Read takes the first message and waits
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		for i := range 3 {
+			slow.Write(i)
+		}
+	}()
+	time.Sleep(10 * time.Millisecond)
+	// blocked Publish does not hold back subscribers and publishers of other topics
+	other := ion.MustTopic[int](ctx, "memory://overflow/other")
+	ch := other.Read(&err)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = other.Write(42); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case v := <-ch:
+		if v != 42 {
+			t.Fatalf("expected 42, got %d", v)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("message of other topic not delivered")
+	}
+}
+
+func TestTopic_Hosts(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var err error
+	billing := ion.MustTopic[int](ctx, "memory://billing/orders").Read(&err)
+	shipping := ion.MustTopic[int](ctx, "memory://shipping/orders").Read(&err)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// topics of different hosts sharing the path are separate
+	if err = ion.MustTopic[int](ctx, "memory://shipping/orders").Write(1); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case v := <-shipping:
+		if v != 1 {
+			t.Fatalf("expected 1, got %d", v)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("message not delivered")
+	}
+	select {
+	case v := <-billing:
+		t.Fatalf("expected nothing on billing/orders, got %d", v)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestTopic_Metrics(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	id := ion.UUID()[:8]
+	topic := ion.MustTopic[int](ctx, "memory://metrics/%s", id)
+	var err error
+	ch := topic.Read(&err)
+	for i := range 3 {
+		if err = topic.Write(i); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for range 3 {
+		<-ch
+	}
+	s := ion.Metrics.String()
+	for _, m := range []string{
+		`pubsub_published_total{topic="metrics/%s"} 3`,
+		`pubsub_consumed_total{topic="metrics/%s"} 3`,
+		`pubsub_publish_seconds_count{topic="metrics/%s"} 3`,
+		`pubsub_lag{topic="metrics/%s"}`,
+	} {
+		if m = fmt.Sprintf(m, id); !strings.Contains(s, m) {
+			t.Fatalf("%s metric not found in\n%s", m, s)
+		}
+	}
+}
+
+func TestTopic_WriteKeyed(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	w := ion.MustTopic[string](ctx, "memory://keyed/orders?partitions=2")
+	var err error
+	p0 := ion.MustTopic[string](ctx, "memory://keyed/orders?partition=0").Read(&err)
+	p1 := ion.MustTopic[string](ctx, "memory://keyed/orders?partition=1").Read(&err)
+	all := ion.MustTopic[string](ctx, "memory://keyed/orders").Read(&err)
+	// keys a and b hash to different partitions
+	for _, k := range []string{"a1", "b1", "a2", "b2"} {
+		if err = w.WriteKeyed(k[:1], k); err != nil {
+			t.Fatal(err)
+		}
+	}
+	read := func(ch <-chan string, n int) string {
+		var ss []string
+		for range n {
+			select {
+			case s := <-ch:
+				ss = append(ss, s)
+			case <-time.After(time.Second):
+				t.Fatalf("messages not received, got %v", ss)
+			}
+		}
+		return strings.Join(ss, ",")
+	}
+	if a, b := read(p0, 2), read(p1, 2); a+"|"+b != "a1,a2|b1,b2" && a+"|"+b != "b1,b2|a1,a2" {
+		t.Fatalf("keys not partitioned, got %s and %s", a, b)
+	}
+	if s := read(all, 4); s != "a1,b1,a2,b2" {
+		t.Fatalf("expected all messages in order, got %s", s)
+	}
+	if err = ion.MustTopic[string](ctx, "memory://keyed/orders?partitions=x").WriteKeyed("a", "a"); err == nil {
+		t.Fatal("expected error of invalid partitions")
+	}
+}
+
+func TestTopic_Group(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	w := ion.MustTopic[string](ctx, "memory://group/orders")
+	var err error
+	a := ion.MustTopic[string](ctx, "memory://group/orders").Group("billing").Read(&err)
+	b := ion.MustTopic[string](ctx, "memory://group/orders").Group("billing").Read(&err)
+	all := ion.MustTopic[string](ctx, "memory://group/orders").Read(&err)
+	for _, s := range []string{"1", "2", "3", "4"} {
+		if err = w.Write(s); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var got []string
+	for len(got) < 8 {
+		select {
+		case s := <-a:
+			got = append(got, "a"+s)
+		case s := <-b:
+			got = append(got, "b"+s)
+		case s := <-all:
+			got = append(got, "*"+s)
+		case <-time.After(time.Second):
+			t.Fatalf("messages not received, got %v", got)
+		}
+	}
+	slices.Sort(got)
+	// group members share messages, reader out of group gets all
+	if s := strings.Join(got, ","); s != "*1,*2,*3,*4,a1,a3,b2,b4" && s != "*1,*2,*3,*4,a2,a4,b1,b3" {
+		t.Fatalf("unexpected delivery %s", s)
+	}
+}
+
+func TestMustTopic_Fatal(t *testing.T) {
+	var err error
+	ion.OnFatal(func(e error) { err = e })
+	defer ion.OnFatal(nil)
+	topic := ion.MustTopic[int](context.Background(), "memory://bad\x7f")
+	if !errors.Is(err, ion.ErrTopic) {
+		t.Fatalf("expected fatal topic error, got %v", err)
+	}
+	if er := topic.Write(1); !errors.Is(er, ion.ErrTopic) {
+		t.Fatalf("expected write of invalid topic to fail, got %v", er)
+	}
+}
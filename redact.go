@@ -0,0 +1,237 @@
+package ion
+
+import (
+	"encoding/json"
+	"io"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+// Redactor masks sensitive data in texts and JSON documents. It is defined
+// once and shared by logging, JSON.Redact and Endpoint descriptions, so every
+// subsystem hides the same things.
+//
+// Two kinds of rules exist:
+//   - patterns, regular expressions matched against any text or JSON string value,
+//   - keys, JSON object keys (case-insensitive) or dot paths whose values are masked entirely.
+//
+// Example:
+//
+//	r := ion.NewRedactor().
+//		Pattern("ticket", `TCK-\d{6}`).
+//		Key("pesel", "customer.address")
+//	if ion.InProduction() {
+//		ion.UseRedactor(r)
+//	}
+type Redactor struct {
+	// Mask replaces redacted values.
+	Mask string
+
+	mu    sync.RWMutex
+	rules []redactRule
+	keys  []string
+}
+
+type redactRule struct {
+	name  string
+	re    *regexp.Regexp
+	valid func(string) bool
+}
+
+// NewRedactor creates Redactor with built-in rules masking credit card
+// numbers, bearer tokens and email addresses, and values of password, secret,
+// token, authorization and api key fields.
+func NewRedactor() *Redactor {
+	r := &Redactor{Mask: "***"}
+	r.rules = append(r.rules,
+		redactRule{"card", regexp.MustCompile(`\b(?:\d[ -]?){12,18}\d\b`), luhn},
+		redactRule{"bearer", regexp.MustCompile(`(?i)\bbearer\s+[A-Za-z0-9\-._~+/]+=*`), nil},
+		redactRule{"email", regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`), nil},
+	)
+	return r.Key("password", "passwd", "secret", "client_secret", "token", "access_token",
+		"refresh_token", "authorization", "api_key", "apikey", "x-api-key")
+}
+
+// UseRedactor sets Redactor used across the package, nil disables redaction.
+// NewRedactor is used by default, it masks output of NewLogger loggers too.
+func UseRedactor(r *Redactor) {
+	redactorMu.Lock()
+	defer redactorMu.Unlock()
+	redactor = r
+}
+
+// Redaction returns Redactor used across the package, nil when disabled.
+func Redaction() *Redactor {
+	redactorMu.RLock()
+	defer redactorMu.RUnlock()
+	return redactor
+}
+
+// Pattern adds a named regular expression rule, matches are replaced by Mask.
+// Adding a rule with existing name replaces it.
+func (r *Redactor) Pattern(name, expr string) *Redactor {
+	re := regexp.MustCompile(expr)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i := range r.rules {
+		if r.rules[i].name == name {
+			r.rules[i] = redactRule{name: name, re: re}
+			return r
+		}
+	}
+	r.rules = append(r.rules, redactRule{name: name, re: re})
+	return r
+}
+
+// Key adds JSON keys or dot paths which values are always masked.
+func (r *Redactor) Key(names ...string) *Redactor {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, n := range names {
+		r.keys = append(r.keys, strings.ToLower(n))
+	}
+	return r
+}
+
+// Text returns s with all pattern matches masked.
+func (r *Redactor) Text(s string) string {
+	if r == nil {
+		return s
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, x := range r.rules {
+		s = x.re.ReplaceAllStringFunc(s, func(m string) string {
+			if x.valid != nil && !x.valid(m) {
+				return m
+			}
+			return r.Mask
+		})
+	}
+	return s
+}
+
+// JSON returns a copy of j with values of sensitive keys and paths masked
+// and pattern matches masked inside string values.
+func (r *Redactor) JSON(j JSON, paths ...string) JSON {
+	if r == nil || len(j) == 0 {
+		return j
+	}
+	if !json.Valid(j) {
+		return JSON(r.Text(string(j)))
+	}
+	r.mu.RLock()
+	keys := slices.Concat(r.keys, paths)
+	r.mu.RUnlock()
+
+	o := append(JSON{}, j...)
+	var walk func(path string, key string, n gjson.Result)
+	walk = func(path, key string, n gjson.Result) {
+		if path != "" && r.matches(keys, path, key) {
+			o, _ = sjson.SetBytes(o, path, r.Mask)
+			return
+		}
+		switch {
+		case n.IsObject(), n.IsArray():
+			n.ForEach(func(k, v gjson.Result) bool {
+				p := jsonKey(k.String())
+				if path != "" {
+					p = path + "." + p
+				}
+				walk(p, k.String(), v)
+				return true
+			})
+		case n.Type == gjson.String && path != "":
+			if s := r.Text(n.String()); s != n.String() {
+				o, _ = sjson.SetBytes(o, path, s)
+			}
+		}
+	}
+	walk("", "", gjson.ParseBytes(j))
+	return o
+}
+
+// Header returns value of the HTTP header masked entirely when its name is
+// one of sensitive keys, otherwise with pattern matches masked.
+func (r *Redactor) Header(name, value string) string {
+	if r == nil {
+		return value
+	}
+	r.mu.RLock()
+	keys := r.keys
+	r.mu.RUnlock()
+	if r.matches(keys, name, name) {
+		return r.Mask
+	}
+	return r.Text(value)
+}
+
+// Writer returns io.Writer masking pattern matches before writing to w.
+func (r *Redactor) Writer(w io.Writer) io.Writer {
+	return redactWriter{w: w, r: r}
+}
+
+func (r *Redactor) matches(keys []string, path, key string) bool {
+	p, k := strings.ToLower(strings.ReplaceAll(path, `\`, "")), strings.ToLower(key)
+	for _, n := range keys {
+		if n == k || n == p {
+			return true
+		}
+	}
+	return false
+}
+
+// redactWriter masks data with its Redactor, or with the package one active
+// at the time of writing when not given.
+type redactWriter struct {
+	w io.Writer
+	r *Redactor
+}
+
+func (w redactWriter) Write(p []byte) (int, error) {
+	r := w.r
+	if r == nil {
+		r = Redaction()
+	}
+	if r == nil {
+		return w.w.Write(p)
+	}
+	if _, err := io.WriteString(w.w, r.Text(string(p))); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// jsonKey escapes gjson/sjson path characters in a single key.
+func jsonKey(k string) string {
+	return strings.NewReplacer(".", `\.`, "*", `\*`, "?", `\?`, "#", `\#`, "|", `\|`, "@", `\@`).Replace(k)
+}
+
+// luhn validates credit card numbers so random long numbers are left intact.
+func luhn(s string) bool {
+	var sum, n int
+	for i := len(s) - 1; i >= 0; i-- {
+		c := s[i]
+		if c < '0' || c > '9' {
+			continue
+		}
+		d := int(c - '0')
+		if n%2 == 1 {
+			if d *= 2; d > 9 {
+				d -= 9
+			}
+		}
+		sum, n = sum+d, n+1
+	}
+	return n >= 13 && sum%10 == 0
+}
+
+var (
+	redactorMu sync.RWMutex
+	redactor   = NewRedactor()
+)
@@ -0,0 +1,69 @@
+package ion_test
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/sokool/log"
+
+	. "github.com/sokool/ion"
+)
+
+func TestRedactor(t *testing.T) {
+	r := NewRedactor().Pattern("ticket", `TCK-\d{6}`).Key("customer.address")
+	cases := []struct {
+		name, in, out string
+	}{
+		{"card", "paid with 4111 1111 1111 1111 today", "paid with *** today"},
+		{"not a card", "order 1234567890123 shipped", "order 1234567890123 shipped"},
+		{"bearer", "Authorization: Bearer eyJhbGciOi.J9x-y", "Authorization: ***"},
+		{"email", "contact tom@example.com now", "contact *** now"},
+		{"custom", "see TCK-123456", "see ***"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if s := r.Text(tc.in); s != tc.out {
+				t.Fatalf("expected %q, got %q", tc.out, s)
+			}
+		})
+	}
+	j := JSON(`{"user":{"Password":"x","mail":"a@b.io"},"customer":{"address":"Main 1","name":"Tom"},"items":[{"token":"t"}]}`)
+	exp := `{"user":{"Password":"***","mail":"***"},"customer":{"address":"***","name":"Tom"},"items":[{"token":"***"}]}`
+	if s := r.JSON(j); string(s) != exp {
+		t.Fatalf("expected %s, got %s", exp, s)
+	}
+	if s := r.JSON(j, "customer.name"); JSON(s).Text("customer.name") != "***" {
+		t.Fatalf("expected masked name, got %s", s)
+	}
+	if h := r.Header("Authorization", "Basic abc"); h != "***" {
+		t.Fatalf("expected masked header, got %s", h)
+	}
+	var b bytes.Buffer
+	if _, err := r.Writer(&b).Write([]byte("mail tom@example.com")); err != nil || b.String() != "mail ***" {
+		t.Fatalf("unexpected writer output %q %v", b.String(), err)
+	}
+}
+
+func TestRedactor_Logger(t *testing.T) {
+	write := func(l *Logger) { l.Infof("order 1234567890123 of tom@example.com shipped in 2.5s") }
+	var b bytes.Buffer
+	write(log.New(&b, log.All).Tag("Redact"))
+	stdout := os.Stdout
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.Stdout = w
+	write(NewLogger("Redact"))
+	os.Stdout = stdout
+	w.Close()
+	out, _ := io.ReadAll(r)
+	// lines differ only by time and masked email
+	_, exp, _ := strings.Cut(strings.Replace(b.String(), "tom@example.com", "***", 1), "[")
+	if _, s, _ := strings.Cut(string(out), "["); s != exp || !strings.Contains(s, "1234567890123 of *** shipped") {
+		t.Fatalf("expected log line unchanged but masked email\n%q, got\n%q", exp, s)
+	}
+}
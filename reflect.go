@@ -3,9 +3,13 @@ package ion
 import (
 	"fmt"
 	"reflect"
+	"slices"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
+
+	"github.com/google/uuid"
 )
 
 type Reflect[O any] struct {
@@ -24,6 +28,16 @@ func NewReflect[O any](o O) *Reflect[O] {
 // Get returns the value at path p.
 // Segments descend through structs, map[string] keys, or zero-arg methods.
 // Methods may return T or (T, error). Unexported fields are not accessed.
+//
+// A `*` segment expands all elements of a slice, array or map (in key order)
+// and a `from:to` segment a range of slice elements, either bound may be
+// omitted. Get returns []any of results then, flattened when several
+// segments expand.
+//
+// Example:
+//
+//	prices, err := ion.NewReflect(order).Get("items.*.price")  // []any{10.5, 3.2}
+//	first, err := ion.NewReflect(order).Get("items.:2.sku")     // []any{"A1", "B2"}
 func (r *Reflect[O]) Get(path string) (any, error) {
 	v := r.value
 	if !v.IsValid() {
@@ -39,9 +53,12 @@ func (r *Reflect[O]) Get(path string) (any, error) {
 		}
 		return v.Interface(), nil
 	}
+	return r.get(v, strings.Split(path, "."), 0)
+}
 
-	seg := strings.Split(path, ".")
-	for i, s := range seg {
+func (r *Reflect[O]) get(v reflect.Value, seg []string, i int) (any, error) {
+	for ; i < len(seg); i++ {
+		s := seg[i]
 		var err error
 		v, err = r.definition(v)
 		if err != nil {
@@ -50,6 +67,9 @@ func (r *Reflect[O]) Get(path string) (any, error) {
 		if !v.IsValid() {
 			return nil, fmt.Errorf("pathval: invalid at %q", r.join(seg[:i]))
 		}
+		if r.expands(v, s) {
+			return r.expand(v, seg, i)
+		}
 
 		switch v.Kind() {
 		case reflect.Struct:
@@ -85,7 +105,7 @@ func (r *Reflect[O]) Get(path string) (any, error) {
 			if v.Type().Key().Kind() != reflect.String {
 				return nil, fmt.Errorf("pathval: non-string map key at %q", r.join(seg[:i]))
 			}
-			mv := v.MapIndex(reflect.ValueOf(s))
+			mv := v.MapIndex(reflect.ValueOf(s).Convert(v.Type().Key()))
 			if !mv.IsValid() {
 				// Try case-insensitive lookup
 				iter := v.MapRange()
@@ -126,6 +146,235 @@ func (r *Reflect[O]) Get(path string) (any, error) {
 	return v.Interface(), nil
 }
 
+// expands reports whether segment s selects many elements of v.
+func (r *Reflect[O]) expands(v reflect.Value, s string) bool {
+	switch v.Kind() {
+	case reflect.Map:
+		return s == "*"
+	case reflect.Slice, reflect.Array:
+		lo, hi, ok := strings.Cut(s, ":")
+		return s == "*" || ok && strings.Trim(lo+hi, "0123456789") == ""
+	}
+	return false
+}
+
+// expand collects results of the remaining path for every element selected
+// by seg[i].
+func (r *Reflect[O]) expand(v reflect.Value, seg []string, i int) ([]any, error) {
+	var vv []reflect.Value
+	switch v.Kind() {
+	case reflect.Map:
+		kk := v.MapKeys()
+		slices.SortFunc(kk, func(a, b reflect.Value) int { return strings.Compare(a.String(), b.String()) })
+		for _, k := range kk {
+			vv = append(vv, v.MapIndex(k))
+		}
+	default:
+		lo, hi := 0, v.Len()
+		if a, b, ok := strings.Cut(seg[i], ":"); ok {
+			if a != "" {
+				lo, _ = strconv.Atoi(a)
+			}
+			if b != "" {
+				hi, _ = strconv.Atoi(b)
+			}
+			lo, hi = min(lo, v.Len()), min(hi, v.Len())
+		}
+		for n := lo; n < hi; n++ {
+			vv = append(vv, v.Index(n))
+		}
+	}
+	nested := slices.ContainsFunc(seg[i+1:], func(s string) bool { return s == "*" || strings.Contains(s, ":") })
+	out := make([]any, 0, len(vv))
+	for _, e := range vv {
+		x, err := r.get(e, seg, i+1)
+		if err != nil {
+			return nil, err
+		}
+		if xx, ok := x.([]any); ok && nested {
+			out = append(out, xx...)
+			continue
+		}
+		out = append(out, x)
+	}
+	return out, nil
+}
+
+// Set writes value at dot-separated path, symmetric to Get. Value is
+// converted to the type of destination with the same rules as Cast, nil
+// pointers and maps met on the way are allocated, index equal to the slice
+// length appends to it. The root must be a pointer, so changes are visible.
+//
+// Example:
+//
+//	var cfg Config
+//	err := ion.NewReflect(&cfg).Set("server.timeout", "30s")
+func (r *Reflect[O]) Set(path string, value any) error {
+	if r.value.Kind() != reflect.Pointer || r.value.IsNil() {
+		return Errorf("pathval: root must be a non-nil pointer, %s given", r.typ)
+	}
+	var seg []string
+	if path != "" {
+		seg = strings.Split(path, ".")
+	}
+	return r.set(r.value.Elem(), seg, 0, value)
+}
+
+func (r *Reflect[O]) set(v reflect.Value, seg []string, i int, value any) error {
+	for v.Kind() == reflect.Pointer && i < len(seg) {
+		if v.IsNil() {
+			if !v.CanSet() {
+				return fmt.Errorf("pathval: nil pointer not settable at %q", r.join(seg[:i]))
+			}
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		v = v.Elem()
+	}
+	if v.Kind() == reflect.Interface && !v.IsNil() && i < len(seg) {
+		// interfaces hold copies, change it and write it back
+		c := reflect.New(v.Elem().Type()).Elem()
+		c.Set(v.Elem())
+		if err := r.set(c, seg, i, value); err != nil {
+			return err
+		}
+		v.Set(c)
+		return nil
+	}
+	if i == len(seg) {
+		if !v.CanSet() {
+			return fmt.Errorf("pathval: value not settable at %q", r.join(seg))
+		}
+		c, err := castValue(value, v.Type())
+		if err != nil {
+			return fmt.Errorf("pathval: %w at %q", err, r.join(seg))
+		}
+		v.Set(c)
+		return nil
+	}
+
+	s := seg[i]
+	switch v.Kind() {
+	case reflect.Struct:
+		f, ok := r.field(v, s)
+		if !ok {
+			return r.nfErr("field", s, seg, i)
+		}
+		return r.set(f, seg, i+1, value)
+
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return fmt.Errorf("pathval: non-string map key at %q", r.join(seg[:i]))
+		}
+		if v.IsNil() {
+			if !v.CanSet() {
+				return fmt.Errorf("pathval: nil map not settable at %q", r.join(seg[:i]))
+			}
+			v.Set(reflect.MakeMap(v.Type()))
+		}
+		k := reflect.ValueOf(s).Convert(v.Type().Key())
+		for it := v.MapRange(); it.Next(); {
+			if it.Key().String() != s && strings.EqualFold(it.Key().String(), s) {
+				k = it.Key()
+			}
+		}
+		e := reflect.New(v.Type().Elem()).Elem()
+		if mv := v.MapIndex(k); mv.IsValid() {
+			e.Set(mv)
+		}
+		if err := r.set(e, seg, i+1, value); err != nil {
+			return err
+		}
+		v.SetMapIndex(k, e)
+		return nil
+
+	case reflect.Slice, reflect.Array:
+		idx, err := Cast[string, int](s)
+		if err != nil {
+			return fmt.Errorf("pathval: expected index got %q at %q", s, r.join(seg[:i]))
+		}
+		if idx == v.Len() && v.Kind() == reflect.Slice && v.CanSet() {
+			v.Set(reflect.Append(v, reflect.Zero(v.Type().Elem())))
+		}
+		if idx < 0 || idx >= v.Len() {
+			return fmt.Errorf("pathval: index %d out of range [%d]", idx, v.Len())
+		}
+		return r.set(v.Index(idx), seg, i+1, value)
+
+	default:
+		return fmt.Errorf("pathval: cannot descend into %s at %q", v.Kind(), r.join(seg[:i]))
+	}
+}
+
+// Walk visits every leaf value of the object (structs, maps in key order,
+// slices and arrays are descended into) with its path, the one Get and Set
+// accept. Nil pointers, maps and interfaces are visited as nil leaves, types
+// like time.Time or Enum, byte slices, JSON and Meta are leaves. Pointers
+// cycling back to values being walked are skipped. Returning false from fn
+// stops walking.
+//
+// Example:
+//
+//	ion.NewReflect(cfg).Walk(func(path string, v any) bool {
+//		fmt.Printf("%s = %v\n", path, v)
+//		return true
+//	})
+func (r *Reflect[O]) Walk(fn func(path string, value any) bool) {
+	r.walk(r.value, "", fn, map[reflectSeen]bool{})
+}
+
+// walk visits leaves of v, seen are pointers being walked, so cycles end.
+func (r *Reflect[O]) walk(v reflect.Value, path string, fn func(string, any) bool, seen map[reflectSeen]bool) bool {
+	if k, ok := reflectRef(v); ok {
+		if seen[k] {
+			return true
+		}
+		seen[k] = true
+		defer delete(seen, k)
+	}
+	if v = diffElem(v); !v.IsValid() {
+		return fn(path, nil)
+	}
+	if reflectLeaf(v.Type()) {
+		return fn(path, diffValue(v))
+	}
+	switch v.Kind() {
+	case reflect.Struct:
+		if mapConverts(v.Type()) {
+			break
+		}
+		for _, f := range reflect.VisibleFields(v.Type()) {
+			if !f.IsExported() || f.Anonymous {
+				continue
+			}
+			fv, err := v.FieldByIndexErr(f.Index)
+			if err != nil {
+				continue // promoted through nil embedded pointer
+			}
+			if !r.walk(fv, mapPath(path, f.Name), fn, seen) {
+				return false
+			}
+		}
+		return true
+	case reflect.Map:
+		kk := v.MapKeys()
+		slices.SortFunc(kk, func(a, b reflect.Value) int { return strings.Compare(fmt.Sprint(a), fmt.Sprint(b)) })
+		for _, k := range kk {
+			if !r.walk(v.MapIndex(k), mapPath(path, fmt.Sprint(k)), fn, seen) {
+				return false
+			}
+		}
+		return true
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if !r.walk(v.Index(i), mapPath(path, strconv.Itoa(i)), fn, seen) {
+				return false
+			}
+		}
+		return true
+	}
+	return fn(path, diffValue(v))
+}
+
 func (r *Reflect[O]) Info() string {
 	t := r.typ
 	// count and strip pointers
@@ -177,16 +426,34 @@ func (r *Reflect[O]) definition(v reflect.Value) (reflect.Value, error) {
 	}
 }
 
+// field finds exported field by its Go name, or by name given in one of
+// reflectTags, so paths written against wire formats (e.g. created_at) work.
 func (r *Reflect[O]) field(v reflect.Value, nm string) (reflect.Value, bool) {
+	return structField(v, nm)
+}
+
+func structField(v reflect.Value, nm string) (reflect.Value, bool) {
 	t := v.Type()
 	f, ok := t.FieldByNameFunc(func(s string) bool { return strings.EqualFold(s, nm) })
-
-	if !ok || f.PkgPath != "" { // not found or unexported
-		return reflect.Value{}, false
+	if ok && f.PkgPath == "" {
+		return v.FieldByIndex(f.Index), true
 	}
-	return v.FieldByIndex(f.Index), true
+	for _, f := range reflect.VisibleFields(t) {
+		if !f.IsExported() {
+			continue
+		}
+		for _, k := range reflectTags {
+			if n, _, _ := strings.Cut(f.Tag.Get(k), ","); n != "" && n != "-" && strings.EqualFold(n, nm) {
+				return v.FieldByIndex(f.Index), true
+			}
+		}
+	}
+	return reflect.Value{}, false
 }
 
+// reflectTags are struct tags resolving path segments besides field names.
+var reflectTags = []string{"json", "form", "db"}
+
 func (r *Reflect[O]) method(v reflect.Value, nm string) (reflect.Value, bool) {
 	t := v.Type()
 	for i := 0; i < t.NumMethod(); i++ {
@@ -231,18 +498,27 @@ func (r *Reflect[O]) join(ss []string) string {
 }
 
 // Cast tries to convert common Go types between each other.
-// Supported: string ↔ int, float64, bool, time.Time, time.Duration
+// Supported: string ↔ int, int64, float64, bool, time.Time, time.Duration,
+// uuid.UUID, *URL, Enum; string → []string (comma separated) and back;
+// int ↔ int64, float32, float64. Extra time layouts can be added with
+// UseTimeLayouts.
 // It won’t summon reflect demons — it uses type switches like a real Go dev.
-func Cast[FROM comparable, TO any](from FROM, isZero ...bool) (TO, error) {
+func Cast[FROM any, TO any](from FROM, isZero ...bool) (TO, error) {
 	var zero TO
 	var out any
 	var err error
 	if n := len(isZero); n > 0 && isZero[0] {
-		var f FROM
-		if from == f {
+		if v := reflect.ValueOf(from); !v.IsValid() || v.IsZero() {
 			return zero, Errorf("convert: zero value of type `%T` is empty", from)
 		}
 	}
+	if fn, ok := castFunc(reflect.TypeFor[FROM](), reflect.TypeFor[TO]()); ok {
+		o, err := fn(from)
+		if err != nil {
+			return zero, err
+		}
+		return o.(TO), nil
+	}
 	switch v := any(from).(type) {
 
 	// ---------- from STRING ----------
@@ -262,6 +538,20 @@ func Cast[FROM comparable, TO any](from FROM, isZero ...bool) (TO, error) {
 			out, err = parseTime(v)
 		case time.Duration:
 			out, err = time.ParseDuration(v)
+		case uuid.UUID:
+			out, err = uuid.Parse(v)
+		case *URL:
+			out, err = NewURL(v)
+		case []string:
+			var ss []string
+			for _, x := range strings.Split(v, ",") {
+				if x = strings.TrimSpace(x); x != "" {
+					ss = append(ss, x)
+				}
+			}
+			out = ss
+		case enum:
+			out, err = any(zero).(enum).parse(v)
 		default:
 			err = fmt.Errorf("convert: unsupported conversion string → %T", zero)
 		}
@@ -271,6 +561,10 @@ func Cast[FROM comparable, TO any](from FROM, isZero ...bool) (TO, error) {
 		switch any(zero).(type) {
 		case string:
 			out = strconv.Itoa(v)
+		case int64:
+			out = int64(v)
+		case float32:
+			out = float32(v)
 		case float64:
 			out = float64(v)
 		case bool:
@@ -281,6 +575,34 @@ func Cast[FROM comparable, TO any](from FROM, isZero ...bool) (TO, error) {
 			err = fmt.Errorf("convert: unsupported conversion int → %T", zero)
 		}
 
+	// ---------- from INT64 ----------
+	case int64:
+		switch any(zero).(type) {
+		case string:
+			out = strconv.FormatInt(v, 10)
+		case int:
+			out = int(v)
+		case float64:
+			out = float64(v)
+		case time.Duration:
+			out = time.Duration(v)
+		default:
+			err = fmt.Errorf("convert: unsupported conversion int64 → %T", zero)
+		}
+
+	// ---------- from FLOAT32 ----------
+	case float32:
+		switch any(zero).(type) {
+		case string:
+			out = strconv.FormatFloat(float64(v), 'g', -1, 32)
+		case int:
+			out = int(v)
+		case float64:
+			out = float64(v)
+		default:
+			err = fmt.Errorf("convert: unsupported conversion float32 → %T", zero)
+		}
+
 	// ---------- from FLOAT ----------
 	case float64:
 		switch any(zero).(type) {
@@ -337,6 +659,42 @@ func Cast[FROM comparable, TO any](from FROM, isZero ...bool) (TO, error) {
 			err = fmt.Errorf("convert: unsupported conversion time.Duration → %T", zero)
 		}
 
+	// ---------- from UUID, URL, []string ----------
+	case uuid.UUID:
+		switch any(zero).(type) {
+		case string:
+			out = v.String()
+		default:
+			err = fmt.Errorf("convert: unsupported conversion uuid.UUID → %T", zero)
+		}
+	case *URL:
+		switch any(zero).(type) {
+		case string:
+			if v != nil && v.URL != nil {
+				out = v.URL.String()
+			} else {
+				out = ""
+			}
+		default:
+			err = fmt.Errorf("convert: unsupported conversion *URL → %T", zero)
+		}
+	case []string:
+		switch any(zero).(type) {
+		case string:
+			out = strings.Join(v, ",")
+		default:
+			err = fmt.Errorf("convert: unsupported conversion []string → %T", zero)
+		}
+
+	// ---------- from ENUM ----------
+	case enum:
+		switch any(zero).(type) {
+		case string:
+			out = v.String()
+		default:
+			err = fmt.Errorf("convert: unsupported conversion %T → %T", from, zero)
+		}
+
 	default:
 		err = fmt.Errorf("convert: unsupported source type %T", from)
 	}
@@ -348,12 +706,164 @@ func Cast[FROM comparable, TO any](from FROM, isZero ...bool) (TO, error) {
 	return out.(TO), nil
 }
 
-func parseTime(s string) (time.Time, error) {
-	layouts := []string{
-		time.RFC3339,
-		"2006-01-02 15:04:05",
-		"2006-01-02",
+// castValue converts v to type t at runtime, delegating to Cast for times,
+// durations, UUIDs, URLs and string lists, and following its rules for
+// strings, numbers, booleans and enums of any kind. Conversions losing the
+// value, like fraction of float to int or negative number to unsigned one,
+// fail. Nil gives zero value.
+func castValue(v any, t reflect.Type) (reflect.Value, error) {
+	if v == nil {
+		return reflect.Zero(t), nil
+	}
+	rv := reflect.ValueOf(v)
+	if rv.Type().AssignableTo(t) {
+		return rv, nil
+	}
+	if fn, ok := castFunc(rv.Type(), t); ok {
+		o, err := fn(v)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		return reflect.ValueOf(o), nil
+	}
+	if castNumeric(rv.Kind()) && castNumeric(t.Kind()) {
+		return castNumber(rv, t)
+	}
+	if fn, ok := castTypes[t]; ok {
+		o, err := fn(v)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		return reflect.ValueOf(o), nil
+	}
+	if z, ok := reflect.Zero(t).Interface().(enum); ok {
+		if s, ok := v.(string); ok {
+			e, err := z.parse(s)
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			return reflect.ValueOf(e).Convert(t), nil
+		}
+	}
+	switch s, ok := v.(string); {
+	case ok && t.Kind() >= reflect.Int && t.Kind() <= reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, t.Bits())
+		return reflect.ValueOf(n).Convert(t), err
+	case ok && t.Kind() >= reflect.Uint && t.Kind() <= reflect.Uintptr:
+		n, err := strconv.ParseUint(s, 10, t.Bits())
+		return reflect.ValueOf(n).Convert(t), err
+	case ok && (t.Kind() == reflect.Float32 || t.Kind() == reflect.Float64):
+		n, err := strconv.ParseFloat(s, t.Bits())
+		return reflect.ValueOf(n).Convert(t), err
+	case ok && t.Kind() == reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		return reflect.ValueOf(b).Convert(t), err
+	case !ok && t.Kind() == reflect.String:
+		if s, err := Cast[any, string](v); err == nil {
+			return reflect.ValueOf(s).Convert(t), nil
+		}
+		if rv.Kind() >= reflect.Bool && rv.Kind() <= reflect.Float64 || rv.Kind() == reflect.String {
+			return reflect.ValueOf(fmt.Sprint(v)).Convert(t), nil
+		}
+	case rv.Type().ConvertibleTo(t) && rv.Kind() == t.Kind():
+		return rv.Convert(t), nil
 	}
+	return reflect.Value{}, fmt.Errorf("convert: unsupported conversion %T → %s", v, t)
+}
+
+// castTypes are conversions of Cast to types other than basic kinds.
+var castTypes = map[reflect.Type]func(any) (any, error){
+	reflect.TypeFor[time.Time]():     castTo[time.Time],
+	reflect.TypeFor[time.Duration](): castTo[time.Duration],
+	reflect.TypeFor[uuid.UUID]():     castTo[uuid.UUID],
+	reflect.TypeFor[*URL]():          castTo[*URL],
+	reflect.TypeFor[[]string]():      castTo[[]string],
+}
+
+func castTo[TO any](v any) (any, error) {
+	return Cast[any, TO](v)
+}
+
+// castNumeric tells if values of kind k are numbers.
+func castNumeric(k reflect.Kind) bool {
+	return k >= reflect.Int && k <= reflect.Float64
+}
+
+// castNumber converts number rv to numeric type t, failing when the value
+// does not fit, like fraction of float converted to int, or negative number
+// to unsigned one. Precision of floats converted between sizes is not checked.
+func castNumber(rv reflect.Value, t reflect.Type) (reflect.Value, error) {
+	c := rv.Convert(t)
+	float := func(k reflect.Kind) bool { return k == reflect.Float32 || k == reflect.Float64 }
+	if float(rv.Kind()) && float(t.Kind()) {
+		return c, nil
+	}
+	if castSign(c) != castSign(rv) || c.Convert(rv.Type()).Interface() != rv.Interface() {
+		return reflect.Value{}, fmt.Errorf("convert: %v does not fit %s", rv.Interface(), t)
+	}
+	return c, nil
+}
+
+// castSign returns -1, 0 or 1 for negative, zero and positive number v.
+func castSign(v reflect.Value) int {
+	var f float64
+	switch {
+	case v.CanInt():
+		f = float64(v.Int())
+	case v.CanUint():
+		f = float64(v.Uint())
+	default:
+		f = v.Float()
+	}
+	switch {
+	case f < 0:
+		return -1
+	case f > 0:
+		return 1
+	}
+	return 0
+}
+
+// RegisterCast adds conversion of application types (money, IDs, enums)
+// used by Cast, Reflect.Set, Map, SQL variables and JSON.To, nested fields
+// included, registered conversions take precedence over the built-in ones.
+//
+// Example:
+//
+//	ion.RegisterCast(func(s string) (Money, error) { return ParseMoney(s) })
+//	ion.RegisterCast(func(m Money) (int64, error) { return m.Cents(), nil })
+func RegisterCast[FROM, TO any](fn func(FROM) (TO, error)) {
+	castsMu.Lock()
+	defer castsMu.Unlock()
+	casts[[2]reflect.Type{reflect.TypeFor[FROM](), reflect.TypeFor[TO]()}] = func(v any) (any, error) {
+		return fn(v.(FROM))
+	}
+}
+
+func castFunc(from, to reflect.Type) (func(any) (any, error), bool) {
+	castsMu.RLock()
+	defer castsMu.RUnlock()
+	fn, ok := casts[[2]reflect.Type{from, to}]
+	return fn, ok
+}
+
+// UseTimeLayouts adds time layouts tried by Cast (and Reflect.Set) when
+// converting strings to time.Time, after the built-in RFC 3339, date-time
+// and date ones.
+//
+// Example:
+//
+//	ion.UseTimeLayouts("02.01.2006", time.RFC1123)
+func UseTimeLayouts(layouts ...string) {
+	timeLayoutsMu.Lock()
+	defer timeLayoutsMu.Unlock()
+	timeLayouts = slices.Concat(timeLayouts, layouts)
+}
+
+func parseTime(s string) (time.Time, error) {
+	timeLayoutsMu.RLock()
+	layouts := timeLayouts
+	timeLayoutsMu.RUnlock()
 	for _, l := range layouts {
 		if t, err := time.Parse(l, s); err == nil {
 			return t, nil
@@ -361,3 +871,10 @@ func parseTime(s string) (time.Time, error) {
 	}
 	return time.Time{}, Errorf("invalid time format")
 }
+
+var (
+	castsMu       sync.RWMutex
+	casts         = make(map[[2]reflect.Type]func(any) (any, error))
+	timeLayoutsMu sync.RWMutex
+	timeLayouts   = []string{time.RFC3339, "2006-01-02 15:04:05", "2006-01-02"}
+)
@@ -0,0 +1,133 @@
+package ion
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// Change is a single difference found by Diff. Old is nil when the value was
+// added, New is nil when it was removed.
+type Change struct {
+	Path string `json:"path"`
+	Old  any    `json:"old"`
+	New  any    `json:"new"`
+}
+
+func (c Change) String() string {
+	return fmt.Sprintf("%s: %v → %v", c.Path, c.Old, c.New)
+}
+
+// Diff compares a and b deeply and returns their differences addressed by
+// dot paths, the same ones Reflect.Get accepts: exported struct fields,
+// map keys and slice indexes. Values of different types, and types compared
+// as a whole like time.Time, byte slices, JSON and Meta, are reported as a
+// single change. Pointers cycling back to values being compared are skipped.
+//
+// Example:
+//
+//	for _, c := range ion.Diff(before, after) {
+//		audit.Printf("%s changed from %v to %v", c.Path, c.Old, c.New)
+//	}
+func Diff(a, b any) []Change {
+	var cc []Change
+	diff(reflect.ValueOf(a), reflect.ValueOf(b), "", &cc, map[[2]reflectSeen]bool{})
+	return cc
+}
+
+// diff compares a and b, seen are pointers of a and b being compared, so
+// cycles end.
+func diff(a, b reflect.Value, path string, cc *[]Change, seen map[[2]reflectSeen]bool) {
+	ra, aok := reflectRef(a)
+	rb, bok := reflectRef(b)
+	if k := [2]reflectSeen{ra, rb}; aok && bok {
+		if seen[k] {
+			return
+		}
+		seen[k] = true
+		defer delete(seen, k)
+	}
+	a, b = diffElem(a), diffElem(b)
+	switch {
+	case !a.IsValid() && !b.IsValid():
+		return
+	case !a.IsValid() || !b.IsValid() || a.Type() != b.Type():
+		*cc = append(*cc, Change{Path: diffPath(path), Old: diffValue(a), New: diffValue(b)})
+		return
+	case reflectLeaf(a.Type()):
+		if !reflect.DeepEqual(diffValue(a), diffValue(b)) {
+			*cc = append(*cc, Change{Path: diffPath(path), Old: diffValue(a), New: diffValue(b)})
+		}
+		return
+	}
+	switch a.Kind() {
+	case reflect.Struct:
+		if mapConverts(a.Type()) {
+			break
+		}
+		for _, f := range reflect.VisibleFields(a.Type()) {
+			if f.IsExported() && !f.Anonymous {
+				// invalid when promoted through nil embedded pointer, reported as nil
+				x, _ := a.FieldByIndexErr(f.Index)
+				y, _ := b.FieldByIndexErr(f.Index)
+				diff(x, y, mapPath(path, f.Name), cc, seen)
+			}
+		}
+		return
+	case reflect.Map:
+		var kk []reflect.Value
+		for _, m := range []reflect.Value{a, b} {
+			for _, k := range m.MapKeys() {
+				if !slices.ContainsFunc(kk, func(x reflect.Value) bool { return x.Equal(k) }) {
+					kk = append(kk, k)
+				}
+			}
+		}
+		slices.SortFunc(kk, func(x, y reflect.Value) int { return strings.Compare(fmt.Sprint(x), fmt.Sprint(y)) })
+		for _, k := range kk {
+			diff(a.MapIndex(k), b.MapIndex(k), mapPath(path, fmt.Sprint(k)), cc, seen)
+		}
+		return
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < max(a.Len(), b.Len()); i++ {
+			var x, y reflect.Value
+			if i < a.Len() {
+				x = a.Index(i)
+			}
+			if i < b.Len() {
+				y = b.Index(i)
+			}
+			diff(x, y, mapPath(path, fmt.Sprint(i)), cc, seen)
+		}
+		return
+	}
+	if !reflect.DeepEqual(diffValue(a), diffValue(b)) {
+		*cc = append(*cc, Change{Path: diffPath(path), Old: diffValue(a), New: diffValue(b)})
+	}
+}
+
+// diffElem dereferences pointers and interfaces, nil ones become invalid.
+func diffElem(v reflect.Value) reflect.Value {
+	for v.IsValid() && (v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface) {
+		if v.IsNil() {
+			return reflect.Value{}
+		}
+		v = v.Elem()
+	}
+	return v
+}
+
+func diffValue(v reflect.Value) any {
+	if !v.IsValid() || !v.CanInterface() {
+		return nil
+	}
+	return v.Interface()
+}
+
+func diffPath(path string) string {
+	if path == "" {
+		return "<root>"
+	}
+	return path
+}
@@ -0,0 +1,182 @@
+package ion
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Map copies from into a new TO value field by field, matching fields by
+// name or json, form and db tags case-insensitively, like Reflect does.
+// Nested structs, pointers, slices and maps are mapped recursively, other
+// values are converted with Cast rules. Source maps with string keys can fill
+// structs as well. Fields missing or zero in the source are left zero, as
+// are pointers cycling back to source values being mapped. All conversion
+// failures are returned together with their paths.
+//
+// Example:
+//
+//	type vendorOrder struct {
+//		OrderID string `json:"order_id"`
+//		Total   string `json:"total"`
+//	}
+//	type Order struct {
+//		ID    string  `json:"order_id"`
+//		Total float64
+//	}
+//	o, err := ion.Map[vendorOrder, Order](dto)
+func Map[FROM, TO any](from FROM) (TO, error) {
+	var to TO
+	var errs []error
+	mapValue(reflect.ValueOf(from), reflect.ValueOf(&to).Elem(), "", &errs, map[reflectSeen]bool{})
+	return to, errors.Join(errs...)
+}
+
+// mapValue copies from into to, seen are source pointers being mapped, so
+// cycles end. Maps are not tracked, as they are passed on as they are to
+// pointer destinations.
+func mapValue(from, to reflect.Value, path string, errs *[]error, seen map[reflectSeen]bool) {
+	if k, ok := reflectRef(from); ok && k.typ.Kind() == reflect.Pointer {
+		if seen[k] {
+			return
+		}
+		seen[k] = true
+		defer delete(seen, k)
+	}
+	for from.Kind() == reflect.Pointer || from.Kind() == reflect.Interface {
+		if from.IsNil() {
+			return
+		}
+		from = from.Elem()
+	}
+	if !from.IsValid() || from.IsZero() {
+		return
+	}
+	if from.Type().AssignableTo(to.Type()) {
+		to.Set(from)
+		return
+	}
+	switch to.Kind() {
+	case reflect.Pointer:
+		p := reflect.New(to.Type().Elem())
+		mapValue(from, p.Elem(), path, errs, seen)
+		to.Set(p)
+		return
+	case reflect.Struct:
+		if from.Kind() != reflect.Struct && from.Kind() != reflect.Map || mapConverts(to.Type()) {
+			break
+		}
+		for _, f := range reflect.VisibleFields(to.Type()) {
+			if !f.IsExported() || f.Anonymous {
+				continue
+			}
+			v, ok := mapSource(from, f)
+			if !ok {
+				continue
+			}
+			mapValue(v, to.FieldByIndex(f.Index), mapPath(path, f.Name), errs, seen)
+		}
+		return
+	case reflect.Slice:
+		if from.Kind() != reflect.Slice && from.Kind() != reflect.Array {
+			break
+		}
+		s := reflect.MakeSlice(to.Type(), from.Len(), from.Len())
+		for i := 0; i < from.Len(); i++ {
+			mapValue(from.Index(i), s.Index(i), mapPath(path, fmt.Sprint(i)), errs, seen)
+		}
+		to.Set(s)
+		return
+	case reflect.Map:
+		if from.Kind() != reflect.Map || !from.Type().Key().ConvertibleTo(to.Type().Key()) {
+			break
+		}
+		m := reflect.MakeMapWithSize(to.Type(), from.Len())
+		for it := from.MapRange(); it.Next(); {
+			e := reflect.New(to.Type().Elem()).Elem()
+			mapValue(it.Value(), e, mapPath(path, fmt.Sprint(it.Key())), errs, seen)
+			m.SetMapIndex(it.Key().Convert(to.Type().Key()), e)
+		}
+		to.Set(m)
+		return
+	}
+	v, err := castValue(from.Interface(), to.Type())
+	if err != nil {
+		if path == "" {
+			path = "<root>"
+		}
+		*errs = append(*errs, ErrMap.New("%s %w", path, err))
+		return
+	}
+	to.Set(v)
+}
+
+// mapSource finds value of the destination field f in a struct or map.
+func mapSource(from reflect.Value, f reflect.StructField) (reflect.Value, bool) {
+	nn := []string{f.Name}
+	for _, k := range reflectTags {
+		if n, _, _ := strings.Cut(f.Tag.Get(k), ","); n != "" && n != "-" {
+			nn = append(nn, n)
+		}
+	}
+	for _, n := range nn {
+		switch from.Kind() {
+		case reflect.Struct:
+			if v, ok := structField(from, n); ok {
+				return v, true
+			}
+		case reflect.Map:
+			if from.Type().Key().Kind() != reflect.String {
+				return reflect.Value{}, false
+			}
+			for it := from.MapRange(); it.Next(); {
+				if strings.EqualFold(it.Key().String(), n) {
+					return it.Value(), true
+				}
+			}
+		}
+	}
+	return reflect.Value{}, false
+}
+
+// mapConverts reports whether struct type t is a value converted as a whole
+// (e.g. time.Time or Enum) instead of field by field.
+func mapConverts(t reflect.Type) bool {
+	_, ok := reflect.Zero(t).Interface().(interface{ String() string })
+	return ok || t.Implements(jsonUnmarshaler) || reflect.PointerTo(t).Implements(jsonUnmarshaler)
+}
+
+// reflectLeaf reports whether values of t are not descended into, byte
+// slices like JSON, and Meta, which are data rather than structure.
+func reflectLeaf(t reflect.Type) bool {
+	return t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8 || t == reflect.TypeFor[Meta]()
+}
+
+// reflectSeen identifies pointer or map visited by reflect helpers, so
+// cyclic structures are not descended into forever.
+type reflectSeen struct {
+	ptr uintptr
+	typ reflect.Type
+}
+
+// reflectRef returns identity of v when it is non-nil pointer or map,
+// looking through interfaces.
+func reflectRef(v reflect.Value) (reflectSeen, bool) {
+	for v.IsValid() && v.Kind() == reflect.Interface && !v.IsNil() {
+		v = v.Elem()
+	}
+	if !v.IsValid() || v.Kind() != reflect.Pointer && v.Kind() != reflect.Map || v.IsNil() {
+		return reflectSeen{}, false
+	}
+	return reflectSeen{v.Pointer(), v.Type()}, true
+}
+
+func mapPath(path, s string) string {
+	if path == "" {
+		return s
+	}
+	return path + "." + s
+}
+
+var ErrMap = Errorf("map")
@@ -0,0 +1,381 @@
+package ion_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sokool/ion"
+)
+
+type reflectConfig struct {
+	Name    string
+	Timeout time.Duration
+	Port    int32
+	Server  *struct {
+		Hosts []string
+		Ratio float32
+	}
+	Labels map[string]string
+	Nested map[string]*struct{ On bool }
+	Any    any
+}
+
+func TestReflect_Set(t *testing.T) {
+	var c reflectConfig
+	r := ion.NewReflect(&c)
+	for path, value := range map[string]any{
+		"name":           "api",
+		"timeout":        "30s",
+		"port":           "8080",
+		"server.hosts.0": "a.example",
+		"server.ratio":   0.5,
+		"labels.env":     "prod",
+		"nested.x.on":    "true",
+	} {
+		if err := r.Set(path, value); err != nil {
+			t.Fatalf("set %s: %s", path, err)
+		}
+	}
+	if c.Name != "api" || c.Timeout != 30*time.Second || c.Port != 8080 || c.Server.Hosts[0] != "a.example" ||
+		c.Server.Ratio != 0.5 || c.Labels["env"] != "prod" || !c.Nested["x"].On {
+		t.Fatalf("unexpected config %+v %+v", c, c.Server)
+	}
+	if v, err := r.Get("labels.env"); err != nil || v != "prod" {
+		t.Fatalf("expected symmetric Get, got %v %v", v, err)
+	}
+	c.Any = map[string]any{"a": 1}
+	if err := r.Set("any.a", 2); err != nil || c.Any.(map[string]any)["a"] != 2 {
+		t.Fatalf("expected interface value updated, got %v %v", c.Any, err)
+	}
+	for _, p := range []string{"missing", "server.hosts.5", "port.x"} {
+		if err := r.Set(p, "1"); err == nil {
+			t.Fatalf("expected error for %s", p)
+		}
+	}
+	if err := r.Set("port", "abc"); err == nil {
+		t.Fatal("expected conversion error")
+	}
+	if err := ion.NewReflect(c).Set("name", "x"); err == nil {
+		t.Fatal("expected error of non-pointer root")
+	}
+	// numbers convert when they fit, otherwise fail instead of changing
+	if err := r.Set("port", 443.0); err != nil || c.Port != 443 {
+		t.Fatalf("expected port 443, got %d %v", c.Port, err)
+	}
+	if err := r.Set("timeout", 1e9); err != nil || c.Timeout != time.Second {
+		t.Fatalf("expected timeout 1s, got %s %v", c.Timeout, err)
+	}
+	if err := r.Set("port", 1.5); err == nil {
+		t.Fatal("expected float with fraction not set to int")
+	}
+	var u struct{ N uint8 }
+	for _, v := range []any{-1, 300, -0.5} {
+		if err := ion.NewReflect(&u).Set("n", v); err == nil {
+			t.Fatalf("expected %v not set to uint8, got %d", v, u.N)
+		}
+	}
+}
+
+func TestReflect_GetWildcard(t *testing.T) {
+	type item struct {
+		SKU   string
+		Price float64
+		Tags  []string
+	}
+	o := struct {
+		Items []item
+		Stock map[string]int
+	}{
+		Items: []item{{"A", 1.5, []string{"x"}}, {"B", 2, nil}, {"C", 3, []string{"y", "z"}}},
+		Stock: map[string]int{"b": 2, "a": 1},
+	}
+	r := ion.NewReflect(o)
+	for path, exp := range map[string]string{
+		"items.*.price":  "[1.5 2 3]",
+		"items.1:.sku":   "[B C]",
+		"items.:2.sku":   "[A B]",
+		"items.*.tags.*": "[x y z]",
+		"stock.*":        "[1 2]",
+		"items.5:.sku":   "[]",
+	} {
+		v, err := r.Get(path)
+		if err != nil || fmt.Sprint(v) != exp {
+			t.Fatalf("expected %s at %s, got %v %v", exp, path, v, err)
+		}
+	}
+	if _, err := r.Get("items.*.missing"); err == nil {
+		t.Fatal("expected error of missing field")
+	}
+}
+
+func TestReflect_Tags(t *testing.T) {
+	type audit struct {
+		CreatedAt time.Time `json:"created_at,omitempty"`
+	}
+	type user struct {
+		audit
+		ID    string `db:"user_id"`
+		Email string `form:"e-mail" json:"-"`
+	}
+	u := user{audit: audit{CreatedAt: time.Unix(0, 0)}, ID: "u1", Email: "a@b.c"}
+	r := ion.NewReflect(&u)
+	for path, exp := range map[string]any{"created_at": time.Unix(0, 0), "user_id": "u1", "e-mail": "a@b.c", "email": "a@b.c"} {
+		if v, err := r.Get(path); err != nil || v != exp {
+			t.Fatalf("expected %v at %s, got %v %v", exp, path, v, err)
+		}
+	}
+	if err := r.Set("user_id", "u2"); err != nil || u.ID != "u2" {
+		t.Fatalf("expected id set by db tag, got %s %v", u.ID, err)
+	}
+}
+
+func TestCast(t *testing.T) {
+	id := "8f14e45f-ceea-467f-a9a5-3d3d1b9d1a11"
+	if u, err := ion.Cast[string, uuid.UUID](id); err != nil || u.String() != id {
+		t.Fatalf("unexpected uuid %v %v", u, err)
+	}
+	if _, err := ion.Cast[string, uuid.UUID]("nope"); err == nil {
+		t.Fatal("expected invalid uuid error")
+	}
+	u, err := ion.Cast[string, *ion.URL]("https://example.com/a?b=1")
+	if err != nil || u.Host != "example.com" {
+		t.Fatalf("unexpected url %v %v", u, err)
+	}
+	if s, err := ion.Cast[*ion.URL, string](u); err != nil || s != "https://example.com/a?b=1" {
+		t.Fatalf("unexpected url string %s %v", s, err)
+	}
+	if ss, err := ion.Cast[string, []string](" a, b,,c "); err != nil || fmt.Sprint(ss) != "[a b c]" {
+		t.Fatalf("unexpected split %v %v", ss, err)
+	}
+	if s, err := ion.Cast[[]string, string]([]string{"a", "b"}); err != nil || s != "a,b" {
+		t.Fatalf("unexpected join %s %v", s, err)
+	}
+	if n, err := ion.Cast[int, int64](7); err != nil || n != 7 {
+		t.Fatalf("unexpected int64 %v %v", n, err)
+	}
+	if n, err := ion.Cast[int64, int](7); err != nil || n != 7 {
+		t.Fatalf("unexpected int %v %v", n, err)
+	}
+	if f, err := ion.Cast[int, float32](3); err != nil || f != 3 {
+		t.Fatalf("unexpected float32 %v %v", f, err)
+	}
+	if _, err := ion.Cast[[]string, string](nil, true); err == nil {
+		t.Fatal("expected zero value error")
+	}
+	if _, err := ion.Cast[string, time.Time]("17.10.2026"); err == nil {
+		t.Fatal("expected unknown layout error")
+	}
+	ion.UseTimeLayouts("02.01.2006")
+	if tm, err := ion.Cast[string, time.Time]("17.10.2026"); err != nil || tm.Month() != time.October {
+		t.Fatalf("unexpected time %v %v", tm, err)
+	}
+}
+
+func TestMap(t *testing.T) {
+	type vendorLine struct {
+		Code string `json:"sku_code"`
+		Qty  string
+	}
+	type vendorOrder struct {
+		OrderID  string `json:"order_id"`
+		Total    string
+		Created  string
+		Lines    []vendorLine
+		Customer map[string]any
+	}
+	type line struct {
+		SKU string `json:"sku_code"`
+		Qty int
+	}
+	type order struct {
+		ID       string `db:"order_id"`
+		Total    float64
+		Created  time.Time
+		Lines    []line
+		Customer *struct{ Name string }
+		Missing  string
+	}
+	o, err := ion.Map[vendorOrder, order](vendorOrder{
+		OrderID: "o1", Total: "12.5", Created: "2026-10-17",
+		Lines:    []vendorLine{{"A", "2"}},
+		Customer: map[string]any{"name": "Tom"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if o.ID != "o1" || o.Total != 12.5 || o.Created.Day() != 17 || o.Lines[0].SKU != "A" || o.Lines[0].Qty != 2 || o.Customer.Name != "Tom" {
+		t.Fatalf("unexpected mapping %+v", o)
+	}
+	_, err = ion.Map[vendorOrder, order](vendorOrder{Total: "x", Lines: []vendorLine{{Qty: "y"}}})
+	if !ion.ErrMap.In(err) || !strings.Contains(err.Error(), "Lines.0.Qty") || !strings.Contains(err.Error(), "Total") {
+		t.Fatalf("expected path errors, got %v", err)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	type user struct {
+		Name  string
+		Tags  []string
+		Attrs map[string]any
+		At    time.Time
+		Boss  *user
+	}
+	a := user{Name: "Tom", Tags: []string{"a"}, Attrs: map[string]any{"x": 1, "y": 2}, At: time.Unix(0, 0)}
+	b := user{Name: "Tom", Tags: []string{"a", "b"}, Attrs: map[string]any{"x": 1, "z": 3}, At: time.Unix(1, 0), Boss: &user{Name: "Ann"}}
+	exp := "[Tags.1: <nil> → b Attrs.y: 2 → <nil> Attrs.z: <nil> → 3 At: 1970-01-01 00:00:00 +0000 UTC → 1970-01-01 00:00:01 +0000 UTC Boss: <nil> → {Ann [] map[] 0001-01-01 00:00:00 +0000 UTC <nil>}]"
+	cc := ion.Diff(a, b)
+	for i := range cc {
+		if tm, ok := cc[i].Old.(time.Time); ok {
+			cc[i].Old, cc[i].New = tm.UTC(), cc[i].New.(time.Time).UTC()
+		}
+	}
+	if s := fmt.Sprint(cc); s != exp {
+		t.Fatalf("expected\n%s\ngot\n%s", exp, s)
+	}
+	if cc := ion.Diff(a, a); len(cc) != 0 {
+		t.Fatalf("expected no changes, got %v", cc)
+	}
+	for _, c := range ion.Diff(a, b) {
+		if _, err := ion.NewReflect(b).Get(c.Path); err != nil && c.New != nil {
+			t.Fatalf("path %s not readable by Reflect: %s", c.Path, err)
+		}
+	}
+}
+
+type money struct{ cents int64 }
+
+func TestRegisterCast(t *testing.T) {
+	ion.RegisterCast(func(s string) (money, error) {
+		f, err := ion.Cast[string, float64](s)
+		return money{int64(f * 100)}, err
+	})
+	ion.RegisterCast(func(f float64) (money, error) { return money{int64(f * 100)}, nil })
+	if m, err := ion.Cast[string, money]("12.34"); err != nil || m.cents != 1234 {
+		t.Fatalf("unexpected money %v %v", m, err)
+	}
+	var p struct{ Price money }
+	if err := ion.NewReflect(&p).Set("price", "1.5"); err != nil || p.Price.cents != 150 {
+		t.Fatalf("unexpected Reflect.Set money %v %v", p, err)
+	}
+	var m money
+	if err := ion.JSON(`{"price":2.5}`).Select("price").To(&m); err != nil || m.cents != 250 {
+		t.Fatalf("unexpected JSON.To money %v %v", m, err)
+	}
+	var o struct {
+		Name  string
+		Items []struct {
+			Price money `json:"price"`
+		} `json:"items"`
+		Total *money
+		Tax   map[string]money `json:"tax"`
+	}
+	err := ion.JSON(`{"name":"A1","items":[{"price":"1.25"},{"price":3}],"total":4.25,"tax":{"vat":"0.5"}}`).To(&o)
+	if err != nil || o.Name != "A1" || len(o.Items) != 2 || o.Items[0].Price.cents != 125 || o.Items[1].Price.cents != 300 ||
+		o.Total == nil || o.Total.cents != 425 || o.Tax["vat"].cents != 50 {
+		t.Fatalf("unexpected JSON.To nested money %+v %v", o, err)
+	}
+	if _, err := ion.Cast[string, money]("x"); err == nil {
+		t.Fatal("expected conversion error")
+	}
+}
+
+func TestReflect_Walk(t *testing.T) {
+	v := struct {
+		Name  string
+		At    time.Time
+		Tags  []string
+		Attrs map[string]int
+		Next  *struct{ ID int }
+	}{Name: "a", Tags: []string{"x", "y"}, Attrs: map[string]int{"b": 2, "a": 1}}
+	var out []string
+	ion.NewReflect(&v).Walk(func(p string, x any) bool {
+		if _, ok := x.(time.Time); ok {
+			x = "time"
+		}
+		out = append(out, fmt.Sprintf("%s=%v", p, x))
+		return true
+	})
+	if s := strings.Join(out, " "); s != "Name=a At=time Tags.0=x Tags.1=y Attrs.a=1 Attrs.b=2 Next=<nil>" {
+		t.Fatalf("unexpected walk %s", s)
+	}
+	n := 0
+	ion.NewReflect(v).Walk(func(string, any) bool { n++; return n < 3 })
+	if n != 3 {
+		t.Fatalf("expected walk stopped after 3 leaves, got %d", n)
+	}
+}
+
+type reflectBase struct{ ID int }
+
+func TestReflect_WalkLeaves(t *testing.T) {
+	v := struct {
+		*reflectBase
+		Body ion.JSON
+		Meta ion.Meta
+	}{Body: ion.JSON(`{"a":1}`), Meta: ion.Meta{"a": 1}}
+	var out []string
+	ion.NewReflect(&v).Walk(func(p string, x any) bool {
+		out = append(out, fmt.Sprintf("%s=%T", p, x))
+		return true
+	})
+	if s := strings.Join(out, " "); s != "Body=ion.JSON Meta=ion.Meta" {
+		t.Fatalf("unexpected walk %s", s)
+	}
+}
+
+func TestDiff_NilEmbedded(t *testing.T) {
+	type item struct {
+		*reflectBase
+		Name string
+	}
+	cc := ion.Diff(item{Name: "a"}, item{reflectBase: &reflectBase{ID: 1}, Name: "a"})
+	if len(cc) != 1 || cc[0].Path != "ID" || cc[0].Old != nil || cc[0].New != 1 {
+		t.Fatalf("unexpected changes %v", cc)
+	}
+	if cc = ion.Diff(item{}, item{}); len(cc) != 0 {
+		t.Fatalf("expected no changes, got %v", cc)
+	}
+}
+
+// reflectNode is a cyclic structure, Next may point back to the node.
+type reflectNode struct {
+	Name string
+	Next *reflectNode
+}
+
+func TestDiff_LeavesAndCycles(t *testing.T) {
+	type doc struct{ Body ion.JSON }
+	cc := ion.Diff(doc{Body: ion.JSON(`{"a":1}`)}, doc{Body: ion.JSON(`{"a":2}`)})
+	if len(cc) != 1 || cc[0].Path != "Body" {
+		t.Fatalf("expected JSON compared as a whole, got %v", cc)
+	}
+	a, b := &reflectNode{Name: "a"}, &reflectNode{Name: "b"}
+	a.Next, b.Next = a, b
+	if cc = ion.Diff(a, b); len(cc) != 1 || cc[0].Path != "Name" {
+		t.Fatalf("expected cycle compared once, got %v", cc)
+	}
+}
+
+func TestReflect_Cycles(t *testing.T) {
+	a := &reflectNode{Name: "a"}
+	a.Next = &reflectNode{Name: "b", Next: a}
+	var out []string
+	ion.NewReflect(a).Walk(func(p string, x any) bool {
+		out = append(out, fmt.Sprintf("%s=%v", p, x))
+		return true
+	})
+	if s := strings.Join(out, " "); s != "Name=a Next.Name=b" {
+		t.Fatalf("unexpected walk of cycle %s", s)
+	}
+	type node struct {
+		Name string
+		Next *node
+	}
+	n, err := ion.Map[*reflectNode, node](a)
+	if err != nil || n.Name != "a" || n.Next == nil || n.Next.Name != "b" || n.Next.Next != nil {
+		t.Fatalf("expected cycle mapped once, got %+v %v", n, err)
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"hash/fnv"
 	"math"
+	"reflect"
 	"regexp"
 	"strconv"
 	"strings"
@@ -43,7 +44,9 @@ func (s SQL[T]) Write(c context.Context, tt ...T) error {
 	}
 	cxt := c
 	if cxt == nil {
-		cxt, _ = context.WithTimeout(ctx, time.Second*5)
+		var done context.CancelFunc
+		cxt, done = context.WithTimeout(ctx, time.Second*5)
+		defer done()
 	}
 	for _, t := range tt {
 		qry, args, err := s.query(t)
@@ -74,6 +77,52 @@ func (s SQL[T]) One(ctx context.Context, params any) (T, error) {
 	return t, nil
 }
 
+// Page reads single page of rows described by q, together with the total
+// number of rows returned by the query. The query is wrapped, so it must not
+// contain its own LIMIT/OFFSET clauses.
+func (s SQL[T]) Page(c context.Context, q PageQuery, params any) (Page[T], error) {
+	var p Page[T]
+	qry, pms, err := s.query(params)
+	if err != nil {
+		return p, err
+	}
+	n, cnt := len(pms), sqlCountQuery(qry)
+	qry = fmt.Sprintf("SELECT * FROM (%s) AS page LIMIT $%d OFFSET $%d", qry, n+1, n+2)
+	var tt []T
+	if err = s.rows(c, params, qry, append(pms, q.Limit, q.Offset), func(t T) error { tt = append(tt, t); return nil }); err != nil {
+		return p, err
+	}
+	var total int
+	if !InUnitTests() {
+		db, err := SQLConnection(ctx)
+		if err != nil {
+			return p, err
+		}
+		cx := c
+		if cx == nil {
+			cx = ctx
+		}
+		if total, err = sqlCount(cx, db, cnt, pms); err != nil {
+			return p, err
+		}
+	}
+	return NewPage(tt, total, q), nil
+}
+
+// sqlCountQuery returns query counting rows of qry.
+func sqlCountQuery(qry string) string {
+	return fmt.Sprintf("SELECT count(*) FROM (%s) AS page", qry)
+}
+
+// sqlCount runs count query of sqlCountQuery.
+func sqlCount(ctx context.Context, db *SQLDB, qry string, pms []any) (int, error) {
+	var n int
+	if err := db.QueryRowContext(ctx, qry, pms...).Scan(&n); err != nil {
+		return 0, ErrSQL.Wrap(err)
+	}
+	return n, nil
+}
+
 func (s SQL[T]) String() string {
 	return ""
 }
@@ -121,14 +170,20 @@ func (s SQL[T]) hash() uint64 {
 }
 
 func (s SQL[T]) scan(c context.Context, params any, to func(T) error) error {
-	n := time.Now()
-	if c == nil {
-		c, _ = context.WithTimeout(ctx, time.Second*5)
-	}
 	qry, pms, err := s.query(params)
 	if err != nil {
 		return err
 	}
+	return s.rows(c, params, qry, pms, to)
+}
+
+func (s SQL[T]) rows(c context.Context, params any, qry string, pms []any, to func(T) error) error {
+	n := time.Now()
+	if c == nil {
+		var done context.CancelFunc
+		c, done = context.WithTimeout(ctx, time.Second*5)
+		defer done()
+	}
 	if InUnitTests() {
 		return nil
 	}
@@ -158,7 +213,7 @@ func (s SQL[T]) scan(c context.Context, params any, to func(T) error) error {
 	m := time.Since(n).String()
 	var t T
 	x := NewReflect(t).Name()
-	log_.Trace(4).Debugf(x+": found %d in time of %s %v", i, m, params)
+	log_.Trace(5).Debugf(x+": found %d in time of %s %v", i, m, params)
 	Metrics.Percentile("sql_read_in_seconds{name=%q}", time.Since(n).Seconds(), x)
 	return nil
 }
@@ -380,6 +435,13 @@ func (f *scanner[T]) Scan(src any) error {
 	}
 }
 
+// sqlValueTypes are driver value types tried with conversions registered
+// by RegisterCast.
+var sqlValueTypes = []reflect.Type{
+	reflect.TypeFor[int64](), reflect.TypeFor[float64](), reflect.TypeFor[bool](),
+	reflect.TypeFor[string](), reflect.TypeFor[[]byte](), reflect.TypeFor[time.Time](),
+}
+
 // Val is a generic wrapper that implements driver.Valuer for any T.
 type valuer struct {
 	V any
@@ -387,6 +449,17 @@ type valuer struct {
 
 // Value converts the underlying T into driver.Value.
 func (w valuer) Value() (driver.Value, error) {
+	if w.V != nil {
+		for _, t := range sqlValueTypes {
+			if fn, ok := castFunc(reflect.TypeOf(w.V), t); ok {
+				v, err := fn(w.V)
+				if err != nil {
+					return nil, err
+				}
+				return valuer{v}.Value()
+			}
+		}
+	}
 	switch v := w.V.(type) {
 	case nil:
 		return nil, nil
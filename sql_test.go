@@ -1,7 +1,69 @@
 package ion
 
-import "testing"
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"slices"
+	"testing"
+)
 
 func TestSQL(t *testing.T) {
 
 }
+
+func TestSQL_PageCount(t *testing.T) {
+	d := &sqlFake{count: 42}
+	sql.Register("ion-page-count", d)
+	db, err := sql.Open("ion-page-count", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	qry, pms, err := SQL[any]("SELECT id FROM orders WHERE shop = ${shop}").query(map[string]any{"shop": "a1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	n, err := sqlCount(context.Background(), db, sqlCountQuery(qry), pms)
+	if err != nil || n != 42 {
+		t.Fatalf("expected 42 rows counted, got %d %v", n, err)
+	}
+	if d.query != "SELECT count(*) FROM (SELECT id FROM orders WHERE shop = $1) AS page" || !slices.Equal(d.args, []driver.Value{"a1"}) {
+		t.Fatalf("unexpected count query %q %v", d.query, d.args)
+	}
+}
+
+// sqlFake is database/sql driver answering every query with count, it
+// keeps the last query and its arguments.
+type sqlFake struct {
+	count int64
+	query string
+	args  []driver.Value
+}
+
+func (d *sqlFake) Open(string) (driver.Conn, error)           { return d, nil }
+func (d *sqlFake) Prepare(q string) (driver.Stmt, error)      { d.query = q; return d, nil }
+func (d *sqlFake) Close() error                               { return nil }
+func (d *sqlFake) Begin() (driver.Tx, error)                  { return nil, driver.ErrSkip }
+func (d *sqlFake) NumInput() int                              { return -1 }
+func (d *sqlFake) Exec([]driver.Value) (driver.Result, error) { return nil, driver.ErrSkip }
+func (d *sqlFake) Query(args []driver.Value) (driver.Rows, error) {
+	d.args = args
+	return &sqlFakeRows{n: d.count}, nil
+}
+
+type sqlFakeRows struct {
+	n    int64
+	done bool
+}
+
+func (r *sqlFakeRows) Columns() []string { return []string{"count"} }
+func (r *sqlFakeRows) Close() error      { return nil }
+func (r *sqlFakeRows) Next(dest []driver.Value) error {
+	if r.done {
+		return io.EOF
+	}
+	r.done, dest[0] = true, r.n
+	return nil
+}
@@ -2,10 +2,10 @@ package ion
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
 	"fmt"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -20,16 +20,15 @@ type Store interface {
 	//todo transform it to Read or something letting to travers through key pattern values
 	Keys(pattern string) ([]string, error)
 
-	// Disable
-	// todo it's temporary :) to fullfill current requirements
-	Disable(ctx context.Context) context.Context
-
 	//todo
 	Delete(ctx context.Context, key string) error
 }
 
 // UseStore sets the provided Store implementation as the global app storage system.
 func UseStore(m Store) {
+	if n, ok := m.(*namespace); ok && n.store == nil {
+		n.store = Cache // namespace of itself would recurse
+	}
 	Cache = m
 }
 
@@ -43,29 +42,43 @@ func UseStore(m Store) {
 //   - args: Optional format arguments for the key string
 //
 // Returns:
-//   - int: Number of bytes read from storage, 0 if key not found, -1 if error occurred
+//   - int: Number of bytes read from storage, 0 if key not found or cache is
+//     bypassed with WithoutCache or RefreshCache, -1 if error occurred
+//
+// Hits, misses and time of reads are counted in cache_hits_total,
+// cache_misses_total and cache_get_seconds metrics, labeled by key prefix,
+// the part before the first colon, ie. cache_hits_total{prefix="rest"}.
 func Get[T any](ctx context.Context, key string, value T, args ...any) int {
 	key = fmt.Sprintf(key, args...)
+	if cacheMode(ctx) != cacheDefault {
+		return 0
+	}
+	now, p := time.Now(), cachePrefix(key)
 	b, err := Cache.Get(ctx, key)
+	Metrics.Percentile("cache_get_seconds{prefix=%q}", time.Since(now).Seconds(), p)
 	switch {
 	case errors.Is(err, context.Canceled):
 		return -1
 	case err != nil:
+		Metrics.Count("cache_errors_total{prefix=%q}", 1, p)
 		log_.Errorf("Store: get %q failed due %s", key, err)
 		return -1
 	}
 	n := len(b)
 	if n == 0 {
+		Metrics.Count("cache_misses_total{prefix=%q}", 1, p)
 		return 0
 	}
-	if err = json.Unmarshal(b, value); err != nil {
+	Metrics.Count("cache_hits_total{prefix=%q}", 1, p)
+	if err = codecOf(key).Unmarshal(b, value); err != nil {
 		log_.Errorf("Store: get %q failed due %s", key, err)
 		return -1
 	}
 	return n
 }
 
-// Set stores a value in storage under the given key after marshaling it to JSON.
+// Set stores a value in storage under the given key after marshaling it with
+// Codec of the key, JSON by default, see UseCodec.
 // It accepts a generic type T which is used for marshaling the data.
 //
 // Parameters:
@@ -75,58 +88,180 @@ func Get[T any](ctx context.Context, key string, value T, args ...any) int {
 //   - ttl: Optional time-to-live duration(s). Multiple durations will be summed
 //
 // Returns:
-//   - int: Number of bytes written to storage, -1 if error occurred
+//   - int: Number of bytes written to storage, 0 if cache is bypassed with
+//     WithoutCache, -1 if error occurred
+//
+// Time of writes is counted in cache_set_seconds metric, labeled by key
+// prefix as in Get.
 func Set[T any](ctx context.Context, key string, value T, ttl ...time.Duration) int {
-	b, err := json.Marshal(value)
+	b, err := codecOf(key).Marshal(value)
 	if err != nil {
 		log_.Errorf("Store: set %q failed due %s", key, err)
 		return -1
 	}
+	if cacheMode(ctx) == cacheBypass {
+		return 0
+	}
 	s := len(b)
 	var d time.Duration
 	for i := range ttl {
 		d += ttl[i]
 	}
-	switch err = Cache.Set(ctx, key, b, d); {
+	now := time.Now()
+	err = Cache.Set(ctx, key, b, d)
+	Metrics.Percentile("cache_set_seconds{prefix=%q}", time.Since(now).Seconds(), cachePrefix(key))
+	switch {
 	case errors.Is(err, context.Canceled):
 		s = -1
 	case err != nil:
 		s = -1
+		Metrics.Count("cache_errors_total{prefix=%q}", 1, cachePrefix(key))
 		log_.Errorf("Store: set %q failed due %s", key, err)
 	}
 	return s
 }
 
-type memory map[string][]byte
+// GetOrSet returns value stored under the key, or computes it, stores it
+// for ttl and returns it. Concurrent calls for the same key wait for a single
+// computation, in other processes as well when a distributed Locker is used,
+// see UseLocker. Error of compute is returned and nothing is stored.
+//
+// Example:
+//
+//	rate, err := ion.GetOrSet(ctx, "fx:EUR", time.Hour, func() (float64, error) {
+//		return fx.Rate(ctx, "EUR")
+//	})
+func GetOrSet[T any](ctx context.Context, key string, ttl time.Duration, compute func() (T, error)) (T, error) {
+	var v T
+	if Get(ctx, "%s", &v, key) > 0 {
+		return v, nil
+	}
+	flights.mu.Lock()
+	if f, ok := flights.calls[key]; ok {
+		flights.mu.Unlock()
+		<-f.done
+		if f.err != nil {
+			return v, f.err
+		}
+		v, _ = f.value.(T)
+		return v, nil
+	}
+	f := &flight{done: make(chan struct{})}
+	if flights.calls == nil {
+		flights.calls = make(map[string]*flight)
+	}
+	flights.calls[key] = f
+	flights.mu.Unlock()
+	defer func() {
+		p := recover()
+		if p != nil {
+			f.err = ErrCache.New("%s compute panicked: %v", key, p)
+		}
+		flights.mu.Lock()
+		delete(flights.calls, key)
+		flights.mu.Unlock()
+		close(f.done)
+		if p != nil {
+			panic(p) // rethrow panic
+		}
+	}()
+	mu := NewLocker(ctx, "cache:compute:"+key)
+	mu.Lock()
+	defer mu.Unlock()
+	if Get(ctx, "%s", &v, key) > 0 { // computed by other process meanwhile
+		f.value = v
+		return v, nil
+	}
+	if v, f.err = compute(); f.err != nil {
+		return v, f.err
+	}
+	f.value = v
+	Set(ctx, key, v, ttl)
+	return v, nil
+}
+
+// cachePrefix returns label of the key in cache metrics, its part before the
+// first colon.
+func cachePrefix(key string) string {
+	p, _, ok := strings.Cut(key, ":")
+	if !ok || p == "" {
+		return "none"
+	}
+	return p
+}
+
+// flight is computation of GetOrSet awaited by concurrent calls of the key.
+type flight struct {
+	done  chan struct{}
+	value any
+	err   error
+}
+
+var flights struct {
+	mu    sync.Mutex
+	calls map[string]*flight
+}
+
+type memory struct {
+	mu      sync.RWMutex
+	data    map[string][]byte
+	exp     map[string]time.Time // expiration of keys stored with ttl
+	janitor sync.Once
+}
 
-func (s memory) Delete(ctx context.Context, key string) error {
-	delete(s, key)
+func (s *memory) Delete(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.data, key)
+	delete(s.exp, key)
 	return nil
 }
 
-func (s memory) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
-	s[key] = value
+func (s *memory) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.set(key, value, ttl)
 	return nil
 }
 
-func (s memory) Get(ctx context.Context, key string) ([]byte, error) {
-	v, ok := s[key]
-	if !ok {
-		return nil, nil
-	}
+func (s *memory) Get(ctx context.Context, key string) ([]byte, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	v, _ := s.get(key)
 	return v, nil
 }
 
-func (s memory) Keys(pattern string) ([]string, error) {
+func (s *memory) Keys(pattern string) ([]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
 	var keys []string
-	for k := range s {
-		if strings.HasPrefix(k, pattern) {
+	for k := range s.data {
+		if _, ok := s.get(k); ok && strings.HasPrefix(k, pattern) {
 			keys = append(keys, k)
 		}
 	}
 	return keys, nil
 }
 
-func (s memory) Disable(ctx context.Context) context.Context {
-	return ctx
+// get returns value of the key, false when missing or expired.
+func (s *memory) get(key string) ([]byte, bool) {
+	v, ok := s.data[key]
+	if e, exp := s.exp[key]; ok && exp && !time.Now().Before(e) {
+		return nil, false
+	}
+	return v, ok
+}
+
+// set stores value of the key, expiring after ttl when positive.
+func (s *memory) set(key string, value []byte, ttl time.Duration) {
+	if s.data == nil {
+		s.data, s.exp = make(map[string][]byte), make(map[string]time.Time)
+	}
+	s.data[key] = value
+	if ttl <= 0 {
+		delete(s.exp, key)
+		return
+	}
+	s.exp[key] = time.Now().Add(ttl)
+	s.janitor.Do(func() { go s.sweep() })
 }
@@ -0,0 +1,144 @@
+package ion
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// BatchStore is Store reading and writing many keys in one round trip, ie.
+// with Redis MGET or pipeline. GetMany and SetMany fall back to a loop of
+// Get and Set calls for Store not implementing it.
+type BatchStore interface {
+	Store
+
+	// GetMany returns values of the keys in their order, nil for missing.
+	GetMany(ctx context.Context, keys []string) ([][]byte, error)
+
+	// SetMany stores all values with the same expiration duration.
+	SetMany(ctx context.Context, values map[string][]byte, ttl time.Duration) error
+}
+
+// GetMany retrieves values of many keys from storage at once and unmarshals
+// them into a map by key, missing keys and values failing to unmarshal are
+// left out. Returns nil on error, which is logged.
+//
+// Example:
+//
+//	chats := ion.GetMany[Chat](ctx, "chat:1", "chat:2", "chat:3")
+func GetMany[T any](ctx context.Context, keys ...string) map[string]T {
+	if cacheMode(ctx) != cacheDefault {
+		return map[string]T{}
+	}
+	bb, err := storeGetMany(ctx, Cache, keys)
+	switch {
+	case errors.Is(err, context.Canceled):
+		return nil
+	case err != nil:
+		log_.Errorf("Store: get %d keys failed due %s", len(keys), err)
+		return nil
+	}
+	m := make(map[string]T, len(keys))
+	for i, b := range bb {
+		if len(b) == 0 {
+			continue
+		}
+		var v T
+		if err = codecOf(keys[i]).Unmarshal(b, &v); err != nil {
+			log_.Errorf("Store: get %q failed due %s", keys[i], err)
+			continue
+		}
+		m[keys[i]] = v
+	}
+	return m
+}
+
+// SetMany stores values in storage under their keys after marshaling them
+// with their Codec, ttl durations are summed as in Set.
+//
+// Returns:
+//   - int: Number of bytes written to storage, -1 if error occurred
+func SetMany[T any](ctx context.Context, values map[string]T, ttl ...time.Duration) int {
+	bb, s := make(map[string][]byte, len(values)), 0
+	for k, v := range values {
+		b, err := codecOf(k).Marshal(v)
+		if err != nil {
+			log_.Errorf("Store: set %q failed due %s", k, err)
+			return -1
+		}
+		bb[k], s = b, s+len(b)
+	}
+	if cacheMode(ctx) == cacheBypass {
+		return 0
+	}
+	d := storeTTL(ttl)
+	switch err := storeSetMany(ctx, Cache, bb, d); {
+	case errors.Is(err, context.Canceled):
+		s = -1
+	case err != nil:
+		s = -1
+		log_.Errorf("Store: set %d keys failed due %s", len(values), err)
+	}
+	return s
+}
+
+func storeGetMany(ctx context.Context, s Store, keys []string) ([][]byte, error) {
+	if b, ok := s.(BatchStore); ok {
+		return b.GetMany(ctx, keys)
+	}
+	bb := make([][]byte, len(keys))
+	for i, k := range keys {
+		var err error
+		if bb[i], err = s.Get(ctx, k); err != nil {
+			return nil, err
+		}
+	}
+	return bb, nil
+}
+
+func storeSetMany(ctx context.Context, s Store, values map[string][]byte, ttl time.Duration) error {
+	if b, ok := s.(BatchStore); ok {
+		return b.SetMany(ctx, values, ttl)
+	}
+	for k, v := range values {
+		if err := s.Set(ctx, k, v, ttl); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *memory) GetMany(ctx context.Context, keys []string) ([][]byte, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	bb := make([][]byte, len(keys))
+	for i, k := range keys {
+		bb[i], _ = s.get(k)
+	}
+	return bb, nil
+}
+
+func (s *memory) SetMany(ctx context.Context, values map[string][]byte, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for k, v := range values {
+		s.set(k, v, ttl)
+	}
+	return nil
+}
+
+func (n *namespace) GetMany(ctx context.Context, keys []string) ([][]byte, error) {
+	kk := make([]string, len(keys))
+	for i, k := range keys {
+		kk[i] = n.prefix + k
+	}
+	return storeGetMany(ctx, n.backend(), kk)
+}
+
+func (n *namespace) SetMany(ctx context.Context, values map[string][]byte, ttl time.Duration) error {
+	vv := make(map[string][]byte, len(values))
+	for k, v := range values {
+		vv[n.prefix+k] = v
+	}
+	return storeSetMany(ctx, n.backend(), vv, ttl)
+}
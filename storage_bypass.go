@@ -0,0 +1,43 @@
+package ion
+
+import "context"
+
+// WithoutCache returns ctx bypassing the cache, Get, GetMany and Endpoint
+// responses miss and Set and SetMany store nothing, ie. for requests which
+// must see live data. State the package keeps in the Store, like idempotency
+// records, tag indexes, chats, limiters and topic history, is not affected.
+//
+// Example:
+//
+//	res, err := orders.Context(ion.WithoutCache(ctx)).Get()
+func WithoutCache(ctx context.Context) context.Context {
+	return context.WithValue(ctx, cacheModeKey{}, cacheBypass)
+}
+
+// RefreshCache returns ctx forcing refresh of the cache, Get, GetMany and
+// Endpoint responses miss, while Set and SetMany store new values.
+func RefreshCache(ctx context.Context) context.Context {
+	return context.WithValue(ctx, cacheModeKey{}, cacheRefresh)
+}
+
+// WithCache returns ctx using the cache again, when derived from context of
+// WithoutCache or RefreshCache.
+func WithCache(ctx context.Context) context.Context {
+	if cacheMode(ctx) == cacheDefault {
+		return ctx
+	}
+	return context.WithValue(ctx, cacheModeKey{}, cacheDefault)
+}
+
+const (
+	cacheDefault = iota
+	cacheBypass
+	cacheRefresh
+)
+
+type cacheModeKey struct{}
+
+func cacheMode(ctx context.Context) int {
+	m, _ := ctx.Value(cacheModeKey{}).(int)
+	return m
+}
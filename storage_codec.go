@@ -0,0 +1,88 @@
+package ion
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"strings"
+	"sync"
+)
+
+// Codec encodes values stored with Get, Set and other Store helpers, JSON by
+// default. Msgpack, protobuf or other encodings can be used by implementing
+// it, see UseCodec.
+type Codec interface {
+	Marshal(v any) ([]byte, error)
+	Unmarshal(b []byte, v any) error
+}
+
+var (
+	// JSONCodec encodes values with encoding/json, it is the default one.
+	JSONCodec Codec = jsonCodec{}
+	// GobCodec encodes values with encoding/gob, faster than JSON for large
+	// payloads and keeping time precision and big numbers.
+	GobCodec Codec = gobCodec{}
+)
+
+// UseCodec sets Codec of stored values, globally or for keys starting with
+// any of the prefixes, ie. of a Namespace. The longest matching prefix wins.
+// Values stored before with another codec can not be read.
+//
+// Example:
+//
+//	ion.UseCodec(ion.GobCodec, "report:")
+func UseCodec(c Codec, prefix ...string) {
+	codecs.mu.Lock()
+	defer codecs.mu.Unlock()
+	if len(prefix) == 0 {
+		codecs.fallback = c
+		return
+	}
+	if codecs.prefixed == nil {
+		codecs.prefixed = make(map[string]Codec)
+	}
+	for _, p := range prefix {
+		codecs.prefixed[p] = c
+	}
+}
+
+// codecOf returns Codec of values stored under the key.
+func codecOf(key string) Codec {
+	codecs.mu.RLock()
+	defer codecs.mu.RUnlock()
+	c, n := codecs.fallback, -1
+	for p, pc := range codecs.prefixed {
+		if len(p) > n && strings.HasPrefix(key, p) {
+			c, n = pc, len(p)
+		}
+	}
+	if c == nil {
+		return JSONCodec
+	}
+	return c
+}
+
+var codecs struct {
+	mu       sync.RWMutex
+	fallback Codec
+	prefixed map[string]Codec
+}
+
+type jsonCodec struct{}
+
+func (jsonCodec) Marshal(v any) ([]byte, error)   { return json.Marshal(v) }
+func (jsonCodec) Unmarshal(b []byte, v any) error { return json.Unmarshal(b, v) }
+
+type gobCodec struct{}
+
+func (gobCodec) Marshal(v any) ([]byte, error) {
+	var b bytes.Buffer
+	if err := gob.NewEncoder(&b).Encode(v); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+func (gobCodec) Unmarshal(b []byte, v any) error {
+	return gob.NewDecoder(bytes.NewReader(b)).Decode(v)
+}
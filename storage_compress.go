@@ -0,0 +1,108 @@
+package ion
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"time"
+)
+
+// CompressedStore returns Store gzipping values larger than threshold bytes,
+// 16KB by default, before writing them to the store, ie. cached LLM
+// responses or vendor payloads. Every value is prefixed with a byte telling
+// whether it is compressed, so raw value resembling gzip is read as is.
+//
+// Example:
+//
+//	ion.UseStore(ion.CompressedStore(redis, 64<<10))
+func CompressedStore(s Store, threshold ...int) Store {
+	c := &compressed{Store: s, threshold: 16 << 10}
+	if len(threshold) > 0 {
+		c.threshold = threshold[0]
+	}
+	return c
+}
+
+type compressed struct {
+	Store
+	threshold int
+}
+
+func (c *compressed) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	b, err := c.compress(value)
+	if err != nil {
+		return err
+	}
+	return c.Store.Set(ctx, key, b, ttl)
+}
+
+func (c *compressed) Get(ctx context.Context, key string) ([]byte, error) {
+	b, err := c.Store.Get(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	return c.decompress(b)
+}
+
+func (c *compressed) GetMany(ctx context.Context, keys []string) ([][]byte, error) {
+	bb, err := storeGetMany(ctx, c.Store, keys)
+	if err != nil {
+		return nil, err
+	}
+	for i := range bb {
+		if bb[i], err = c.decompress(bb[i]); err != nil {
+			return nil, err
+		}
+	}
+	return bb, nil
+}
+
+func (c *compressed) SetMany(ctx context.Context, values map[string][]byte, ttl time.Duration) error {
+	vv := make(map[string][]byte, len(values))
+	for k, v := range values {
+		var err error
+		if vv[k], err = c.compress(v); err != nil {
+			return err
+		}
+	}
+	return storeSetMany(ctx, c.Store, vv, ttl)
+}
+
+// markers of stored values
+const (
+	compressedRaw byte = iota
+	compressedGzip
+)
+
+func (c *compressed) compress(b []byte) ([]byte, error) {
+	if len(b) <= c.threshold {
+		return append([]byte{compressedRaw}, b...), nil
+	}
+	buf := bytes.NewBuffer([]byte{compressedGzip})
+	w := gzip.NewWriter(buf)
+	if _, err := w.Write(b); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (c *compressed) decompress(b []byte) ([]byte, error) {
+	switch {
+	case len(b) == 0: // missing
+		return b, nil
+	case b[0] == compressedRaw:
+		return b[1:], nil
+	case b[0] != compressedGzip:
+		return nil, ErrCache.New("compressed value with unknown marker %d", b[0])
+	}
+	r, err := gzip.NewReader(bytes.NewReader(b[1:]))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return io.ReadAll(r)
+}
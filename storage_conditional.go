@@ -0,0 +1,130 @@
+package ion
+
+import (
+	"bytes"
+	"context"
+	"time"
+)
+
+// ConditionalStore is Store setting keys conditionally in one atomic
+// operation, ie. with Redis SET NX or a script. SetIfAbsent and
+// CompareAndSwap fall back to get and set under Locker of the key for Store
+// not implementing it, which is atomic across processes only with
+// distributed Locker, see UseLocker.
+type ConditionalStore interface {
+	Store
+
+	// SetIfAbsent stores the value when key does not exist, true when stored.
+	SetIfAbsent(ctx context.Context, key string, value []byte, ttl time.Duration) (bool, error)
+
+	// CompareAndSwap stores the value when key holds old one, true when
+	// stored.
+	CompareAndSwap(ctx context.Context, key string, old, value []byte, ttl time.Duration) (bool, error)
+}
+
+// SetIfAbsent stores the value under the key when it does not exist yet, true
+// when stored. Only one of concurrent callers succeeds, so it de-duplicates
+// work across instances. Ttl durations are summed as in Set.
+//
+// Example:
+//
+//	if ok, err := ion.SetIfAbsent(ctx, "invoice:"+id, host, time.Hour); !ok || err != nil {
+//		return err // sent by another instance
+//	}
+func SetIfAbsent[T any](ctx context.Context, key string, value T, ttl ...time.Duration) (bool, error) {
+	b, err := codecOf(key).Marshal(value)
+	if err != nil {
+		return false, ErrCache.Wrap(err)
+	}
+	d := storeTTL(ttl)
+	if c, ok := Cache.(ConditionalStore); ok {
+		if ok, err = c.SetIfAbsent(ctx, key, b, d); err != nil {
+			return false, ErrCache.Wrap(err)
+		}
+		return ok, nil
+	}
+	return storeSwap(ctx, key, nil, b, d)
+}
+
+// CompareAndSwap stores the value under the key when it holds old one, true
+// when stored. Values are compared encoded with Codec of the key. Only one
+// of concurrent callers swapping the same old value succeeds, others read
+// the new one and retry or use it.
+//
+// Example:
+//
+//	var t Token
+//	ion.Get(ctx, "oauth:token", &t)
+//	if t.Expired() {
+//		n, _ := oauth.Refresh(ctx, t)
+//		if ok, _ := ion.CompareAndSwap(ctx, "oauth:token", t, n); !ok {
+//			ion.Get(ctx, "oauth:token", &n) // refreshed by another instance
+//		}
+//		t = n
+//	}
+func CompareAndSwap[T any](ctx context.Context, key string, old, value T, ttl ...time.Duration) (bool, error) {
+	o, err := codecOf(key).Marshal(old)
+	if err != nil {
+		return false, ErrCache.Wrap(err)
+	}
+	b, err := codecOf(key).Marshal(value)
+	if err != nil {
+		return false, ErrCache.Wrap(err)
+	}
+	d := storeTTL(ttl)
+	if c, ok := Cache.(ConditionalStore); ok {
+		if ok, err = c.CompareAndSwap(ctx, key, o, b, d); err != nil {
+			return false, ErrCache.Wrap(err)
+		}
+		return ok, nil
+	}
+	return storeSwap(ctx, key, o, b, d)
+}
+
+// storeSwap sets the value when key holds old one, or does not exist when
+// old is nil, under Locker of the key.
+func storeSwap(ctx context.Context, key string, old, value []byte, ttl time.Duration) (bool, error) {
+	mu := NewLocker(ctx, "cache:swap:"+key)
+	mu.Lock()
+	defer mu.Unlock()
+	b, err := Cache.Get(ctx, key)
+	if err != nil {
+		return false, ErrCache.Wrap(err)
+	}
+	if old == nil && b != nil || old != nil && !bytes.Equal(b, old) {
+		return false, nil
+	}
+	if err = Cache.Set(ctx, key, value, ttl); err != nil {
+		return false, ErrCache.Wrap(err)
+	}
+	return true, nil
+}
+
+// storeTTL sums optional ttl durations.
+func storeTTL(ttl []time.Duration) time.Duration {
+	var d time.Duration
+	for i := range ttl {
+		d += ttl[i]
+	}
+	return d
+}
+
+func (s *memory) SetIfAbsent(ctx context.Context, key string, value []byte, ttl time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.get(key); ok {
+		return false, nil
+	}
+	s.set(key, value, ttl)
+	return true, nil
+}
+
+func (s *memory) CompareAndSwap(ctx context.Context, key string, old, value []byte, ttl time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if b, ok := s.get(key); !ok || !bytes.Equal(b, old) {
+		return false, nil
+	}
+	s.set(key, value, ttl)
+	return true, nil
+}
@@ -0,0 +1,82 @@
+package ion
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"time"
+)
+
+// EncryptedStore returns Store encrypting values with AES-GCM before writing
+// them to inner one, so OAuth tokens or chat transcripts are not kept in
+// plaintext. Key is 16, 24 or 32 bytes long, for AES-128, AES-192 or
+// AES-256. Keys are rotated by passing the previous ones as old, values are
+// written with key and read with any of them. Values stored before without
+// encryption can not be read.
+//
+// Example:
+//
+//	s, err := ion.EncryptedStore(redis, key, previous)
+//	if err != nil {
+//		return err
+//	}
+//	ion.UseStore(s)
+func EncryptedStore(inner Store, key []byte, old ...[]byte) (Store, error) {
+	e := &encrypted{Store: inner}
+	for _, k := range append([][]byte{key}, old...) {
+		b, err := aes.NewCipher(k)
+		if err != nil {
+			return nil, ErrCache.Wrap(err)
+		}
+		g, err := cipher.NewGCM(b)
+		if err != nil {
+			return nil, ErrCache.Wrap(err)
+		}
+		h := sha256.Sum256(k)
+		e.keys = append(e.keys, encryptionKey{id: h[0], aead: g})
+	}
+	return e, nil
+}
+
+type encrypted struct {
+	Store
+	keys []encryptionKey // first one encrypts
+}
+
+// encryptionKey is AES-GCM cipher of a key, id is the first byte of the key
+// hash, written before nonce to find the key decrypting the value.
+type encryptionKey struct {
+	id   byte
+	aead cipher.AEAD
+}
+
+func (e *encrypted) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	k := e.keys[0]
+	b := make([]byte, 1+k.aead.NonceSize(), 1+k.aead.NonceSize()+len(value)+k.aead.Overhead())
+	b[0] = k.id
+	if _, err := rand.Read(b[1:]); err != nil {
+		return ErrCache.Wrap(err)
+	}
+	// the key is authenticated, so values can not be swapped between keys
+	b = k.aead.Seal(b, b[1:], value, []byte(key))
+	return e.Store.Set(ctx, key, b, ttl)
+}
+
+func (e *encrypted) Get(ctx context.Context, key string) ([]byte, error) {
+	b, err := e.Store.Get(ctx, key)
+	if err != nil || len(b) == 0 {
+		return b, err
+	}
+	for _, k := range e.keys {
+		n := 1 + k.aead.NonceSize()
+		if b[0] != k.id || len(b) < n {
+			continue
+		}
+		if v, err := k.aead.Open(nil, b[1:n], b[n:], []byte(key)); err == nil {
+			return v, nil
+		}
+	}
+	return nil, ErrCache.New("%s value not decrypted", key)
+}
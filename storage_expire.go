@@ -0,0 +1,62 @@
+package ion
+
+import (
+	"strings"
+	"time"
+)
+
+// OnExpire calls fn with keys starting with the prefix, ie. of a Namespace,
+// when they expire, until global Context is done. Expired keys are broadcast
+// over Expirations topic, by the memory Store every second and by other
+// stores when they support it, ie. from Redis keyspace notifications.
+//
+// Example:
+//
+//	err := ion.OnExpire("chat:", func(key string) {
+//		archive(strings.TrimPrefix(key, "chat:"))
+//	})
+func OnExpire(prefix string, fn func(key string)) error {
+	var err error
+	ch := Expirations.Read(&err)
+	if err != nil {
+		return ErrCache.Wrap(err)
+	}
+	go func() {
+		for k := range ch {
+			if strings.HasPrefix(k, prefix) {
+				fn(k)
+			}
+		}
+	}()
+	return nil
+}
+
+// sweep removes expired keys of the memory Store and broadcasts them over
+// Expirations topic.
+func (s *memory) sweep() {
+	t := time.NewTicker(time.Second)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-t.C:
+			var kk []string
+			s.mu.Lock()
+			for k, e := range s.exp {
+				if !now.Before(e) {
+					delete(s.data, k)
+					delete(s.exp, k)
+					kk = append(kk, k)
+				}
+			}
+			s.mu.Unlock()
+			for _, k := range kk {
+				_ = Expirations.Write(k) // best effort, there might be no listeners
+			}
+		}
+	}
+}
+
+// Expirations broadcasts keys of the Store when they expire, see OnExpire.
+var Expirations = &Topic[string]{Name: MustURL("/cache/expirations")}
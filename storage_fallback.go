@@ -0,0 +1,213 @@
+package ion
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// FallbackStore returns Store writing to and reading from primary (e.g.
+// Redis) which falls back to secondary (e.g. memory) when primary fails.
+//
+// After a failure primary is skipped for the given cooldown (5s by default),
+// so an outage degrades cache effectiveness instead of slowing down every
+// call and flooding logs with errors. Keys written to secondary meanwhile are
+// read from and written to it until copied back to primary once it recovers.
+//
+// Example:
+//
+//	ion.UseStore(ion.FallbackStore(redis, ion.MemoryStore()))
+func FallbackStore(primary, secondary Store, cooldown ...time.Duration) Store {
+	f := &fallback{primary: primary, secondary: secondary, cooldown: 5 * time.Second, dirty: make(map[string]fallbackMark)}
+	if len(cooldown) > 0 {
+		f.cooldown = cooldown[0]
+	}
+	return f
+}
+
+// MemoryStore returns a new in-process Store, it is the default one.
+func MemoryStore() Store {
+	return &memory{}
+}
+
+type fallback struct {
+	primary, secondary Store
+	cooldown           time.Duration
+
+	mu     sync.Mutex
+	until  time.Time               // primary is skipped until then
+	dirty  map[string]fallbackMark // keys written to secondary
+	writes uint64
+}
+
+// fallbackMark is expiration of dirty key and number of its last write, so
+// resync tells whether the key was written again while copied.
+type fallbackMark struct {
+	exp time.Time
+	n   uint64
+}
+
+// Set writes to primary, dirty keys are written to secondary until copied,
+// so resync never overwrites newer values in primary.
+func (f *fallback) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	f.mu.Lock()
+	_, dirty := f.dirty[key]
+	f.mu.Unlock()
+	if f.healthy() && !dirty {
+		err := f.primary.Set(ctx, key, value, ttl)
+		if !f.failed(ctx, err) {
+			return err
+		}
+	}
+	f.mu.Lock()
+	var exp time.Time
+	if ttl > 0 {
+		exp = time.Now().Add(ttl)
+	}
+	f.writes++
+	f.dirty[key] = fallbackMark{exp: exp, n: f.writes}
+	f.mu.Unlock()
+	return f.secondary.Set(ctx, key, value, ttl)
+}
+
+func (f *fallback) Get(ctx context.Context, key string) ([]byte, error) {
+	f.mu.Lock()
+	_, dirty := f.dirty[key]
+	f.mu.Unlock()
+	if !dirty && f.healthy() {
+		b, err := f.primary.Get(ctx, key)
+		if !f.failed(ctx, err) {
+			return b, err
+		}
+	}
+	return f.secondary.Get(ctx, key)
+}
+
+func (f *fallback) Keys(pattern string) ([]string, error) {
+	if f.healthy() {
+		kk, err := f.primary.Keys(pattern)
+		if !f.failed(ctx, err) {
+			return kk, err
+		}
+	}
+	return f.secondary.Keys(pattern)
+}
+
+func (f *fallback) Delete(ctx context.Context, key string) error {
+	f.mu.Lock()
+	delete(f.dirty, key)
+	f.mu.Unlock()
+	err := f.secondary.Delete(ctx, key)
+	if f.healthy() {
+		if er := f.primary.Delete(ctx, key); !f.failed(ctx, er) {
+			return er
+		}
+	}
+	return err
+}
+
+// healthy reports whether primary should be used, when its cooldown is over
+// it is probed again and pending keys are resynchronized.
+func (f *fallback) healthy() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.until.IsZero() {
+		return true
+	}
+	if time.Now().Before(f.until) {
+		return false
+	}
+	f.until = time.Time{}
+	log_.Infof("Store: primary %T restored", f.primary)
+	go f.resync()
+	return true
+}
+
+// failed marks primary as down when err is caused by the store itself.
+func (f *fallback) failed(ctx context.Context, err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || ctx.Err() != nil {
+		return false
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.until.IsZero() {
+		log_.Errorf("Store: primary %T failed due %s, using %T for %s", f.primary, err, f.secondary, f.cooldown)
+	}
+	f.until = time.Now().Add(f.cooldown)
+	return true
+}
+
+// resync copies keys written to secondary during an outage back to primary,
+// each key is dirty until copied, so it is read from secondary meanwhile.
+func (f *fallback) resync() {
+	f.mu.Lock()
+	keys := make([]string, 0, len(f.dirty))
+	for k := range f.dirty {
+		keys = append(keys, k)
+	}
+	f.mu.Unlock()
+
+	var n int
+	for _, k := range keys {
+		ok, err := f.copy(k)
+		if f.failed(ctx, err) {
+			return // keys left dirty are copied on next recovery
+		}
+		if ok {
+			n++
+		}
+	}
+	if n > 0 {
+		log_.Infof("Store: %d keys resynchronized to %T", n, f.primary)
+	}
+}
+
+// copy writes dirty key from secondary to primary without holding the lock,
+// it is copied again when written meanwhile, true when copied.
+func (f *fallback) copy(k string) (bool, error) {
+	for {
+		f.mu.Lock()
+		m, ok := f.dirty[k]
+		f.mu.Unlock()
+		if !ok {
+			return false, nil // deleted meanwhile
+		}
+		var ttl time.Duration
+		if !m.exp.IsZero() {
+			if ttl = time.Until(m.exp); ttl <= 0 {
+				f.clean(k, m)
+				return false, nil
+			}
+		}
+		b, err := f.secondary.Get(ctx, k)
+		if err != nil || b == nil {
+			f.clean(k, m)
+			return false, nil
+		}
+		if err = f.primary.Set(ctx, k, b, ttl); err != nil {
+			return false, err
+		}
+		f.mu.Lock()
+		c, ok := f.dirty[k]
+		if c == m {
+			delete(f.dirty, k)
+		}
+		f.mu.Unlock()
+		switch {
+		case !ok: // deleted while copied
+			return false, f.primary.Delete(ctx, k)
+		case c == m:
+			return true, nil
+		}
+	}
+}
+
+// clean removes dirty mark m of key k, unless the key was written again.
+func (f *fallback) clean(k string, m fallbackMark) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.dirty[k] == m {
+		delete(f.dirty, k)
+	}
+}
@@ -0,0 +1,64 @@
+package ion
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+// Namespace returns Store keeping keys under the prefix, so Endpoint cache,
+// chat history and app data sharing one Redis do not collide. Keys are
+// prefixed on write and read, Keys and Delete are scoped to the namespace
+// and return keys without the prefix. Without store, the global Cache is
+// used, resolved on every call, so it follows UseStore. Namespace without
+// store passed to UseStore wraps the Store used so far.
+//
+// Example:
+//
+//	sessions := ion.Namespace("sessions:")
+//	err := sessions.Set(ctx, id, b, time.Hour) // stored as sessions:<id>
+func Namespace(prefix string, store ...Store) Store {
+	n := &namespace{prefix: prefix}
+	if len(store) > 0 {
+		n.store = store[0]
+	}
+	return n
+}
+
+type namespace struct {
+	prefix string
+	store  Store
+}
+
+func (n *namespace) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return n.backend().Set(ctx, n.prefix+key, value, ttl)
+}
+
+func (n *namespace) Get(ctx context.Context, key string) ([]byte, error) {
+	return n.backend().Get(ctx, n.prefix+key)
+}
+
+func (n *namespace) Keys(pattern string) ([]string, error) {
+	kk, err := n.backend().Keys(n.prefix + pattern)
+	if err != nil {
+		return nil, err
+	}
+	var keys []string
+	for _, k := range kk {
+		if k, ok := strings.CutPrefix(k, n.prefix); ok {
+			keys = append(keys, k)
+		}
+	}
+	return keys, nil
+}
+
+func (n *namespace) Delete(ctx context.Context, key string) error {
+	return n.backend().Delete(ctx, n.prefix+key)
+}
+
+func (n *namespace) backend() Store {
+	if n.store != nil {
+		return n.store
+	}
+	return Cache
+}
@@ -0,0 +1,82 @@
+package ion
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"time"
+)
+
+// CacheTag attaches tags to a cached key, so it can be removed with
+// Invalidate. Tag index is kept in the Store as long as the longest living
+// of its keys, ttl of zero keeps it without expiration. Endpoint.CacheTags
+// uses it for cached responses, it can be used for any other key stored with
+// Set as well.
+func CacheTag(ctx context.Context, key string, ttl time.Duration, tags ...string) {
+	ctx = WithCache(ctx)
+	for _, t := range tags {
+		k := "cache:tag:" + t
+		mu := NewLocker(ctx, k)
+		mu.Lock()
+		var x cacheTagIndex
+		n := Get(ctx, "%s", &x, k)
+		if !slices.Contains(x.Keys, key) {
+			x.Keys = append(x.Keys, key)
+		}
+		switch until := time.Now().Add(ttl); {
+		case ttl <= 0 || n > 0 && x.Until.IsZero():
+			x.Until = time.Time{}
+		case until.After(x.Until):
+			x.Until = until
+		}
+		var d time.Duration
+		if !x.Until.IsZero() {
+			d = time.Until(x.Until)
+		}
+		Set(ctx, k, x, d)
+		mu.Unlock()
+	}
+}
+
+// cacheTagIndex is a list of keys of the tag, kept until the last of them
+// expires, zero Until for keys without expiration.
+type cacheTagIndex struct {
+	Keys  []string  `json:"keys"`
+	Until time.Time `json:"until"`
+}
+
+// Invalidate removes all cached keys carrying any of the tags and broadcasts
+// the tags over Invalidations topic, so processes holding local copies of
+// cached data can drop them as well.
+//
+// Example:
+//
+//	orders := api.Endpoint("/orders").Cache(time.Hour).CacheTags("orders", "tenant:42")
+//	...
+//	ion.Invalidate(ctx, "tenant:42")
+func Invalidate(ctx context.Context, tags ...string) error {
+	ctx = WithCache(ctx)
+	var errs []error
+	for _, t := range tags {
+		k := "cache:tag:" + t
+		var x cacheTagIndex
+		if Get(ctx, "%s", &x, k) < 0 {
+			errs = append(errs, ErrCache.New("tag %q index not readable", t))
+			continue
+		}
+		for _, key := range append(x.Keys, k) {
+			if err := Cache.Delete(ctx, key); err != nil {
+				errs = append(errs, ErrCache.Wrap(err))
+			}
+		}
+	}
+	Metrics.Count("cache_invalidations_total", len(tags))
+	_ = Invalidations.Write(tags) // best effort, there might be no listeners
+	return errors.Join(errs...)
+}
+
+var (
+	ErrCache = Errorf("cache")
+	// Invalidations broadcasts tags removed with Invalidate.
+	Invalidations = &Topic[[]string]{Name: MustURL("/cache/invalidations")}
+)
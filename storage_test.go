@@ -0,0 +1,555 @@
+package ion_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sokool/ion"
+)
+
+// flaky is a Store failing while down flag is set.
+type flaky struct {
+	ion.Store
+	down atomic.Bool
+	hang chan struct{} // Set waits for it when given
+}
+
+func (f *flaky) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	if f.down.Load() {
+		return errors.New("connection refused")
+	}
+	if f.hang != nil {
+		<-f.hang
+	}
+	return f.Store.Set(ctx, key, value, ttl)
+}
+
+func (f *flaky) Get(ctx context.Context, key string) ([]byte, error) {
+	if f.down.Load() {
+		return nil, errors.New("connection refused")
+	}
+	return f.Store.Get(ctx, key)
+}
+
+func TestFallbackStore(t *testing.T) {
+	ctx := context.Background()
+	p := &flaky{Store: ion.MemoryStore()}
+	s := ion.FallbackStore(p, ion.MemoryStore(), 10*time.Millisecond)
+
+	p.down.Store(true)
+	if err := s.Set(ctx, "a", []byte("1"), 0); err != nil {
+		t.Fatal(err)
+	}
+	if b, err := s.Get(ctx, "a"); err != nil || string(b) != "1" {
+		t.Fatalf("expected value from secondary, got %s %v", b, err)
+	}
+	if err := s.Set(ctx, "b", []byte("1"), 0); err != nil {
+		t.Fatal(err)
+	}
+	p.down.Store(false)
+	time.Sleep(20 * time.Millisecond)
+	if err := s.Set(ctx, "b", []byte("2"), 0); err != nil { // primary probed, resync starts
+		t.Fatal(err)
+	}
+	if b, err := s.Get(ctx, "a"); err != nil || string(b) != "1" {
+		t.Fatalf("expected dirty key read from secondary, got %s %v", b, err)
+	}
+	time.Sleep(20 * time.Millisecond)
+	if b, err := p.Get(ctx, "a"); err != nil || string(b) != "1" {
+		t.Fatalf("expected value resynced to primary, got %s %v", b, err)
+	}
+	if b, err := p.Get(ctx, "b"); err != nil || string(b) != "2" {
+		t.Fatalf("expected newer write kept on primary, got %s %v", b, err)
+	}
+}
+
+func TestFallbackStore_HungResync(t *testing.T) {
+	ctx := context.Background()
+	p := &flaky{Store: ion.MemoryStore()}
+	s := ion.FallbackStore(p, ion.MemoryStore(), 10*time.Millisecond)
+	p.down.Store(true)
+	if err := s.Set(ctx, "a", []byte("1"), 0); err != nil {
+		t.Fatal(err)
+	}
+	p.hang = make(chan struct{})
+	defer close(p.hang)
+	p.down.Store(false)
+	time.Sleep(20 * time.Millisecond)
+	s.Get(ctx, "x") // primary probed, resync hangs on copy of a
+	time.Sleep(10 * time.Millisecond)
+	done := make(chan struct{})
+	go func() {
+		s.Get(ctx, "y")
+		s.Get(ctx, "a")
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("reads blocked by hung resync")
+	}
+}
+
+func TestNamespace(t *testing.T) {
+	ctx := context.Background()
+	m := ion.MemoryStore()
+	a, b := ion.Namespace("a:", m), ion.Namespace("b:", m)
+	if err := a.Set(ctx, "k", []byte("1"), 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Set(ctx, "k", []byte("2"), 0); err != nil {
+		t.Fatal(err)
+	}
+	if v, err := a.Get(ctx, "k"); err != nil || string(v) != "1" {
+		t.Fatalf("expected 1 in a namespace, got %s %v", v, err)
+	}
+	if v, err := m.Get(ctx, "b:k"); err != nil || string(v) != "2" {
+		t.Fatalf("expected prefixed key in store, got %s %v", v, err)
+	}
+	if kk, err := b.Keys(""); err != nil || len(kk) != 1 || kk[0] != "k" {
+		t.Fatalf("expected keys of b namespace only, got %v %v", kk, err)
+	}
+	if err := a.Delete(ctx, "k"); err != nil {
+		t.Fatal(err)
+	}
+	if v, _ := b.Get(ctx, "k"); string(v) != "2" {
+		t.Fatalf("expected b namespace untouched, got %s", v)
+	}
+}
+
+func TestGetOrSet(t *testing.T) {
+	ctx := context.Background()
+	key := "getorset:" + ion.UUID()
+	var calls atomic.Int32
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			v, err := ion.GetOrSet(ctx, key, time.Minute, func() (int, error) {
+				calls.Add(1)
+				time.Sleep(20 * time.Millisecond)
+				return 42, nil
+			})
+			if err != nil || v != 42 {
+				t.Errorf("expected 42, got %d %v", v, err)
+			}
+		}()
+	}
+	wg.Wait()
+	if n := calls.Load(); n != 1 {
+		t.Fatalf("expected single computation, got %d", n)
+	}
+	_, err := ion.GetOrSet(ctx, key+":err", time.Minute, func() (int, error) { return 0, errors.New("boom") })
+	if err == nil {
+		t.Fatal("expected compute error")
+	}
+	var v int
+	if n := ion.Get(ctx, "%s", &v, key+":err"); n != 0 {
+		t.Fatalf("expected nothing stored on error, got %d bytes", n)
+	}
+}
+
+func TestGetOrSet_Panic(t *testing.T) {
+	ctx := context.Background()
+	key := "getorset:" + ion.UUID()
+	started, done := make(chan struct{}), make(chan any)
+	go func() {
+		defer func() { done <- recover() }()
+		ion.GetOrSet(ctx, key, time.Minute, func() (int, error) {
+			close(started)
+			time.Sleep(20 * time.Millisecond)
+			panic("boom")
+		})
+	}()
+	<-started
+	// waiting call gets an error instead of panicking on missing value
+	if _, err := ion.GetOrSet(ctx, key, time.Minute, func() (int, error) { return 42, nil }); err == nil {
+		t.Fatal("expected error of panicked computation")
+	}
+	if p := <-done; p != "boom" {
+		t.Fatalf("expected panic to reach the caller, got %v", p)
+	}
+}
+
+func TestGetMany(t *testing.T) {
+	ctx := context.Background()
+	id := ion.UUID()
+	a, b, c := id+":a", id+":b", id+":c"
+	if n := ion.SetMany(ctx, map[string]int{a: 1, b: 2}, time.Minute); n != 2 {
+		t.Fatalf("expected 2 bytes written, got %d", n)
+	}
+	m := ion.GetMany[int](ctx, a, b, c)
+	if len(m) != 2 || m[a] != 1 || m[b] != 2 {
+		t.Fatalf("unexpected values %v", m)
+	}
+	ns := ion.Namespace("ns:", &flaky{Store: ion.MemoryStore()}) // store without batch support
+	if err := ns.(ion.BatchStore).SetMany(ctx, map[string][]byte{"x": []byte("1")}, 0); err != nil {
+		t.Fatal(err)
+	}
+	if bb, err := ns.(ion.BatchStore).GetMany(ctx, []string{"x", "y"}); err != nil || string(bb[0]) != "1" || bb[1] != nil {
+		t.Fatalf("unexpected values %q %v", bb, err)
+	}
+}
+
+func TestIncr(t *testing.T) {
+	ctx := context.Background()
+	key := "incr:" + ion.UUID()
+	var wg sync.WaitGroup
+	for range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := ion.Incr(ctx, key, 1, time.Minute); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	if n, err := ion.Incr(ctx, key, -5); err != nil || n != 15 {
+		t.Fatalf("expected 15, got %d %v", n, err)
+	}
+	var n int64
+	if ion.Get(ctx, "%s", &n, key); n != 15 {
+		t.Fatalf("expected counter readable with Get, got %d", n)
+	}
+	ion.Set(ctx, key, "text")
+	if _, err := ion.Incr(ctx, key, 1); err == nil {
+		t.Fatal("expected error of key not being a counter")
+	}
+}
+
+func TestCompareAndSwap(t *testing.T) {
+	ctx := context.Background()
+	key := "cas:" + ion.UUID()
+	var won atomic.Int32
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if ok, err := ion.SetIfAbsent(ctx, key, "v1"); err != nil {
+				t.Error(err)
+			} else if ok {
+				won.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+	if n := won.Load(); n != 1 {
+		t.Fatalf("expected one SetIfAbsent to succeed, got %d", n)
+	}
+	if ok, err := ion.CompareAndSwap(ctx, key, "v0", "v2"); ok || err != nil {
+		t.Fatalf("expected swap of stale value to fail, got %t %v", ok, err)
+	}
+	if ok, err := ion.CompareAndSwap(ctx, key, "v1", "v2"); !ok || err != nil {
+		t.Fatalf("expected swap to succeed, got %t %v", ok, err)
+	}
+	var s string
+	if ion.Get(ctx, "%s", &s, key); s != "v2" {
+		t.Fatalf("expected v2, got %s", s)
+	}
+}
+
+func TestOnExpire(t *testing.T) {
+	ctx := context.Background()
+	id := ion.UUID()
+	expired, topic := make(chan string, 1), ion.Expirations
+	defer func() { ion.Expirations = topic }()
+	ion.Expirations = ion.MustTopic[string](ctx, "memory://cache/expirations") // other tests register PubSub
+	if err := ion.OnExpire(id+":", func(key string) { expired <- key }); err != nil {
+		t.Fatal(err)
+	}
+	ion.Set(ctx, id+":a", 1, 10*time.Millisecond)
+	ion.Set(ctx, id+":b", 1)
+	time.Sleep(20 * time.Millisecond)
+	var v int
+	if n := ion.Get(ctx, "%s:a", &v, id); n != 0 {
+		t.Fatalf("expected expired key missing, got %d bytes", n)
+	}
+	select {
+	case k := <-expired:
+		if k != id+":a" {
+			t.Fatalf("unexpected expired key %s", k)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("expiration not notified")
+	}
+	if n := ion.Get(ctx, "%s:b", &v, id); n != 1 {
+		t.Fatalf("expected key without ttl kept, got %d bytes", n)
+	}
+}
+
+func TestUseCodec(t *testing.T) {
+	ctx := context.Background()
+	prefix := "gob:" + ion.UUID() + ":"
+	ion.UseCodec(ion.GobCodec, prefix)
+	now := time.Now()
+	ion.Set(ctx, prefix+"t", now)
+	var v time.Time
+	if ion.Get(ctx, "%st", &v, prefix); !v.Equal(now) || v.Location() != now.Location() {
+		t.Fatalf("expected %s, got %s", now, v)
+	}
+	if b, _ := ion.Cache.Get(ctx, prefix+"t"); json.Valid(b) {
+		t.Fatalf("expected gob encoding, got %s", b)
+	}
+}
+
+func TestCompressedStore(t *testing.T) {
+	ctx := context.Background()
+	m := ion.MemoryStore()
+	s := ion.CompressedStore(m, 100)
+	big := bytes.Repeat([]byte("ion "), 1000)
+	if err := s.Set(ctx, "big", big, 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Set(ctx, "small", []byte("ion"), 0); err != nil {
+		t.Fatal(err)
+	}
+	gz := []byte{0x1f, 0x8b, 'i', 'o', 'n'} // raw value starting as gzip
+	if err := s.Set(ctx, "gz", gz, 0); err != nil {
+		t.Fatal(err)
+	}
+	if b, _ := m.Get(ctx, "big"); len(b) >= len(big) {
+		t.Fatalf("expected compressed value, got %d bytes", len(b))
+	}
+	if b, _ := m.Get(ctx, "small"); string(b) != "\x00ion" {
+		t.Fatalf("expected small value stored as is, got %q", b)
+	}
+	if b, err := s.Get(ctx, "big"); err != nil || !bytes.Equal(b, big) {
+		t.Fatalf("expected decompressed value, got %d bytes %v", len(b), err)
+	}
+	if b, err := s.Get(ctx, "gz"); err != nil || !bytes.Equal(b, gz) {
+		t.Fatalf("expected raw value read as is, got %q %v", b, err)
+	}
+}
+
+func TestGet_Metrics(t *testing.T) {
+	ctx := context.Background()
+	p := "m" + ion.UUID()[:8]
+	var v int
+	ion.Get(ctx, "%s:a", &v, p)
+	ion.Set(ctx, p+":a", 1)
+	ion.Get(ctx, "%s:a", &v, p)
+	ion.Get(ctx, "%s:a", &v, p)
+	s := ion.Metrics.String()
+	for _, m := range []string{
+		`cache_hits_total{prefix="%s"} 2`,
+		`cache_misses_total{prefix="%s"} 1`,
+		`cache_get_seconds_count{prefix="%s"} 3`,
+		`cache_set_seconds_count{prefix="%s"} 1`,
+	} {
+		if m = fmt.Sprintf(m, p); !strings.Contains(s, m) {
+			t.Fatalf("expected %s in metrics", m)
+		}
+	}
+}
+
+func TestTransact(t *testing.T) {
+	ctx := context.Background()
+	id := ion.UUID()
+	a, b := id+":a", id+":b"
+	if err := ion.Transact(ctx, func(tx ion.Tx) error {
+		tx.Set(a, []byte("1"), 0)
+		tx.Set(b, []byte("1"), 0)
+		return errors.New("rollback")
+	}); err == nil {
+		t.Fatal("expected transaction error")
+	}
+	if v, _ := ion.Cache.Get(ctx, a); v != nil {
+		t.Fatalf("expected no writes of failed transaction, got %s", v)
+	}
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := ion.Transact(ctx, func(tx ion.Tx) error {
+				v, _ := tx.Get(a)
+				n := len(v) + 1
+				tx.Set(a, bytes.Repeat([]byte("x"), n), 0)
+				tx.Set(b, bytes.Repeat([]byte("x"), n), 0)
+				return nil
+			})
+			if err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	va, _ := ion.Cache.Get(ctx, a)
+	vb, _ := ion.Cache.Get(ctx, b)
+	if len(va) != 10 || !bytes.Equal(va, vb) {
+		t.Fatalf("expected keys updated together, got %q %q", va, vb)
+	}
+}
+
+func TestEncryptedStore(t *testing.T) {
+	ctx := context.Background()
+	m := ion.MemoryStore()
+	k1, k2 := bytes.Repeat([]byte("1"), 32), bytes.Repeat([]byte("2"), 32)
+	s1, err := ion.EncryptedStore(m, k1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = s1.Set(ctx, "token", []byte("secret"), 0); err != nil {
+		t.Fatal(err)
+	}
+	if b, _ := m.Get(ctx, "token"); bytes.Contains(b, []byte("secret")) {
+		t.Fatalf("expected encrypted value, got %q", b)
+	}
+	s2, err := ion.EncryptedStore(m, k2, k1) // rotated key
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b, err := s2.Get(ctx, "token"); err != nil || string(b) != "secret" {
+		t.Fatalf("expected value read with old key, got %q %v", b, err)
+	}
+	if err = s2.Set(ctx, "token", []byte("new"), 0); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = s1.Get(ctx, "token"); err == nil {
+		t.Fatal("expected error reading value of new key with old one")
+	}
+	if _, err = ion.EncryptedStore(m, []byte("short")); err == nil {
+		t.Fatal("expected error of invalid key size")
+	}
+}
+
+func TestWithoutCache_Store(t *testing.T) {
+	ctx, key := context.Background(), "bypass:"+ion.UUID()
+	if n := ion.Set(ion.WithoutCache(ctx), key, 1); n != 0 {
+		t.Fatalf("expected nothing stored without cache, got %d", n)
+	}
+	var v int
+	if n := ion.Get(ctx, "%s", &v, key); n != 0 {
+		t.Fatalf("expected miss of value set without cache, got %d", n)
+	}
+	ion.Set(ion.RefreshCache(ctx), key, 2)
+	if n := ion.Get(ion.RefreshCache(ctx), "%s", &v, key); n != 0 {
+		t.Fatalf("expected miss on refresh, got %d", n)
+	}
+	if n := ion.Get(ctx, "%s", &v, key); n != 1 || v != 2 {
+		t.Fatalf("expected value stored on refresh, got %d %d", n, v)
+	}
+	// state of the package is kept regardless of bypass
+	ion.CacheTag(ion.WithoutCache(ctx), key, 0, key)
+	if err := ion.Invalidate(ion.WithoutCache(ctx), key); err != nil {
+		t.Fatal(err)
+	}
+	if n := ion.Get(ctx, "%s", &v, key); n != 0 {
+		t.Fatalf("expected key invalidated by tag indexed without cache, got %d", n)
+	}
+}
+
+func TestIncr_Fallback(t *testing.T) {
+	defer func(s ion.Store) { ion.Cache = s }(ion.Cache)
+	ion.Cache = ion.CompressedStore(ion.MemoryStore()) // no atomic Incr
+	ctx, key := context.Background(), "incr:"+ion.UUID()
+	var wg sync.WaitGroup
+	for range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := ion.Incr(ctx, key, 1); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	if n, err := ion.Incr(ctx, key, 0); err != nil || n != 20 {
+		t.Fatalf("expected 20, got %d %v", n, err)
+	}
+}
+
+func TestSetIfAbsent_Fallback(t *testing.T) {
+	defer func(s ion.Store) { ion.Cache = s }(ion.Cache)
+	ion.Cache = ion.CompressedStore(ion.MemoryStore()) // no atomic SetIfAbsent
+	ctx, key := context.Background(), "absent:"+ion.UUID()
+	var won atomic.Int32
+	var wg sync.WaitGroup
+	for range 50 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if ok, _ := ion.SetIfAbsent(ctx, key, 1); ok {
+				won.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+	if n := won.Load(); n != 1 {
+		t.Fatalf("expected one SetIfAbsent to succeed, got %d", n)
+	}
+}
+
+func TestTransact_Fallback(t *testing.T) {
+	defer func(s ion.Store) { ion.Cache = s }(ion.Cache)
+	ion.Cache = ion.CompressedStore(ion.MemoryStore()) // no atomic Tx
+	ctx, key := context.Background(), "tx:"+ion.UUID()
+	var wg sync.WaitGroup
+	for range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := ion.Transact(ctx, func(tx ion.Tx) error {
+				v, _ := tx.Get(key)
+				tx.Set(key, append(v, 'x'), 0)
+				return nil
+			})
+			if err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	if v, _ := ion.Cache.Get(ctx, key); len(v) != 20 {
+		t.Fatalf("expected transactions kept apart, got %q", v)
+	}
+}
+
+func TestCacheTag(t *testing.T) {
+	ctx, tag := context.Background(), "tag:"+ion.UUID()
+	var wg sync.WaitGroup
+	for i := range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			k := fmt.Sprintf("%s:%d", tag, i)
+			ion.Set(ctx, k, i)
+			ion.CacheTag(ctx, k, time.Minute, tag)
+		}()
+	}
+	wg.Wait()
+	if err := ion.Invalidate(ctx, tag); err != nil {
+		t.Fatal(err)
+	}
+	for i := range 20 {
+		var v int
+		if n := ion.Get(ctx, "%s:%d", &v, tag, i); n != 0 {
+			t.Fatalf("expected key %d invalidated", i)
+		}
+	}
+	// index outlives short living keys of the tag
+	long, short := tag+":long", tag+":short"
+	ion.Set(ctx, long, 1, time.Minute)
+	ion.CacheTag(ctx, long, time.Minute, tag)
+	ion.Set(ctx, short, 2, 20*time.Millisecond)
+	ion.CacheTag(ctx, short, 20*time.Millisecond, tag)
+	time.Sleep(50 * time.Millisecond)
+	if err := ion.Invalidate(ctx, tag); err != nil {
+		t.Fatal(err)
+	}
+	var v int
+	if n := ion.Get(ctx, "%s", &v, long); n != 0 {
+		t.Fatal("expected long living key invalidated")
+	}
+}
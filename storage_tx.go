@@ -0,0 +1,149 @@
+package ion
+
+import (
+	"context"
+	"time"
+)
+
+// Tx reads and writes keys of the Store within transaction, writes are
+// visible to its reads and applied together when the transaction succeeds.
+type Tx interface {
+	Get(key string) ([]byte, error)
+	Set(key string, value []byte, ttl time.Duration)
+	Delete(key string)
+}
+
+// TxStore is Store updating many keys atomically, ie. with Redis
+// MULTI/EXEC. Transact falls back to writes buffered and applied under
+// Locker for Store not implementing it, which keeps concurrent transactions
+// apart, but may apply writes partially when the Store fails.
+type TxStore interface {
+	Store
+
+	// Tx runs fn in transaction, its writes are applied when it returns nil.
+	Tx(ctx context.Context, fn func(Tx) error) error
+}
+
+// Transact runs fn in transaction of the global Store, so related keys, ie.
+// chat messages and their index, are updated together, or not at all when fn
+// fails. Fn must use Tx only, not the Store helpers, which may deadlock.
+//
+// Example:
+//
+//	err := ion.Transact(ctx, func(tx ion.Tx) error {
+//		tx.Set("chat:42:msg:7", msg, 0)
+//		tx.Set("chat:42:index", index, 0)
+//		return nil
+//	})
+func Transact(ctx context.Context, fn func(Tx) error) error {
+	return storeTx(ctx, Cache, fn)
+}
+
+func storeTx(ctx context.Context, s Store, fn func(Tx) error) error {
+	if t, ok := s.(TxStore); ok {
+		return t.Tx(ctx, fn)
+	}
+	mu := NewLocker(ctx, "cache:tx")
+	mu.Lock()
+	defer mu.Unlock()
+	tx := newTxBuffer(func(key string) ([]byte, error) { return s.Get(ctx, key) })
+	if err := fn(tx); err != nil {
+		return err
+	}
+	return tx.commit(func(key string, w txWrite) error {
+		if w.deleted {
+			return s.Delete(ctx, key)
+		}
+		return s.Set(ctx, key, w.value, w.ttl)
+	})
+}
+
+// txBuffer is Tx keeping writes until commit.
+type txBuffer struct {
+	get    func(key string) ([]byte, error)
+	writes map[string]txWrite
+	order  []string
+}
+
+type txWrite struct {
+	value   []byte
+	ttl     time.Duration
+	deleted bool
+}
+
+func newTxBuffer(get func(key string) ([]byte, error)) *txBuffer {
+	return &txBuffer{get: get, writes: make(map[string]txWrite)}
+}
+
+func (t *txBuffer) Get(key string) ([]byte, error) {
+	if w, ok := t.writes[key]; ok {
+		return w.value, nil
+	}
+	return t.get(key)
+}
+
+func (t *txBuffer) Set(key string, value []byte, ttl time.Duration) {
+	t.write(key, txWrite{value: value, ttl: ttl})
+}
+
+func (t *txBuffer) Delete(key string) {
+	t.write(key, txWrite{deleted: true})
+}
+
+func (t *txBuffer) write(key string, w txWrite) {
+	if _, ok := t.writes[key]; !ok {
+		t.order = append(t.order, key)
+	}
+	t.writes[key] = w
+}
+
+// commit applies writes in order they were made.
+func (t *txBuffer) commit(apply func(key string, w txWrite) error) error {
+	for _, k := range t.order {
+		if err := apply(k, t.writes[k]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *memory) Tx(ctx context.Context, fn func(Tx) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	tx := newTxBuffer(func(key string) ([]byte, error) {
+		b, _ := s.get(key)
+		return b, nil
+	})
+	if err := fn(tx); err != nil {
+		return err
+	}
+	return tx.commit(func(key string, w txWrite) error {
+		if w.deleted {
+			delete(s.data, key)
+			delete(s.exp, key)
+		} else {
+			s.set(key, w.value, w.ttl)
+		}
+		return nil
+	})
+}
+
+func (n *namespace) Tx(ctx context.Context, fn func(Tx) error) error {
+	return storeTx(ctx, n.backend(), func(tx Tx) error {
+		return fn(namespaceTx{tx, n.prefix})
+	})
+}
+
+// namespaceTx is Tx of the namespace, prefixing its keys.
+type namespaceTx struct {
+	tx     Tx
+	prefix string
+}
+
+func (t namespaceTx) Get(key string) ([]byte, error) { return t.tx.Get(t.prefix + key) }
+
+func (t namespaceTx) Set(key string, value []byte, ttl time.Duration) {
+	t.tx.Set(t.prefix+key, value, ttl)
+}
+
+func (t namespaceTx) Delete(key string) { t.tx.Delete(t.prefix + key) }
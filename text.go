@@ -5,7 +5,9 @@ import (
 	"encoding/hex"
 	"fmt"
 	"math"
+	"sort"
 	"strings"
+	"unicode"
 	"unicode/utf8"
 )
 
@@ -20,7 +22,7 @@ func NewText(message string, args ...any) Text {
 
 // Compare returns a hybrid similarity score between the receiver text t and to,
 // in the range [0..1]. The score combines semantic similarity (cosine) and
-// character-level similarity (Levenshtein).
+// character-level similarity (Levenshtein), see Score to mix other metrics.
 //
 // Alpha and beta are weights for the cosine and Levenshtein components. If both
 // are zero, they default to 50 and 50. The values are normalized so that
@@ -34,36 +36,47 @@ func NewText(message string, args ...any) Text {
 // A higher beta makes the algorithm focus on character-level similarity such as
 // spelling, exact phrasing, and structural differences.
 func (t Text) Compare(to string, alpha, beta uint) float64 {
-	a := float64(alpha)
-	b := float64(beta)
-
-	if a == 0 && b == 0 {
-		a, b = 0.5, 0.5
-	} else {
-		s := a + b
-		if s == 0 {
-			// fallback sanity
-			a, b = 0.5, 0.5
-		} else {
-			a /= s
-			b /= s
+	return t.Score(to, Weight{Text.Cosine, alpha}, Weight{Text.Levenshtein, beta})
+}
+
+// Weight pairs a similarity metric, like Text.Cosine or Text.Trigram, with
+// its share in Score.
+type Weight struct {
+	Metric func(Text, string) float64
+	Value  uint
+}
+
+// Score returns weighted similarity between t and to in [0..1], mixing
+// metrics by their normalized weights, equally when all weights are zero.
+//
+// Example:
+//
+//	s := ion.Text(address).Score(candidate,
+//		ion.Weight{ion.Text.Trigram, 60},
+//		ion.Weight{ion.Text.Jaccard, 30},
+//		ion.Weight{ion.Text.Levenshtein, 10})
+func (t Text) Score(to string, weights ...Weight) float64 {
+	var sum, score float64
+	for _, w := range weights {
+		sum += float64(w.Value)
+	}
+	for _, w := range weights {
+		a := float64(w.Value) / sum
+		if sum == 0 {
+			a = 1 / float64(len(weights))
+		}
+		if a > 0 {
+			score += a * w.Metric(t, to)
 		}
 	}
-	return a*t.Cosine(to) + b*t.Levenshtein(to)
+	return score
 }
 
 // Cosine returns cosine similarity between t and to in [0..1].
 // Performs tokenization and builds simple frequency vectors.
 func (t Text) Cosine(to string) float64 {
-	tokenize := func(s string) []string {
-		s = strings.ToLower(s)
-		r := strings.NewReplacer(",", " ", ".", " ", ";", " ", "!", " ", "?", " ")
-		s = r.Replace(s)
-		return strings.Fields(s)
-	}
-
-	wa := tokenize(string(t))
-	wb := tokenize(to)
+	wa := textWords(string(t))
+	wb := textWords(to)
 
 	vocab := map[string]struct{}{}
 	for _, w := range wa {
@@ -105,6 +118,20 @@ func (t Text) Cosine(to string) float64 {
 	return dot / (math.Sqrt(na) * math.Sqrt(nb))
 }
 
+// Jaccard returns similarity of word sets of t and to in [0..1], the share
+// of common words among all distinct ones. Word order and repetitions don't
+// matter, typos do.
+func (t Text) Jaccard(to string) float64 {
+	return textJaccard(textWords(string(t)), textWords(to))
+}
+
+// Trigram returns similarity of three-character sequences of t and to in
+// [0..1], like PostgreSQL pg_trgm does. It is tolerant to typos, word order
+// and abbreviations at once, which makes it a good fit for addresses and names.
+func (t Text) Trigram(to string) float64 {
+	return textJaccard(textTrigrams(string(t)), textTrigrams(to))
+}
+
 // Levenshtein returns similarity in [0..1] using rune-based distance,
 // scaled by max length of either string.
 func (t Text) Levenshtein(to string) float64 {
@@ -228,3 +255,155 @@ func (t Text) Hash(prefix ...string) string {
 func (t Text) Generate(instructions ...string) (string, error) {
 	return (&LLM{Instruction: strings.Join(instructions, "\n")}).Read(string(t))
 }
+
+// textWords splits s into lowercase words without punctuation.
+func textWords(s string) []string {
+	s = strings.ToLower(s)
+	r := strings.NewReplacer(",", " ", ".", " ", ";", " ", "!", " ", "?", " ")
+	s = r.Replace(s)
+	return strings.Fields(s)
+}
+
+// textTrigrams returns three-rune sequences of words in s, padded like pg_trgm.
+func textTrigrams(s string) []string {
+	var gg []string
+	for _, w := range textWords(s) {
+		r := []rune("  " + w + " ")
+		for i := 0; i+3 <= len(r); i++ {
+			gg = append(gg, string(r[i:i+3]))
+		}
+	}
+	return gg
+}
+
+func textJaccard(a, b []string) float64 {
+	if len(a) == 0 && len(b) == 0 {
+		return 1
+	}
+	set := make(map[string]uint8)
+	for _, x := range a {
+		set[x] |= 1
+	}
+	for _, x := range b {
+		set[x] |= 2
+	}
+	var n int
+	for _, m := range set {
+		if m == 3 {
+			n++
+		}
+	}
+	return float64(n) / float64(len(set))
+}
+
+// NGrams returns frequencies of character n-grams of the lowercase text with
+// whitespace collapsed to single spaces, used for shingling and duplicate
+// detection. It returns empty map when n is not positive or text is shorter.
+//
+// Example:
+//
+//	ion.Text("Abab").NGrams(2) // map[ab:2 ba:1]
+func (t Text) NGrams(n int) map[string]int {
+	r := []rune(strings.Join(strings.Fields(strings.ToLower(string(t))), " "))
+	m := make(map[string]int)
+	for i := 0; n > 0 && i+n <= len(r); i++ {
+		m[string(r[i:i+n])]++
+	}
+	return m
+}
+
+// WordNGrams returns frequencies of n consecutive words of the text, words
+// are lowercase and without punctuation like in Cosine.
+//
+// Example:
+//
+//	ion.Text("to be or not to be").WordNGrams(2) // map[be or:1 not to:1 or not:1 to be:2]
+func (t Text) WordNGrams(n int) map[string]int {
+	w := textWords(string(t))
+	m := make(map[string]int)
+	for i := 0; n > 0 && i+n <= len(w); i++ {
+		m[strings.Join(w[i:i+n], " ")]++
+	}
+	return m
+}
+
+// TruncateUnit is a unit of Text.Truncate limit.
+type TruncateUnit int
+
+const (
+	// TruncateRunes limits number of characters, including the ellipsis.
+	TruncateRunes TruncateUnit = iota
+	// TruncateWords limits number of words.
+	TruncateWords
+)
+
+// Truncate shortens the text to limit runes or words, cutting on word
+// boundaries and appending an ellipsis, so UI snippets and prompts never end
+// mid-rune or mid-word. A single word longer than the rune limit is cut
+// inside. Text within the limit is returned unchanged.
+//
+// Example:
+//
+//	ion.Text("The quick brown fox").Truncate(12, ion.TruncateRunes) // "The quick…"
+//	ion.Text("The quick brown fox").Truncate(2, ion.TruncateWords)  // "The quick…"
+func (t Text) Truncate(limit int, unit TruncateUnit) Text {
+	if limit <= 0 {
+		return ""
+	}
+	ends := textWordEnds(string(t))
+	switch unit {
+	case TruncateWords:
+		if len(ends) <= limit {
+			return t
+		}
+		return t[:ends[limit-1]] + "…"
+	default:
+		if utf8.RuneCountInString(string(t)) <= limit {
+			return t
+		}
+		for i := len(ends) - 1; i >= 0; i-- {
+			if utf8.RuneCountInString(string(t[:ends[i]])) < limit {
+				return t[:ends[i]] + "…"
+			}
+		}
+		return Text([]rune(string(t))[:limit-1]) + "…"
+	}
+}
+
+// TruncateTokens shortens the text to limit tokens of the model (see
+// Tokens), cutting on word boundaries and appending an ellipsis.
+func (t Text) TruncateTokens(limit int, model string) Text {
+	if t.Tokens(model) <= limit {
+		return t
+	}
+	ends := textWordEnds(string(t))
+	n := sort.Search(len(ends), func(i int) bool {
+		return (t[:ends[i]] + "…").Tokens(model) > limit
+	})
+	if n == 0 {
+		return ""
+	}
+	return t[:ends[n-1]] + "…"
+}
+
+// textWordEnds returns byte offsets where words of s end, trailing
+// punctuation is left out.
+func textWordEnds(s string) []int {
+	var ee []int
+	in := false
+	for i, r := range s {
+		switch sp := unicode.IsSpace(r); {
+		case sp && in:
+			ee, in = append(ee, i), false
+		case !sp:
+			in = true
+		}
+	}
+	if in {
+		ee = append(ee, len(s))
+	}
+	for i, e := range ee {
+		ee[i] = len(strings.TrimRightFunc(s[:e], func(r rune) bool { return unicode.IsPunct(r) && r != ')' && r != '"' }))
+	}
+	return ee
+}
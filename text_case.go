@@ -0,0 +1,75 @@
+package ion
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Snake returns text in snake_case, e.g. "HTTPServer ID" gives "http_server_id".
+func (t Text) Snake() Text {
+	return Text(strings.ToLower(strings.Join(t.cases(), "_")))
+}
+
+// Kebab returns text in kebab-case, e.g. "HTTPServer ID" gives "http-server-id".
+func (t Text) Kebab() Text {
+	return Text(strings.ToLower(strings.Join(t.cases(), "-")))
+}
+
+// Camel returns text in camelCase, e.g. "http_server id" gives "httpServerId".
+func (t Text) Camel() Text {
+	ww := t.cases()
+	for i := range ww {
+		if ww[i] = strings.ToLower(ww[i]); i > 0 {
+			ww[i] = textUpperFirst(ww[i])
+		}
+	}
+	return Text(strings.Join(ww, ""))
+}
+
+// Title returns words of text capitalized and separated by a space,
+// e.g. "http_server-id" gives "Http Server Id".
+func (t Text) Title() Text {
+	ww := t.cases()
+	for i := range ww {
+		ww[i] = textUpperFirst(strings.ToLower(ww[i]))
+	}
+	return Text(strings.Join(ww, " "))
+}
+
+// cases splits text into words on separators, lower to upper case changes
+// and acronym ends, so "parseHTTPRequest2xx_ok" gives parse, HTTP, Request2xx and ok.
+// Digits stay with the preceding word.
+func (t Text) cases() []string {
+	var ww []string
+	var w []rune
+	rr := []rune(string(t))
+	for i, r := range rr {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			if len(w) > 0 {
+				ww, w = append(ww, string(w)), nil
+			}
+			continue
+		}
+		if len(w) > 0 && unicode.IsUpper(r) {
+			p := w[len(w)-1]
+			next := i+1 < len(rr) && unicode.IsLower(rr[i+1])
+			if unicode.IsLower(p) || unicode.IsDigit(p) || (unicode.IsUpper(p) && next) {
+				ww, w = append(ww, string(w)), nil
+			}
+		}
+		w = append(w, r)
+	}
+	if len(w) > 0 {
+		ww = append(ww, string(w))
+	}
+	return ww
+}
+
+func textUpperFirst(s string) string {
+	r := []rune(s)
+	if len(r) == 0 {
+		return s
+	}
+	r[0] = unicode.ToUpper(r[0])
+	return string(r)
+}
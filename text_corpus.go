@@ -0,0 +1,160 @@
+package ion
+
+import (
+	"math"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Corpus is a TF-IDF index of documents for fast similarity search. Unlike
+// Text.Cosine, which builds vocabulary for every pair, documents are
+// tokenized once and a query is scored only against documents sharing its
+// words, so matching against thousands of records is cheap. Words frequent
+// across the corpus (like "street" in addresses) weigh less than rare ones.
+//
+// Example:
+//
+//	c := ion.NewCorpus(addresses...)
+//	for _, m := range c.Similar("221b baker st london", 3) {
+//		fmt.Println(m.Index, m.Text, m.Score)
+//	}
+type Corpus struct {
+	mu    sync.RWMutex
+	docs  []string
+	index map[string][]posting // word → documents containing it
+	norms []float64            // vector lengths, nil when outdated
+}
+
+type posting struct{ doc, count int }
+
+// Match is a document found by Corpus.Similar.
+type Match struct {
+	// Index of the document in order of addition.
+	Index int     `json:"index"`
+	Text  string  `json:"text"`
+	Score float64 `json:"score"`
+}
+
+// NewCorpus creates Corpus of given documents.
+func NewCorpus(docs ...string) *Corpus {
+	return (&Corpus{index: make(map[string][]posting)}).Add(docs...)
+}
+
+// Add appends documents to the corpus.
+func (c *Corpus) Add(docs ...string) *Corpus {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, d := range docs {
+		for w, n := range c.counts(d) {
+			c.index[w] = append(c.index[w], posting{len(c.docs), n})
+		}
+		c.docs = append(c.docs, d)
+	}
+	c.norms = nil
+	return c
+}
+
+// Len returns number of documents.
+func (c *Corpus) Len() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return len(c.docs)
+}
+
+// Similar returns up to n documents most similar to the query, ordered by
+// TF-IDF cosine score in (0..1], documents without common words are skipped.
+func (c *Corpus) Similar(query string, n int) []Match {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.norms == nil {
+		c.norms = make([]float64, len(c.docs))
+		for w, pp := range c.index {
+			idf := c.idf(w)
+			for _, p := range pp {
+				c.norms[p.doc] += math.Pow(float64(p.count)*idf, 2)
+			}
+		}
+	}
+	var qn float64
+	scores := make(map[int]float64)
+	for w, k := range c.counts(query) {
+		idf := c.idf(w)
+		q := float64(k) * idf
+		qn += q * q
+		for _, p := range c.index[w] {
+			scores[p.doc] += q * float64(p.count) * idf
+		}
+	}
+	mm := make([]Match, 0, len(scores))
+	for d, s := range scores {
+		if s > 0 {
+			mm = append(mm, Match{Index: d, Text: c.docs[d], Score: s / math.Sqrt(qn*c.norms[d])})
+		}
+	}
+	slices.SortFunc(mm, func(a, b Match) int {
+		if a.Score != b.Score {
+			if a.Score > b.Score {
+				return -1
+			}
+			return 1
+		}
+		return a.Index - b.Index
+	})
+	if len(mm) > n {
+		mm = mm[:max(n, 0)]
+	}
+	return mm
+}
+
+// idf returns smoothed inverse document frequency of word w, unknown words
+// get the highest one.
+func (c *Corpus) idf(w string) float64 {
+	return math.Log(float64(len(c.docs)+1)/float64(len(c.index[w])+1)) + 1
+}
+
+func (c *Corpus) counts(s string) map[string]int {
+	m := make(map[string]int)
+	for _, w := range textWords(s) {
+		m[w]++
+	}
+	return m
+}
+
+// TextSearch returns up to n items best matching the query, ranked by a
+// typo-tolerant score mixing Trigram (70%) and Levenshtein (30%) similarity
+// of lowercased texts. The costly Levenshtein distance is computed only for
+// items which can still make it into the top n, so searching large
+// collections stays fast. Items sharing no trigrams with the query are
+// skipped.
+//
+// Example:
+//
+//	for _, m := range ion.TextSearch(suppliers, "acme industres", 5) {
+//		fmt.Println(m.Text, m.Score)
+//	}
+func TextSearch(items []string, query string, n int) []Match {
+	if n <= 0 {
+		return nil
+	}
+	q := strings.ToLower(query)
+	qg := textTrigrams(q)
+	var mm []Match // sorted best first, at most n
+	for i, s := range items {
+		tri := textJaccard(qg, textTrigrams(s))
+		if tri == 0 || len(mm) == n && 0.7*tri+0.3 <= mm[n-1].Score {
+			continue
+		}
+		m := Match{Index: i, Text: s, Score: 0.7*tri + 0.3*Text(q).Levenshtein(strings.ToLower(s))}
+		at, _ := slices.BinarySearchFunc(mm, m, func(a, b Match) int {
+			if a.Score > b.Score {
+				return -1
+			}
+			return 1
+		})
+		if at < n {
+			mm = slices.Insert(mm, at, m)[:min(len(mm)+1, n)]
+		}
+	}
+	return mm
+}
@@ -0,0 +1,199 @@
+package ion
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Diff compares text with to word by word, as one would review how an LLM
+// revision changed a document. Joining Text of all spans except DiffInsert
+// ones gives the original text, all except DiffDelete ones gives to.
+//
+// Example:
+//
+//	d := ion.Text("the quick brown fox").Diff("the slow red fox")
+//	d.Inline() // "the [-quick brown-]{+slow red+} fox"
+func (t Text) Diff(to string) TextDiff {
+	a, b := textDiffTokens.FindAllString(string(t), -1), textDiffTokens.FindAllString(to, -1)
+	var p, s int
+	for p < len(a) && p < len(b) && a[p] == b[p] {
+		p++
+	}
+	for s < len(a)-p && s < len(b)-p && a[len(a)-1-s] == b[len(b)-1-s] {
+		s++
+	}
+
+	var d TextDiff
+	d = d.add(DiffEqual, strings.Join(a[:p], ""))
+	x, y := a[p:len(a)-s], b[p:len(b)-s]
+	// too long to compare word by word, lines are compared instead, or the
+	// whole is replaced when there are too many of them too
+	if len(x)*len(y) > textDiffCells {
+		x, y = strings.SplitAfter(strings.Join(x, ""), "\n"), strings.SplitAfter(strings.Join(y, ""), "\n")
+	}
+	if len(x)*len(y) <= textDiffCells {
+		d = d.lcs(x, y)
+	} else {
+		d = d.add(DiffDelete, strings.Join(x, "")).add(DiffInsert, strings.Join(y, ""))
+	}
+	return d.add(DiffEqual, strings.Join(a[len(a)-s:], ""))
+}
+
+// lcs appends changes of x into y, found with their longest common
+// subsequence.
+func (d TextDiff) lcs(x, y []string) TextDiff {
+	// lcs[i][j] is the longest common subsequence length of x[i:] and y[j:]
+	lcs := make([][]int, len(x)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(y)+1)
+	}
+	for i := len(x) - 1; i >= 0; i-- {
+		for j := len(y) - 1; j >= 0; j-- {
+			if x[i] == y[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+	var del, ins strings.Builder
+	flush := func() {
+		d = d.add(DiffDelete, del.String()).add(DiffInsert, ins.String())
+		del.Reset()
+		ins.Reset()
+	}
+	i, j := 0, 0
+	for i < len(x) || j < len(y) {
+		switch {
+		case i < len(x) && j < len(y) && x[i] == y[j]:
+			// whitespace alone between changes is folded into them,
+			// so "quick brown" → "slow red" is one change, not two
+			if strings.TrimSpace(x[i]) == "" && del.Len()+ins.Len() > 0 && i+1 < len(x) && j+1 < len(y) && x[i+1] != y[j+1] {
+				del.WriteString(x[i])
+				ins.WriteString(y[j])
+			} else {
+				flush()
+				d = d.add(DiffEqual, x[i])
+			}
+			i, j = i+1, j+1
+		case j < len(y) && (i == len(x) || lcs[i][j+1] >= lcs[i+1][j]):
+			ins.WriteString(y[j])
+			j++
+		default:
+			del.WriteString(x[i])
+			i++
+		}
+	}
+	flush()
+	return d
+}
+
+// DiffOp tells what happened to a DiffSpan.
+type DiffOp string
+
+const (
+	DiffEqual  DiffOp = "="
+	DiffInsert DiffOp = "+"
+	DiffDelete DiffOp = "-"
+)
+
+// DiffSpan is a piece of text kept, inserted or deleted.
+type DiffSpan struct {
+	Op   DiffOp `json:"op"`
+	Text string `json:"text"`
+}
+
+// TextDiff is a result of Text.Diff.
+type TextDiff []DiffSpan
+
+// Changed tells if any span was inserted or deleted.
+func (d TextDiff) Changed() bool {
+	for _, s := range d {
+		if s.Op != DiffEqual {
+			return true
+		}
+	}
+	return false
+}
+
+// Inline renders diff in place, deletions as [-text-] and insertions as {+text+}.
+func (d TextDiff) Inline() string {
+	var b strings.Builder
+	for _, s := range d {
+		switch s.Op {
+		case DiffDelete:
+			b.WriteString("[-" + s.Text + "-]")
+		case DiffInsert:
+			b.WriteString("{+" + s.Text + "+}")
+		default:
+			b.WriteString(s.Text)
+		}
+	}
+	return b.String()
+}
+
+// Unified renders diff line by line like diff -u without headers, changed
+// lines are prefixed with "- " and "+ ", unchanged ones with two spaces.
+func (d TextDiff) Unified() string {
+	var b, was, is strings.Builder
+	flush := func() {
+		if was.String() == is.String() {
+			for _, l := range strings.Split(was.String(), "\n") {
+				b.WriteString("  " + l + "\n")
+			}
+		} else {
+			for _, l := range strings.Split(was.String(), "\n") {
+				b.WriteString("- " + l + "\n")
+			}
+			for _, l := range strings.Split(is.String(), "\n") {
+				b.WriteString("+ " + l + "\n")
+			}
+		}
+		was.Reset()
+		is.Reset()
+	}
+	for _, s := range d {
+		if s.Op != DiffEqual {
+			if s.Op == DiffDelete {
+				was.WriteString(s.Text)
+			} else {
+				is.WriteString(s.Text)
+			}
+			continue
+		}
+		ll := strings.Split(s.Text, "\n")
+		for i, l := range ll {
+			was.WriteString(l)
+			is.WriteString(l)
+			if i < len(ll)-1 {
+				flush()
+			}
+		}
+	}
+	if was.Len()+is.Len() > 0 {
+		flush()
+	}
+	return b.String()
+}
+
+func (d TextDiff) String() string {
+	return d.Inline()
+}
+
+// add appends span merging it with the last one of the same operation.
+func (d TextDiff) add(op DiffOp, s string) TextDiff {
+	if s == "" {
+		return d
+	}
+	if n := len(d); n > 0 && d[n-1].Op == op {
+		d[n-1].Text += s
+		return d
+	}
+	return append(d, DiffSpan{op, s})
+}
+
+// textDiffCells limits size of the table comparing tokens, so diff of long
+// texts does not take too much memory.
+const textDiffCells = 1 << 20
+
+var textDiffTokens = regexp.MustCompile(`\s+|[\p{L}\p{N}_']+|[^\s\p{L}\p{N}_']`)
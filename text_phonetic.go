@@ -0,0 +1,376 @@
+package ion
+
+import (
+	"strings"
+)
+
+// Phonetic holds codes of words sounding alike despite different spelling,
+// for every word of the text separated by spaces.
+type Phonetic struct {
+	// Soundex is the classic four character American Soundex code.
+	Soundex string `json:"soundex"`
+	// Metaphone is the primary Double Metaphone code.
+	Metaphone string `json:"metaphone"`
+	// Alternate is the alternate Double Metaphone code, covering other
+	// pronunciations (e.g. Slavic or Germanic) of the same spelling.
+	Alternate string `json:"alternate"`
+}
+
+// Phonetic returns Soundex and Double Metaphone codes of the text, used to
+// deduplicate person and company names whose spelling varies.
+//
+// Example:
+//
+//	ion.Text("Smith").Phonetic()   // {S530 SM0 XMT}
+//	ion.Text("Schmidt").Phonetic() // {S530 XMT SMT}
+func (t Text) Phonetic() Phonetic {
+	var s, m, a []string
+	for _, w := range phoneticWords(string(t)) {
+		p, x := metaphone(w)
+		s, m, a = append(s, soundex(w)), append(m, p), append(a, x)
+	}
+	return Phonetic{Soundex: strings.Join(s, " "), Metaphone: strings.Join(m, " "), Alternate: strings.Join(a, " ")}
+}
+
+// SoundsLike reports whether t and other have the same number of words and
+// every pair of them sounds alike: shares Soundex code or any of Double
+// Metaphone codes.
+//
+// Example:
+//
+//	ion.Text("Jon Smyth").SoundsLike("John Smith") // true
+func (t Text) SoundsLike(other string) bool {
+	a, b := phoneticWords(string(t)), phoneticWords(other)
+	if len(a) != len(b) || len(a) == 0 {
+		return false
+	}
+	for i := range a {
+		if soundex(a[i]) == soundex(b[i]) {
+			continue
+		}
+		p, x := metaphone(a[i])
+		q, y := metaphone(b[i])
+		if p != q && p != y && x != q && x != y {
+			return false
+		}
+	}
+	return true
+}
+
+// phoneticWords returns uppercase words of s with diacritics removed.
+func phoneticWords(s string) []string {
+	s = strings.ToUpper(phoneticLatin.Replace(s))
+	return strings.FieldsFunc(s, func(r rune) bool { return r < 'A' || r > 'Z' })
+}
+
+func soundex(w string) string {
+	if w == "" {
+		return ""
+	}
+	code := func(c byte) byte {
+		switch c {
+		case 'B', 'F', 'P', 'V':
+			return '1'
+		case 'C', 'G', 'J', 'K', 'Q', 'S', 'X', 'Z':
+			return '2'
+		case 'D', 'T':
+			return '3'
+		case 'L':
+			return '4'
+		case 'M', 'N':
+			return '5'
+		case 'R':
+			return '6'
+		}
+		return 0
+	}
+	out, last := []byte{w[0]}, code(w[0])
+	for i := 1; i < len(w) && len(out) < 4; i++ {
+		c := code(w[i])
+		switch {
+		case w[i] == 'H' || w[i] == 'W':
+			continue // do not separate equal codes
+		case c == 0:
+			last = 0
+		case c != last:
+			out, last = append(out, c), c
+		}
+	}
+	for len(out) < 4 {
+		out = append(out, '0')
+	}
+	return string(out)
+}
+
+// metaphone returns primary and alternate Double Metaphone codes of the
+// uppercase word, covering the common rules of the algorithm.
+func metaphone(w string) (string, string) {
+	var p, a strings.Builder
+	add := func(primary, alternate string) {
+		p.WriteString(primary)
+		a.WriteString(alternate)
+	}
+	at := func(i int, ss ...string) bool {
+		for _, s := range ss {
+			if i >= 0 && strings.HasPrefix(w[i:], s) {
+				return true
+			}
+		}
+		return false
+	}
+	vowel := func(i int) bool { return i >= 0 && i < len(w) && strings.IndexByte("AEIOUY", w[i]) >= 0 }
+	slavic := strings.Contains(w, "W") || strings.Contains(w, "K") || strings.Contains(w, "CZ") || strings.Contains(w, "WITZ")
+
+	i := 0
+	if at(0, "GN", "KN", "PN", "WR", "PS") {
+		i = 1
+	}
+	if at(0, "X") {
+		add("S", "S")
+		i = 1
+	}
+	for i < len(w) && (p.Len() < 4 || a.Len() < 4) {
+		c, n := w[i], 1
+		switch c {
+		case 'A', 'E', 'I', 'O', 'U', 'Y':
+			if i == 0 {
+				add("A", "A")
+			}
+		case 'B':
+			add("P", "P")
+			if at(i+1, "B") {
+				n = 2
+			}
+		case 'C':
+			switch {
+			case at(i, "CHR", "CHL") || at(i, "CH") && i == 0 && at(i+2, "OR", "YM", "IA", "EM"):
+				add("K", "K")
+				n = 2
+			case at(i, "CZ") && !at(i-2, "WICZ"):
+				add("S", "X")
+				n = 2
+			case at(i, "CH"):
+				add("X", "K")
+				n = 2
+			case at(i, "CIA"):
+				add("X", "X")
+				n = 3
+			case at(i, "CC") && at(i+2, "I", "E", "H"):
+				add("KS", "KS")
+				n = 3
+			case at(i, "CK", "CG", "CQ"):
+				add("K", "K")
+				n = 2
+			case at(i, "CIO", "CIE"):
+				add("S", "X")
+				n = 2
+			case at(i+1, "I", "E", "Y"):
+				add("S", "S")
+				n = 2
+			default:
+				add("K", "K")
+				if at(i+1, "C", "K", "Q") {
+					n = 2
+				}
+			}
+		case 'D':
+			switch {
+			case at(i, "DG") && at(i+2, "I", "E", "Y"):
+				add("J", "J")
+				n = 3
+			case at(i, "DT", "DD"):
+				add("T", "T")
+				n = 2
+			default:
+				add("T", "T")
+			}
+		case 'F', 'K', 'L', 'M', 'N', 'R':
+			add(string(c), string(c))
+			if i+1 < len(w) && w[i+1] == c {
+				n = 2
+			}
+			if c == 'M' && at(i+1, "B") && i+2 == len(w) {
+				n = 2
+			}
+		case 'G':
+			switch {
+			case at(i, "GH"):
+				switch {
+				case i > 0 && !vowel(i-1):
+					add("K", "K")
+				case i == 0:
+					if at(i+2, "I") {
+						add("J", "J")
+					} else {
+						add("K", "K")
+					}
+				case at(i-1, "UGH") && !at(i-3, "B", "D", "H"):
+					add("F", "F")
+				}
+				n = 2
+			case at(i, "GN"):
+				if i == 1 && vowel(0) && !slavic {
+					add("KN", "N")
+				} else {
+					add("N", "KN")
+				}
+				n = 2
+			case at(i+1, "E", "I", "Y"):
+				if i == 0 {
+					add("K", "J")
+				} else {
+					add("J", "K")
+				}
+				n = 2
+			default:
+				add("K", "K")
+				if at(i+1, "G") {
+					n = 2
+				}
+			}
+		case 'H':
+			if (i == 0 || vowel(i-1)) && vowel(i+1) {
+				add("H", "H")
+				n = 2
+			}
+		case 'J':
+			if at(i, "JOSE") || at(0, "SAN ") {
+				add("H", "H")
+			} else if i == 0 {
+				add("J", "A")
+			} else {
+				add("J", "H")
+			}
+			if at(i+1, "J") {
+				n = 2
+			}
+		case 'P':
+			switch {
+			case at(i, "PH"):
+				add("F", "F")
+				n = 2
+			default:
+				add("P", "P")
+				if at(i+1, "P", "B") {
+					n = 2
+				}
+			}
+		case 'Q':
+			add("K", "K")
+			if at(i+1, "Q") {
+				n = 2
+			}
+		case 'S':
+			switch {
+			case at(i, "SCH"):
+				if at(i+3, "ER", "EN") {
+					add("X", "SK")
+				} else if i == 0 && !vowel(3) && !at(3, "W") {
+					add("X", "S")
+				} else {
+					add("SK", "SK")
+				}
+				n = 3
+			case at(i, "SH"):
+				add("X", "X")
+				n = 2
+			case at(i, "SIO", "SIA"):
+				add("S", "X")
+				n = 3
+			case i == 0 && at(i+1, "M", "N", "L", "W"):
+				add("S", "X")
+				if at(i+1, "Z") {
+					n = 2
+				}
+			case at(i, "SC") && at(i+2, "I", "E", "Y"):
+				add("S", "S")
+				n = 3
+			case at(i, "SZ"):
+				add("S", "X")
+				n = 2
+			default:
+				add("S", "S")
+				if at(i+1, "S", "Z") {
+					n = 2
+				}
+			}
+		case 'T':
+			switch {
+			case at(i, "TION", "TIA", "TCH"):
+				add("X", "X")
+				n = 3
+			case at(i, "TH"):
+				if at(i+2, "OM", "AM") {
+					add("T", "T")
+				} else {
+					add("0", "T")
+				}
+				n = 2
+			default:
+				add("T", "T")
+				if at(i+1, "T", "D") {
+					n = 2
+				}
+			}
+		case 'V':
+			add("F", "F")
+			if at(i+1, "V") {
+				n = 2
+			}
+		case 'W':
+			switch {
+			case at(i, "WR"):
+				add("R", "R")
+				n = 2
+			case i == 0 && (vowel(1) || at(1, "H")):
+				add("A", "F")
+			case at(i, "WICZ", "WITZ"):
+				add("TS", "FX")
+				n = 4
+			case i == len(w)-1 && vowel(i-1) || at(i-1, "EWSKI", "EWSKY", "OWSKI", "OWSKY") || vowel(i-1) && vowel(i+1):
+				add("", "F")
+			}
+		case 'X':
+			if !(i == len(w)-1 && at(i-2, "AU", "OU")) {
+				add("KS", "KS")
+			}
+			if at(i+1, "C", "X") {
+				n = 2
+			}
+		case 'Z':
+			switch {
+			case at(i, "ZH"):
+				add("J", "J")
+				n = 2
+			case at(i+1, "O", "I", "A") && slavic:
+				add("S", "TS")
+			default:
+				add("S", "S")
+			}
+			if at(i+1, "Z") {
+				n = 2
+			}
+		}
+		i += n
+	}
+	cut := func(s string) string { return s[:min(len(s), 4)] }
+	return cut(p.String()), cut(a.String())
+}
+
+// phoneticLatin folds latin letters with diacritics to ASCII ones.
+var phoneticLatin = strings.NewReplacer(
+	"ą", "a", "à", "a", "á", "a", "â", "a", "ã", "a", "ä", "a", "å", "a",
+	"ć", "c", "ç", "c", "č", "c", "ď", "d",
+	"ę", "e", "è", "e", "é", "e", "ê", "e", "ë", "e", "ě", "e",
+	"ì", "i", "í", "i", "î", "i", "ï", "i", "ł", "l",
+	"ń", "n", "ñ", "n", "ň", "n", "ò", "o", "ó", "o", "ô", "o", "õ", "o", "ö", "o", "ø", "o",
+	"ř", "r", "ś", "s", "š", "s", "ß", "ss", "ť", "t",
+	"ù", "u", "ú", "u", "û", "u", "ü", "u", "ů", "u", "ý", "y", "ź", "z", "ż", "z", "ž", "z",
+	"Ą", "A", "À", "A", "Á", "A", "Â", "A", "Ã", "A", "Ä", "A", "Å", "A",
+	"Ć", "C", "Ç", "C", "Č", "C", "Ď", "D",
+	"Ę", "E", "È", "E", "É", "E", "Ê", "E", "Ë", "E", "Ě", "E",
+	"Ì", "I", "Í", "I", "Î", "I", "Ï", "I", "Ł", "L",
+	"Ń", "N", "Ñ", "N", "Ň", "N", "Ò", "O", "Ó", "O", "Ô", "O", "Õ", "O", "Ö", "O", "Ø", "O",
+	"Ř", "R", "Ś", "S", "Š", "S", "Ť", "T",
+	"Ù", "U", "Ú", "U", "Û", "U", "Ü", "U", "Ů", "U", "Ý", "Y", "Ź", "Z", "Ż", "Z", "Ž", "Z",
+)
@@ -0,0 +1,71 @@
+package ion
+
+import (
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// RedactPII masks personal data in text: email addresses, phone numbers,
+// IBANs and credit card numbers, plus patterns registered with UsePII. Use it
+// on logs and LLM prompts, so both are sanitized the same way.
+//
+// Example:
+//
+//	ion.Text("call +48 601 222 333 or mail jan@x.io").RedactPII() // "call *** or mail ***"
+func (t Text) RedactPII() Text {
+	return Text(redactorPII.Text(string(t)))
+}
+
+// UsePII registers custom named PII pattern masked by Text.RedactPII, using
+// the name of a built-in one (card, iban, email, phone) replaces it.
+func UsePII(name, expr string) {
+	redactorPII.Pattern(name, expr)
+}
+
+// newRedactorPII creates Redactor with PII patterns only, no keys.
+func newRedactorPII() *Redactor {
+	r := &Redactor{Mask: "***"}
+	r.rules = append(r.rules,
+		redactRule{"card", regexp.MustCompile(`\b(?:\d[ -]?){12,18}\d\b`), luhn},
+		redactRule{"iban", regexp.MustCompile(`\b[A-Z]{2}\d{2}(?: ?[A-Z0-9]{4}){2,7}(?: ?[A-Z0-9]{1,3})?\b`), iban},
+		redactRule{"email", regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`), nil},
+		redactRule{"phone", regexp.MustCompile(`(?:\+\d{1,3}[ -]?|\b)\d{2,4}[ -]?\d{3}[ -]?\d{3,4}\b`), phone},
+	)
+	return r
+}
+
+// iban validates ISO 13616 check digits so random uppercase codes are left intact.
+func iban(s string) bool {
+	s = strings.ReplaceAll(s, " ", "")
+	if len(s) < 15 || len(s) > 34 {
+		return false
+	}
+	var b strings.Builder
+	for _, c := range s[4:] + s[:4] {
+		switch {
+		case c >= '0' && c <= '9':
+			b.WriteRune(c)
+		case c >= 'A' && c <= 'Z':
+			b.WriteString(strconv.Itoa(int(c-'A') + 10))
+		default:
+			return false
+		}
+	}
+	n, ok := new(big.Int).SetString(b.String(), 10)
+	return ok && n.Mod(n, big.NewInt(97)).Int64() == 1
+}
+
+// phone accepts numbers of 9 to 15 digits, the E.164 range for subscriber numbers.
+func phone(s string) bool {
+	var n int
+	for _, c := range s {
+		if c >= '0' && c <= '9' {
+			n++
+		}
+	}
+	return n >= 9 && n <= 15
+}
+
+var redactorPII = newRedactorPII()
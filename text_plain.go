@@ -0,0 +1,64 @@
+package ion
+
+import (
+	"html"
+	"regexp"
+	"strings"
+)
+
+// Plain strips Markdown and HTML markup leaving readable plain text, as
+// similarity functions and token-limited prompts should see content only.
+// Headings, emphasis, links, images, code fences, quotes and tags are
+// removed, list items are kept as "- " lines (numbered ones keep numbers),
+// table cells are separated with spaces and HTML entities are decoded.
+//
+// Example:
+//
+//	ion.Text("# Title\n\nSee **[docs](https://x.io)**.").Plain() // "Title\n\nSee docs."
+func (t Text) Plain() Text {
+	s := strings.ReplaceAll(string(t), "\r\n", "\n")
+	for _, r := range textPlain {
+		s = r.re.ReplaceAllString(s, r.with)
+	}
+	s = html.UnescapeString(s)
+	ll := strings.Split(s, "\n")
+	for i := range ll {
+		ll[i] = strings.TrimRight(ll[i], " \t")
+	}
+	s = strings.Join(ll, "\n")
+	s = textBlankLines.ReplaceAllString(s, "\n\n")
+	return Text(strings.TrimSpace(s))
+}
+
+var textBlankLines = regexp.MustCompile(`\n{3,}`)
+
+// textPlain are rewrite rules of Plain applied in order.
+var textPlain = []struct {
+	re   *regexp.Regexp
+	with string
+}{
+	// html
+	{regexp.MustCompile(`(?is)<(script|style|head)[^>]*>.*?</(script|style|head)>`), ""},
+	{regexp.MustCompile(`(?s)<!--.*?-->`), ""},
+	{regexp.MustCompile(`(?i)<li[^>]*>`), "\n- "},
+	{regexp.MustCompile(`(?i)<br\s*/?>|</(p|div|li|tr|h[1-6]|ul|ol|table|blockquote)>`), "\n"},
+	{regexp.MustCompile(`(?i)</?(p|div|h[1-6]|ul|ol|table|blockquote)[^>]*>`), "\n"},
+	{regexp.MustCompile(`(?i)</t[dh]>`), " "},
+	{regexp.MustCompile(`<[^>\n]+>`), ""},
+	// markdown blocks
+	{regexp.MustCompile("(?m)^[ \\t]*(```|~~~).*$\\n?"), ""},
+	{regexp.MustCompile(`(?m)^[ \t]{0,3}#{1,6}[ \t]+(.*?)[ \t]*#*$`), "$1"},
+	{regexp.MustCompile(`(?m)^[ \t]{0,3}([-*_][ \t]*){3,}$\n?`), ""},
+	{regexp.MustCompile(`(?m)^[ \t]{0,3}>[ \t]?`), ""},
+	{regexp.MustCompile(`(?m)^([ \t]*)[*+-][ \t]+(\[[ xX]\][ \t]+)?`), "$1- "},
+	{regexp.MustCompile(`(?m)^[ \t]*\|?[ \t]*:?-+:?[ \t]*(\|[ \t]*:?-+:?[ \t]*)*\|?[ \t]*$\n?`), ""},
+	{regexp.MustCompile(`(?m)^[ \t]*\|[ \t]*(.*?)[ \t]*\|?[ \t]*$`), "$1"},
+	{regexp.MustCompile(`[ \t]*\|[ \t]*`), " "},
+	// markdown inline
+	{regexp.MustCompile(`!\[([^\]]*)\]\([^)]*\)`), "$1"},
+	{regexp.MustCompile(`\[([^\]]+)\]\([^)]*\)`), "$1"},
+	{regexp.MustCompile("`([^`]*)`"), "$1"},
+	{regexp.MustCompile(`(\*\*|__)(\S(?:.*?\S)?)(\*\*|__)`), "$2"},
+	{regexp.MustCompile(`(^|[^\w*])[*_](\S(?:[^*_]*?\S)?)[*_]([^\w*]|$)`), "$1$2$3"},
+	{regexp.MustCompile(`~~(.+?)~~`), "$1"},
+}
@@ -1,7 +1,9 @@
 package ion_test
 
 import (
+	"fmt"
 	"math"
+	"strings"
 	"testing"
 
 	. "github.com/sokool/ion"
@@ -64,3 +66,282 @@ func TestHash(t *testing.T) {
 		})
 	}
 }
+
+func TestText_Similarity(t *testing.T) {
+	round := func(f float64) float64 { return math.Round(f*1000) / 1000 }
+	if v := round(Text("Baker Street 221B London").Jaccard("London, 221b baker street")); v != 1 {
+		t.Fatalf("expected identical word sets, got %v", v)
+	}
+	if v := round(Text("Baker Street London").Jaccard("Baker Road London")); v != 0.5 {
+		t.Fatalf("unexpected jaccard %v", v)
+	}
+	if v := round(Text("Marszałkowska 10 Warszawa").Trigram("Marszalkowska 10 Warszawa")); v <= 0.6 || v >= 1 {
+		t.Fatalf("unexpected trigram of a typo %v", v)
+	}
+	if v := Text("").Trigram(""); v != 1 {
+		t.Fatalf("expected empty texts equal, got %v", v)
+	}
+	a, b := "1600 Pennsylvania Avenue Washington USA", "1600 Penn Ave Washington United States"
+	if round(Text(a).Score(b, Weight{Text.Cosine, 20}, Weight{Text.Levenshtein, 80})) != round(Text(a).Compare(b, 20, 80)) {
+		t.Fatal("expected Compare to be Score of cosine and levenshtein")
+	}
+	exp := (Text(a).Trigram(b) + Text(a).Jaccard(b)) / 2
+	if v := Text(a).Score(b, Weight{Text.Trigram, 0}, Weight{Metric: Text.Jaccard}); round(v) != round(exp) {
+		t.Fatalf("expected equal weights, got %v", v)
+	}
+}
+
+func TestCorpus(t *testing.T) {
+	c := NewCorpus(
+		"221B Baker Street London",
+		"10 Downing Street London",
+		"Baker Road Manchester",
+	).Add("Abbey Road London")
+	mm := c.Similar("baker street london", 2)
+	if len(mm) != 2 || mm[0].Index != 0 || round3(mm[0].Score) >= 1 || mm[0].Score <= mm[1].Score {
+		t.Fatalf("unexpected matches %+v", mm)
+	}
+	if mm = c.Similar("221B Baker Street London", 1); round3(mm[0].Score) != 1 {
+		t.Fatalf("expected exact match, got %+v", mm)
+	}
+	if mm = c.Similar("Kraków", 5); len(mm) != 0 {
+		t.Fatalf("expected no matches, got %+v", mm)
+	}
+	if c.Len() != 4 {
+		t.Fatalf("expected 4 documents, got %d", c.Len())
+	}
+}
+
+func round3(f float64) float64 { return math.Round(f*1000) / 1000 }
+
+func TestText_Tokens(t *testing.T) {
+	cases := []struct {
+		text  string
+		model string
+		exact int // count of tiktoken encoding of the model
+		off   int // tolerated error of the estimate
+	}{
+		{"Hello, world!", "gpt-4", 4, 0},
+		{"hello world", "gpt-4", 2, 0},
+		{"The quick brown fox jumps over the lazy dog.", "gpt-4", 10, 0},
+		{"1234567", "gpt-4", 3, 0},
+		{"tiktoken is great!", "gpt-4", 6, 2}, // rare word split into pieces
+		{"Hello, world!", "gpt-4o", 4, 0},
+		{"hello world", "gpt-4o", 2, 0},
+		{"", "gpt-4o", 0, 0},
+	}
+	for _, c := range cases {
+		if n := Text(c.text).Tokens(c.model); n < c.exact-c.off || n > c.exact+c.off {
+			t.Fatalf("expected %d±%d tokens of %q for %s, got %d", c.exact, c.off, c.text, c.model, n)
+		}
+	}
+	pl := Text("Zażółć gęślą jaźń, proszę.")
+	if pl.Tokens("gpt-4") <= pl.Tokens("gpt-4o") {
+		t.Fatal("expected o200k estimate lower for non-english text")
+	}
+}
+
+func TestText_Truncate(t *testing.T) {
+	s := Text("The quick, brown fox jumps over the lazy dog")
+	for _, c := range []struct {
+		limit int
+		unit  TruncateUnit
+		exp   Text
+	}{
+		{12, TruncateRunes, "The quick…"},
+		{17, TruncateRunes, "The quick, brown…"},
+		{100, TruncateRunes, s},
+		{3, TruncateRunes, "Th…"},
+		{2, TruncateWords, "The quick…"},
+		{9, TruncateWords, s},
+		{0, TruncateWords, ""},
+	} {
+		if r := s.Truncate(c.limit, c.unit); r != c.exp {
+			t.Fatalf("expected %q for %d/%d, got %q", c.exp, c.limit, c.unit, r)
+		}
+	}
+	if r := Text("Zażółć gęślą jaźń").Truncate(10, TruncateRunes); r != "Zażółć…" {
+		t.Fatalf("unexpected rune truncation %q", r)
+	}
+	if r := s.TruncateTokens(4, "gpt-4o"); r.Tokens("gpt-4o") > 4 || r == "" || r == s {
+		t.Fatalf("unexpected token truncation %q", r)
+	}
+}
+
+func TestTextSearch(t *testing.T) {
+	items := []string{"Acme Industries Ltd", "Globex Corporation", "Acme Foods", "Initech", "ACME Industrial Supply"}
+	mm := TextSearch(items, "acme industres", 2)
+	if len(mm) != 2 || mm[0].Index != 0 || mm[1].Index != 4 || mm[0].Score <= mm[1].Score {
+		t.Fatalf("unexpected matches %+v", mm)
+	}
+	if mm = TextSearch(items, "xyz", 3); len(mm) != 0 {
+		t.Fatalf("expected no matches, got %+v", mm)
+	}
+	if mm = TextSearch(items, "initech", 10); len(mm) == 0 || mm[0].Text != "Initech" || round3(mm[0].Score) != 1 {
+		t.Fatalf("expected exact match first, got %+v", mm)
+	}
+}
+
+func TestText_Phonetic(t *testing.T) {
+	for w, exp := range map[string]Phonetic{
+		"Smith":    {"S530", "SM0", "XMT"},
+		"Schmidt":  {"S530", "XMT", "SMT"},
+		"Thompson": {"T512", "TMPS", "TMPS"},
+		"Kowalski": {"K420", "KLSK", "KFLS"},
+		"Knight":   {"K523", "NT", "NT"},
+		"Philip":   {"P410", "FLP", "FLP"},
+		"Jan Łoś":  {"J500 L200", "JN LS", "AN LS"},
+	} {
+		if p := Text(w).Phonetic(); p != exp {
+			t.Fatalf("expected %v for %s, got %v", exp, w, p)
+		}
+	}
+	for a, b := range map[string]string{"Jon Smyth": "John Smith", "Catherine": "Kathryn", "Filip": "Philip", "Schmidt": "Smith", "Kowalsky": "Kowalski"} {
+		if !Text(a).SoundsLike(b) {
+			t.Fatalf("expected %s sounds like %s", a, b)
+		}
+	}
+	for a, b := range map[string]string{"Robert": "Albert", "John Smith": "John", "": ""} {
+		if Text(a).SoundsLike(b) {
+			t.Fatalf("expected %s doesn't sound like %s", a, b)
+		}
+	}
+}
+
+func TestText_NGrams(t *testing.T) {
+	if m := Text("Abab").NGrams(2); fmt.Sprint(m) != "map[ab:2 ba:1]" {
+		t.Fatalf("unexpected ngrams %v", m)
+	}
+	if m := Text("Łódź  łó").NGrams(3); fmt.Sprint(m) != "map[ łó:1 dź :1 ódź:1 łód:1 ź ł:1]" {
+		t.Fatalf("unexpected unicode ngrams %v", m)
+	}
+	if m := Text("To be, or not to be!").WordNGrams(2); fmt.Sprint(m) != "map[be or:1 not to:1 or not:1 to be:2]" {
+		t.Fatalf("unexpected word ngrams %v", m)
+	}
+	if len(Text("ab").NGrams(3)) != 0 || len(Text("ab").WordNGrams(0)) != 0 {
+		t.Fatal("expected empty ngrams")
+	}
+}
+
+func TestText_Plain(t *testing.T) {
+	md := Text("# Orders report\n\nSee **[the docs](https://x.io)** and _notes_ with `code`.\n\n" +
+		"* first\n+ second\n  - nested\n1. one\n\n> quoted\n\n---\n\n| id | name |\n|---|:---:|\n| 1 | Tom |\n\n```go\nx := 1\n```\n![logo](l.png) snake_case_name")
+	exp := "Orders report\n\nSee the docs and notes with code.\n\n- first\n- second\n  - nested\n1. one\n\nquoted\n\nid name\n1 Tom\n\nx := 1\nlogo snake_case_name"
+	if s := md.Plain(); s != Text(exp) {
+		t.Fatalf("expected\n%q\ngot\n%q", exp, s)
+	}
+	h := Text(`<html><head><title>x</title></head><body><h1>Hi&amp;bye</h1><p>Some <b>bold</b><br>text</p>` +
+		`<ul><li>a</li><li>b</li></ul><script>alert(1)</script><table><tr><td>1</td><td>Tom</td></tr></table></body></html>`)
+	if s := h.Plain(); s != "Hi&bye\n\nSome bold\ntext\n\n- a\n\n- b\n\n1 Tom" {
+		t.Fatalf("unexpected html plain %q", s)
+	}
+}
+
+func TestText_RedactPII(t *testing.T) {
+	UsePII("ticket", `TCK-\d{6}`)
+	cases := map[string]string{
+		"mail jan.kowalski@example.com now":       "mail *** now",
+		"call +48 601 222 333 or 601-222-333":     "call *** or ***",
+		"pay to GB82 WEST 1234 5698 7654 32":      "pay to ***",
+		"pay to GB82WEST12345698765432.":          "pay to ***.",
+		"card 4111 1111 1111 1111 expires":        "card *** expires",
+		"order 4111 1111 1111 1112 on 2024-01-02": "order 4111 1111 1111 1112 on 2024-01-02",
+		"invalid GB00WEST12345698765432 kept":     "invalid GB00WEST12345698765432 kept",
+		"see TCK-123456 at 10:30":                 "see *** at 10:30",
+	}
+	for in, out := range cases {
+		if s := Text(in).RedactPII(); string(s) != out {
+			t.Fatalf("expected %q, got %q", out, s)
+		}
+	}
+}
+
+func TestText_Cases(t *testing.T) {
+	cases := []struct{ in, snake, kebab, camel, title string }{
+		{"HTTPServer ID", "http_server_id", "http-server-id", "httpServerId", "Http Server Id"},
+		{"parseHTTPRequest2xx_ok", "parse_http_request2xx_ok", "parse-http-request2xx-ok", "parseHttpRequest2xxOk", "Parse Http Request2xx Ok"},
+		{"  user-name.first ", "user_name_first", "user-name-first", "userNameFirst", "User Name First"},
+		{"Base64Encode", "base64_encode", "base64-encode", "base64Encode", "Base64 Encode"},
+		{"zażółć gęśląJaźń", "zażółć_gęślą_jaźń", "zażółć-gęślą-jaźń", "zażółćGęśląJaźń", "Zażółć Gęślą Jaźń"},
+		{"", "", "", "", ""},
+	}
+	for _, c := range cases {
+		x := Text(c.in)
+		if s := x.Snake(); string(s) != c.snake {
+			t.Fatalf("snake of %q expected %q, got %q", c.in, c.snake, s)
+		}
+		if s := x.Kebab(); string(s) != c.kebab {
+			t.Fatalf("kebab of %q expected %q, got %q", c.in, c.kebab, s)
+		}
+		if s := x.Camel(); string(s) != c.camel {
+			t.Fatalf("camel of %q expected %q, got %q", c.in, c.camel, s)
+		}
+		if s := x.Title(); string(s) != c.title {
+			t.Fatalf("title of %q expected %q, got %q", c.in, c.title, s)
+		}
+	}
+}
+
+func TestText_Diff(t *testing.T) {
+	a := "The quick brown fox.\nJumps over the dog.\nThe end."
+	b := "The slow red fox.\nJumps over the dog.\nThe end!"
+	d := Text(a).Diff(b)
+	if s := d.Inline(); s != "The [-quick brown-]{+slow red+} fox.\nJumps over the dog.\nThe end[-.-]{+!+}" {
+		t.Fatalf("unexpected inline diff %q", s)
+	}
+	if s := d.Unified(); s != "- The quick brown fox.\n+ The slow red fox.\n  Jumps over the dog.\n- The end.\n+ The end!\n" {
+		t.Fatalf("unexpected unified diff %q", s)
+	}
+	var was, is string
+	for _, s := range d {
+		if s.Op != DiffInsert {
+			was += s.Text
+		}
+		if s.Op != DiffDelete {
+			is += s.Text
+		}
+	}
+	if was != a || is != b {
+		t.Fatalf("spans do not rebuild texts, got %q and %q", was, is)
+	}
+	if Text(a).Diff(a).Changed() || !d.Changed() {
+		t.Fatal("expected only different texts to be changed")
+	}
+	if s := Text("").Diff("new words").Inline(); s != "{+new words+}" {
+		t.Fatalf("unexpected diff of empty text %q", s)
+	}
+}
+
+func TestText_DiffLong(t *testing.T) {
+	for _, n := range []int{500, 2000} { // compared by lines, replaced as whole
+		var a, b strings.Builder
+		for i := range n {
+			fmt.Fprintf(&a, "line %d of the long text\n", i)
+			if i == 0 || i == n/2 || i == n-1 {
+				fmt.Fprintf(&b, "line %d of changed text\n", i)
+				continue
+			}
+			fmt.Fprintf(&b, "line %d of the long text\n", i)
+		}
+		d := Text(a.String()).Diff(b.String())
+		var was, is string
+		var equal int
+		for _, s := range d {
+			if s.Op == DiffEqual {
+				equal += len(s.Text)
+			}
+			if s.Op != DiffInsert {
+				was += s.Text
+			}
+			if s.Op != DiffDelete {
+				is += s.Text
+			}
+		}
+		if was != a.String() || is != b.String() {
+			t.Fatalf("%d lines: spans do not rebuild texts", n)
+		}
+		if n == 500 && equal < a.Len()/2 {
+			t.Fatalf("%d lines: expected unchanged lines kept, got %d of %d bytes", n, equal, a.Len())
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package ion
+
+import (
+	"math"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Tokens returns heuristic estimate of LLM tokens of the text for the model,
+// used to budget context windows and truncate prompts, not to bill, usage
+// reported by vendors is exact. No BPE encoding is run, as encoder tables are
+// not bundled: text is split with the cl100k and o200k pre-tokenization
+// pattern (words with their leading space, numbers in groups of up to three
+// digits, punctuation runs, new lines) and every piece is estimated from its
+// length and script, so rare words, code and non-latin scripts may be off
+// more than common English. Models of gpt-4o, gpt-4.1, gpt-5 and o-series
+// use the o200k estimate, other ones cl100k.
+//
+// Example:
+//
+//	if ion.Text(prompt).Tokens("gpt-4o") > 100_000 {
+//		...
+//	}
+func (t Text) Tokens(model string) int {
+	o200k := textO200k.MatchString(strings.ToLower(model))
+	var n float64
+	for _, p := range textPretokens.FindAllString(string(t), -1) {
+		n += textPretoken(p, o200k)
+	}
+	return int(math.Ceil(n))
+}
+
+// textPretoken estimates tokens of a single pre-token.
+func textPretoken(p string, o200k bool) float64 {
+	w := strings.TrimLeft(p, " ")
+	if w == "" {
+		return 1
+	}
+	r, _ := utf8.DecodeRuneInString(w)
+	n := float64(utf8.RuneCountInString(w))
+	switch {
+	case unicode.IsDigit(r):
+		return 1
+	case unicode.IsSpace(r):
+		return 1
+	case unicode.Is(unicode.Han, r) || unicode.Is(unicode.Hiragana, r) || unicode.Is(unicode.Katakana, r) || unicode.Is(unicode.Hangul, r):
+		if o200k {
+			return math.Ceil(n * 0.7)
+		}
+		return n
+	case !unicode.IsLetter(r) && !unicode.IsLetter(lastRune(w)):
+		return math.Ceil(n / 2)
+	case len(w) != int(n) || r > unicode.MaxASCII:
+		// accented latin, cyrillic and similar scripts split into more pieces
+		if o200k {
+			return math.Ceil(n / 3.5)
+		}
+		return math.Ceil(n / 2.5)
+	case n <= 8:
+		return 1
+	}
+	if o200k {
+		return math.Ceil(n / 5.5)
+	}
+	return math.Ceil(n / 5)
+}
+
+func lastRune(s string) rune {
+	r, _ := utf8.DecodeLastRuneInString(s)
+	return r
+}
+
+var (
+	// textPretokens mirrors cl100k/o200k pre-tokenization, without the
+	// trailing whitespace lookahead RE2 does not support.
+	textPretokens = regexp.MustCompile(`(?i:'s|'t|'re|'ve|'m|'ll|'d)|[^\r\n\p{L}\p{N}]?\p{L}+|\p{N}{1,3}| ?[^\s\p{L}\p{N}]+[\r\n]*|\s*[\r\n]+|\s+`)
+	textO200k     = regexp.MustCompile(`gpt-4o|gpt-4\.1|gpt-5|chatgpt-4o|^o\d`)
+)
@@ -0,0 +1,169 @@
+package ion
+
+import (
+	"errors"
+	"fmt"
+	"net/mail"
+	"net/url"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// Validate checks struct fields against rules of their `validate` tags,
+// recursively through nested structs, pointers, slices and maps. All
+// failures are returned joined, each as ErrValidation carrying the field
+// path built from json names, so it can be shown to API clients or LLMs.
+//
+// Rules, separated by commas:
+//   - required: value is not zero (nil, empty string, 0, empty slice),
+//   - min=n, max=n, len=n: number value or length of string, slice or map,
+//   - oneof=a b c: value is one of space separated options,
+//   - email, url, uuid: string format, empty strings are allowed unless required.
+//
+// Example:
+//
+//	type Signup struct {
+//		Email string   `json:"email" validate:"required,email"`
+//		Age   int      `json:"age" validate:"min=18"`
+//		Tags  []string `json:"tags" validate:"max=5"`
+//	}
+//	err := ion.Validate(s) // validation:email is required\nvalidation:age must be at least 18
+func Validate(v any) error {
+	x := validator{seen: make(map[reflectSeen]bool)}
+	x.validate(reflect.ValueOf(v), "", "")
+	return errors.Join(x.errs...)
+}
+
+// validator keeps failures and pointers visited by Validate, so cyclic
+// structures are checked once.
+type validator struct {
+	errs []error
+	seen map[reflectSeen]bool
+}
+
+func (x *validator) validate(v reflect.Value, path, rules string) {
+	for v.IsValid() && v.Kind() == reflect.Pointer && !v.IsNil() {
+		k := reflectSeen{v.Pointer(), v.Type()}
+		if x.seen[k] {
+			return
+		}
+		x.seen[k] = true
+		v = v.Elem()
+	}
+	v = diffElem(v)
+	errs := &x.errs
+	if !v.IsValid() {
+		if slices.Contains(strings.Split(rules, ","), "required") {
+			*errs = append(*errs, ErrValidation.New("%s is required", path))
+		}
+		return
+	}
+	for _, r := range strings.Split(rules, ",") {
+		if r = strings.TrimSpace(r); r == "" {
+			continue
+		}
+		if msg := validateRule(v, r); msg != "" {
+			*errs = append(*errs, ErrValidation.New("%s %s", path, msg))
+			if r == "required" {
+				return
+			}
+		}
+	}
+	if reflectLeaf(v.Type()) {
+		return
+	}
+	switch v.Kind() {
+	case reflect.Struct:
+		for _, f := range reflect.VisibleFields(v.Type()) {
+			if !f.IsExported() || f.Anonymous {
+				continue
+			}
+			n, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+			if n == "" || n == "-" {
+				n = f.Name
+			}
+			fv, err := v.FieldByIndexErr(f.Index)
+			if err != nil {
+				continue // promoted through nil embedded pointer
+			}
+			x.validate(fv, mapPath(path, n), f.Tag.Get("validate"))
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			x.validate(v.Index(i), mapPath(path, strconv.Itoa(i)), "")
+		}
+	case reflect.Map:
+		for it := v.MapRange(); it.Next(); {
+			x.validate(it.Value(), mapPath(path, fmt.Sprint(it.Key())), "")
+		}
+	}
+}
+
+// validateRule returns description of the rule violation, empty when v is valid.
+func validateRule(v reflect.Value, rule string) string {
+	name, arg, _ := strings.Cut(rule, "=")
+	s, isString := "", v.Kind() == reflect.String
+	if isString {
+		s = v.String()
+	}
+	switch name {
+	case "required":
+		if v.IsZero() || (v.Kind() == reflect.Slice || v.Kind() == reflect.Map) && v.Len() == 0 {
+			return "is required"
+		}
+	case "min", "max", "len":
+		n, err := strconv.ParseFloat(arg, 64)
+		if err != nil {
+			return fmt.Sprintf("has invalid %s rule", name)
+		}
+		x, what := 0.0, "length"
+		switch v.Kind() {
+		case reflect.String, reflect.Slice, reflect.Array, reflect.Map:
+			x = float64(v.Len())
+			if isString {
+				x = float64(len([]rune(s)))
+			}
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			x, what = float64(v.Int()), "value"
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			x, what = float64(v.Uint()), "value"
+		case reflect.Float32, reflect.Float64:
+			x, what = v.Float(), "value"
+		default:
+			return fmt.Sprintf("does not support %s rule", name)
+		}
+		switch {
+		case name == "min" && x < n:
+			return fmt.Sprintf("%s must be at least %s", what, arg)
+		case name == "max" && x > n:
+			return fmt.Sprintf("%s must be at most %s", what, arg)
+		case name == "len" && x != n:
+			return fmt.Sprintf("%s must be %s", what, arg)
+		}
+	case "oneof":
+		if x := fmt.Sprint(v.Interface()); !slices.Contains(strings.Fields(arg), x) {
+			return fmt.Sprintf("must be one of %s", strings.Join(strings.Fields(arg), ", "))
+		}
+	case "email":
+		if a, err := mail.ParseAddress(s); s != "" && (err != nil || a.Address != s) {
+			return "must be an email address"
+		}
+	case "url":
+		if u, err := url.Parse(s); s != "" && (err != nil || u.Scheme == "" || u.Host == "") {
+			return "must be an absolute url"
+		}
+	case "uuid":
+		if _, err := uuid.Parse(s); s != "" && err != nil {
+			return "must be an uuid"
+		}
+	default:
+		return fmt.Sprintf("has unknown %s rule", name)
+	}
+	return ""
+}
+
+var ErrValidation = Errorf("validation")
@@ -0,0 +1,82 @@
+package ion_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/sokool/ion"
+)
+
+func TestValidate(t *testing.T) {
+	type address struct {
+		City string `json:"city" validate:"required"`
+	}
+	type signup struct {
+		Email   string            `json:"email" validate:"required,email"`
+		Age     int               `json:"age" validate:"min=18,max=130"`
+		Plan    string            `json:"plan" validate:"oneof=free pro"`
+		Site    string            `json:"site" validate:"url"`
+		ID      string            `validate:"uuid"`
+		Tags    []string          `json:"tags" validate:"max=2"`
+		Home    *address          `json:"home" validate:"required"`
+		Work    *address          `json:"work"`
+		Offices []address         `json:"offices"`
+		Codes   map[string]string `validate:"len=1"`
+	}
+	err := ion.Validate(signup{
+		Email:   "tom",
+		Age:     12,
+		Plan:    "gold",
+		Site:    "example.com",
+		ID:      "x",
+		Tags:    []string{"a", "b", "c"},
+		Offices: []address{{City: "Warsaw"}, {}},
+	})
+	exp := []string{
+		"validation:email must be an email address",
+		"validation:age value must be at least 18",
+		"validation:plan must be one of free, pro",
+		"validation:site must be an absolute url",
+		"validation:ID must be an uuid",
+		"validation:tags length must be at most 2",
+		"validation:home is required",
+		"validation:offices.1.city is required",
+		"validation:Codes length must be 1",
+	}
+	if !ion.ErrValidation.In(err) || err.Error() != strings.Join(exp, "\n") {
+		t.Fatalf("unexpected errors\n%v", err)
+	}
+	ok := signup{Email: "tom@example.com", Age: 30, Plan: "pro", Home: &address{City: "Kraków"}, Codes: map[string]string{"a": "b"}}
+	if err = ion.Validate(&ok); err != nil {
+		t.Fatalf("expected valid, got %s", err)
+	}
+}
+
+type validateBase struct {
+	ID string `json:"id" validate:"required"`
+}
+
+type validateNamed struct {
+	Name string `json:"name" validate:"required"`
+}
+
+func (validateNamed) String() string { return "named" }
+
+type validateNode struct {
+	*validateBase
+	Named validateNamed `json:"named"`
+	Next  *validateNode `json:"next"`
+	Body  ion.JSON      `json:"body"`
+}
+
+func TestValidate_Edges(t *testing.T) {
+	n := &validateNode{Named: validateNamed{Name: "a"}, Body: ion.JSON(`{"a":1}`)}
+	n.Next = n // cycle
+	if err := ion.Validate(n); err != nil {
+		t.Fatalf("expected nil embedded pointer and cycle to pass, got %v", err)
+	}
+	n.Named.Name = ""
+	if err := ion.Validate(n); err == nil || !strings.Contains(err.Error(), "named.name is required") {
+		t.Fatalf("expected nested rule of Stringer struct checked, got %v", err)
+	}
+}
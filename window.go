@@ -0,0 +1,201 @@
+package ion
+
+import (
+	"fmt"
+	"slices"
+	"time"
+)
+
+// Window represents a half-open time range [From, To). Windows created by
+// Day, Week and Month remember their calendar unit, so Next and Previous move
+// by calendar days/months instead of fixed durations (DST and month lengths
+// are respected).
+//
+// Example:
+//
+//	w := Day(time.Now(), warsaw).Previous() // yesterday in Warsaw
+//	Prompt("Summarize orders between {.from} and {.to}").Params(w.Meta())
+type Window struct {
+	From time.Time `json:"from"`
+	To   time.Time `json:"to"`
+	// months and days define calendar step used by Next and Previous
+	months, days int
+}
+
+// NewWindow creates a Window between from and to. Arguments are swapped when
+// to is before from.
+func NewWindow(from, to time.Time) Window {
+	if to.Before(from) {
+		from, to = to, from
+	}
+	return Window{From: from, To: to}
+}
+
+// Day returns the window of the calendar day containing t in the optional
+// location, by default location of t is used.
+func Day(t time.Time, loc ...*time.Location) Window {
+	t = inLocation(t, loc...)
+	f := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	return Window{From: f, To: f.AddDate(0, 0, 1), days: 1}
+}
+
+// Week returns the window of the ISO week (Monday to Sunday) containing t in
+// the optional location.
+func Week(t time.Time, loc ...*time.Location) Window {
+	d := Day(t, loc...)
+	n := (int(d.From.Weekday()) + 6) % 7 // days since Monday
+	f := d.From.AddDate(0, 0, -n)
+	return Window{From: f, To: f.AddDate(0, 0, 7), days: 7}
+}
+
+// Month returns the window of the calendar month containing t in the optional
+// location.
+func Month(t time.Time, loc ...*time.Location) Window {
+	t = inLocation(t, loc...)
+	f := time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location())
+	return Window{From: f, To: f.AddDate(0, 1, 0), months: 1}
+}
+
+// Last returns the window of duration d ending now.
+func Last(d time.Duration) Window {
+	n := time.Now()
+	return NewWindow(n.Add(-d), n)
+}
+
+// Contains reports whether t is within the window.
+func (w Window) Contains(t time.Time) bool {
+	return !t.Before(w.From) && t.Before(w.To)
+}
+
+// Duration returns length of the window.
+func (w Window) Duration() time.Duration {
+	return w.To.Sub(w.From)
+}
+
+// Next returns the following window of the same length or calendar unit.
+func (w Window) Next() Window {
+	return w.shift(1)
+}
+
+// Previous returns the preceding window of the same length or calendar unit.
+func (w Window) Previous() Window {
+	return w.shift(-1)
+}
+
+// In returns the window with both ends converted to the given location.
+func (w Window) In(loc *time.Location) Window {
+	w.From, w.To = w.From.In(loc), w.To.In(loc)
+	return w
+}
+
+// Each iterates over consecutive sub-windows of length step, the last one
+// is truncated to the window end. Iteration index is passed as second value.
+//
+// Usage:
+//
+//	for h, i := range Day(time.Now()).Each(time.Hour) {
+//		fmt.Println(i, h)
+//	}
+func (w Window) Each(step time.Duration) Iterator[Window, int] {
+	return func(yield func(Window, int) bool) {
+		if step <= 0 {
+			return
+		}
+		for i, f := 0, w.From; f.Before(w.To); i, f = i+1, f.Add(step) {
+			t := f.Add(step)
+			if t.After(w.To) {
+				t = w.To
+			}
+			if !yield(Window{From: f, To: t}, i) {
+				return
+			}
+		}
+	}
+}
+
+// Days iterates over calendar days of the window.
+func (w Window) Days() Iterator[Window, int] {
+	return func(yield func(Window, int) bool) {
+		for i, d := 0, Day(w.From); d.From.Before(w.To); i, d = i+1, d.Next() {
+			if !yield(d, i) {
+				return
+			}
+		}
+	}
+}
+
+// Meta returns window bounds formatted as RFC3339, handy as a Prompt parameter.
+func (w Window) Meta() Meta {
+	return Meta{"from": w.From.Format(time.RFC3339), "to": w.To.Format(time.RFC3339)}
+}
+
+// String returns the window as an ISO 8601 time interval.
+func (w Window) String() string {
+	return fmt.Sprintf("%s/%s", w.From.Format(time.RFC3339), w.To.Format(time.RFC3339))
+}
+
+func (w Window) shift(n int) Window {
+	if w.months == 0 && w.days == 0 {
+		d := time.Duration(n) * w.Duration()
+		w.From, w.To = w.From.Add(d), w.To.Add(d)
+		return w
+	}
+	w.From = w.From.AddDate(0, n*w.months, n*w.days)
+	w.To = w.From.AddDate(0, w.months, w.days)
+	return w
+}
+
+// Hours describes recurring opening hours, like business hours, in a location.
+type Hours struct {
+	// Open and Close are offsets from midnight.
+	Open, Close time.Duration
+	// Days when hours apply, every day when empty.
+	Days []time.Weekday
+	// Location of the hours, time.Local when nil.
+	Location *time.Location
+}
+
+// BusinessHours are 9:00-17:00, Monday to Friday in local time.
+var BusinessHours = Hours{
+	Open:  9 * time.Hour,
+	Close: 17 * time.Hour,
+	Days:  []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday},
+}
+
+// Contains reports whether t falls within the hours.
+func (h Hours) Contains(t time.Time) bool {
+	d := Day(t, h.location())
+	if len(h.Days) > 0 && !slices.Contains(h.Days, d.From.Weekday()) {
+		return false
+	}
+	return NewWindow(d.From.Add(h.Open), d.From.Add(h.Close)).Contains(t)
+}
+
+// Next returns the window of the nearest opening hours containing or
+// following t.
+func (h Hours) Next(t time.Time) Window {
+	d := Day(t, h.location())
+	for i := 0; i < 8; i, d = i+1, d.Next() {
+		if len(h.Days) > 0 && !slices.Contains(h.Days, d.From.Weekday()) {
+			continue
+		}
+		if w := NewWindow(d.From.Add(h.Open), d.From.Add(h.Close)); t.Before(w.To) {
+			return w
+		}
+	}
+	return Window{}
+}
+
+func (h Hours) location() *time.Location {
+	if h.Location == nil {
+		return time.Local
+	}
+	return h.Location
+}
+
+func inLocation(t time.Time, loc ...*time.Location) time.Time {
+	if len(loc) > 0 && loc[0] != nil {
+		return t.In(loc[0])
+	}
+	return t
+}
@@ -0,0 +1,49 @@
+package ion_test
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/sokool/ion"
+)
+
+func TestWindow(t *testing.T) {
+	waw, err := time.LoadLocation("Europe/Warsaw")
+	if err != nil {
+		t.Skip(err)
+	}
+	n := time.Date(2025, 3, 30, 12, 0, 0, 0, time.UTC) // DST switch in Warsaw
+	if d := Day(n, waw); d.Duration() != 23*time.Hour || d.From.Hour() != 0 {
+		t.Fatalf("expected 23h day starting at midnight, got %s", d)
+	}
+	if w := Week(n, waw); w.From.Weekday() != time.Monday || w.From.Day() != 24 {
+		t.Fatalf("expected week starting on Monday 24th, got %s", w)
+	}
+	if m := Month(n, waw).Previous(); m.From.Month() != time.February || m.To.Month() != time.March {
+		t.Fatalf("expected February, got %s", m)
+	}
+	if y := Day(n, waw).Previous(); y.From.Day() != 29 || y.Duration() != 24*time.Hour {
+		t.Fatalf("expected 29th, got %s", y)
+	}
+	var c int
+	for range Day(n, waw).Each(time.Hour) {
+		c++
+	}
+	if c != 23 {
+		t.Fatalf("expected 23 hours, got %d", c)
+	}
+	c = 0
+	for range Month(n).Days() {
+		c++
+	}
+	if c != 31 {
+		t.Fatalf("expected 31 days, got %d", c)
+	}
+	h := Hours{Open: 9 * time.Hour, Close: 17 * time.Hour, Days: BusinessHours.Days, Location: waw}
+	if h.Contains(n) {
+		t.Fatal("expected closed on Sunday")
+	}
+	if w := h.Next(n); w.From.Weekday() != time.Monday || w.From.In(waw).Hour() != 9 {
+		t.Fatalf("expected Monday 9:00, got %s", w)
+	}
+}